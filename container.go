@@ -30,7 +30,7 @@ type DeprecatedContainer interface {
 	GetHostEndpoint(ctx context.Context, port string) (string, string, error)
 	GetIPAddress(ctx context.Context) (string, error)
 	LivenessCheckPorts(ctx context.Context) (nat.PortSet, error)
-	Terminate(ctx context.Context) error
+	Terminate(ctx context.Context, opts ...TerminateOption) error
 }
 
 // Container allows getting info about and controlling a single container instance
@@ -46,7 +46,7 @@ type Container interface {
 	IsRunning() bool
 	Start(context.Context) error                                    // start the container
 	Stop(context.Context, *time.Duration) error                     // stop the container
-	Terminate(context.Context) error                                // terminate the container
+	Terminate(context.Context, ...TerminateOption) error            // terminate the container
 	Logs(context.Context) (io.ReadCloser, error)                    // Get logs of the container
 	FollowOutput(LogConsumer)                                       // Deprecated: it will be removed in the next major release
 	StartLogProducer(context.Context, ...LogProductionOption) error // Deprecated: Use the ContainerRequest instead
@@ -87,6 +87,7 @@ type FromDockerfile struct {
 	Repo           string                         // the repo label for image, defaults to UUID
 	Tag            string                         // the tag label for image, defaults to UUID
 	BuildArgs      map[string]*string             // enable user to pass build args to docker daemon
+	Target         string                         // the stage of a multi-stage Dockerfile to build, defaults to the last one
 	PrintBuildLog  bool                           // enable user to print build log
 	AuthConfigs    map[string]registry.AuthConfig // Deprecated. Testcontainers will detect registry credentials automatically. Enable auth configs to be able to pull from an authenticated docker registry
 	// KeepImage describes whether DockerContainer.Terminate should not delete the
@@ -145,12 +146,14 @@ type ContainerRequest struct {
 	Resources               container.Resources                        // Deprecated: Use HostConfigModifier instead
 	Files                   []ContainerFile                            // files which will be copied when container starts
 	User                    string                                     // for specifying uid:gid
-	SkipReaper              bool                                       // Deprecated: The reaper is globally controlled by the .testcontainers.properties file or the TESTCONTAINERS_RYUK_DISABLED environment variable
+	SkipReaper              bool                                       // Deprecated: The reaper is globally controlled by the .testcontainers.properties file or the TESTCONTAINERS_RYUK_DISABLED environment variable. Use ReaperDisabled for a single container
+	ReaperDisabled          bool                                       // The reaper does not terminate this container, which must be terminated by the user
 	ReaperImage             string                                     // Deprecated: use WithImageName ContainerOption instead. Alternative reaper image
 	ReaperOptions           []ContainerOption                          // Deprecated: the reaper is configured at the properties level, for an entire test session
 	AutoRemove              bool                                       // Deprecated: Use HostConfigModifier instead. If set to true, the container will be removed from the host when stopped
 	AlwaysPullImage         bool                                       // Always pull image
 	ImagePlatform           string                                     // ImagePlatform describes the platform which the image runs on.
+	RegistryAuthConfigs     map[string]registry.AuthConfig             // Auth configs by registry to pull the image, taking precedence over the docker config
 	Binds                   []string                                   // Deprecated: Use HostConfigModifier instead
 	ShmSize                 int64                                      // Amount of memory shared with the host (in bytes)
 	CapAdd                  []string                                   // Deprecated: Use HostConfigModifier instead. Add Linux capabilities
@@ -160,6 +163,7 @@ type ContainerRequest struct {
 	EnpointSettingsModifier func(map[string]*network.EndpointSettings) // Modifier for the network settings before container creation
 	LifecycleHooks          []ContainerLifecycleHooks                  // define hooks to be executed during container lifecycle
 	LogConsumerCfg          *LogConsumerConfig                         // define the configuration for the log producer and its log consumers to follow the logs
+	TerminateTimeout        time.Duration                              // time given to the container to stop when terminated, before it's killed. It's killed right away if zero
 }
 
 // containerOptions functional options for a container
@@ -346,6 +350,7 @@ func (c *ContainerRequest) BuildOptions() (types.ImageBuildOptions, error) {
 	buildOptions := types.ImageBuildOptions{
 		Remove:      true,
 		ForceRemove: true,
+		Target:      c.FromDockerfile.Target,
 	}
 
 	if c.FromDockerfile.BuildOptionsModifier != nil {
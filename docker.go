@@ -5,17 +5,17 @@ import (
 	"bufio"
 	"bytes"
 	"context"
-	"encoding/base64"
 	"encoding/binary"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"io/fs"
+	"math"
 	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -42,6 +42,17 @@ import (
 // Implement interfaces
 var _ Container = (*DockerContainer)(nil)
 
+// DockerContainer does not implement the accessors of DeprecatedContainer,
+// so only their shared Terminate method is kept in sync
+type terminator interface {
+	Terminate(ctx context.Context, opts ...TerminateOption) error
+}
+
+var (
+	_ terminator = (*DockerContainer)(nil)
+	_ terminator = DeprecatedContainer(nil)
+)
+
 const (
 	Bridge        = "bridge" // Bridge network name (as well as driver)
 	Podman        = "podman"
@@ -62,6 +73,8 @@ type DockerContainer struct {
 
 	isRunning     bool
 	imageWasBuilt bool
+	// terminateTimeout is the time given to the container to stop when terminated, before it's killed.
+	terminateTimeout time.Duration
 	// keepBuiltImage makes Terminate not remove the image if imageWasBuilt.
 	keepBuiltImage     bool
 	provider           *DockerProvider
@@ -205,6 +218,22 @@ func (c *DockerContainer) MappedPort(ctx context.Context, port nat.Port) (nat.Po
 	return "", errors.New("port not found")
 }
 
+// MappedPortInt gets externally mapped port for a container port, as an integer,
+// e.g. to build the address of the container with fmt.Sprintf("%s:%d", host, port).
+func (c *DockerContainer) MappedPortInt(ctx context.Context, port nat.Port) (int, error) {
+	mapped, err := c.MappedPort(ctx, port)
+	if err != nil {
+		return 0, err
+	}
+
+	p, err := strconv.Atoi(mapped.Port())
+	if err != nil {
+		return 0, fmt.Errorf("parse mapped port %s of %s: %w", mapped, port, err)
+	}
+
+	return p, nil
+}
+
 // Deprecated: use c.Inspect(ctx).NetworkSettings.Ports instead.
 // Ports gets the exposed ports for the container.
 func (c *DockerContainer) Ports(ctx context.Context) (nat.PortMap, error) {
@@ -220,7 +249,9 @@ func (c *DockerContainer) SessionID() string {
 	return c.sessionID
 }
 
-// Start will start an already created container
+// Start will start an already created container, or restart a stopped one, keeping its filesystem
+// and network attachments. The lifecycle hooks run again, including the wait strategies of the
+// container, so the container is ready when it returns.
 func (c *DockerContainer) Start(ctx context.Context) error {
 	err := c.startingHook(ctx)
 	if err != nil {
@@ -285,8 +316,33 @@ func (c *DockerContainer) Stop(ctx context.Context, timeout *time.Duration) erro
 	return nil
 }
 
+// TerminateOption is an option of the Terminate method of the containers.
+type TerminateOption func(*terminateOptions)
+
+type terminateOptions struct {
+	stopTimeout *time.Duration
+}
+
+// StopTimeout gives the container the timeout to stop when terminated, overriding the one set with
+// WithTerminateTimeout: the container is sent SIGTERM, and it's killed if it's still running once the
+// timeout is over. A zero timeout kills the container right away.
+func StopTimeout(timeout time.Duration) TerminateOption {
+	return func(o *terminateOptions) {
+		o.stopTimeout = &timeout
+	}
+}
+
 // Terminate is used to kill the container. It is usually triggered by as defer function.
-func (c *DockerContainer) Terminate(ctx context.Context) error {
+// The anonymous volumes of the container, like those of the VOLUME instructions of the image, are removed
+// with it, while the named volumes are kept, so they can be mounted by another container.
+// If a terminate timeout was set with WithTerminateTimeout or the StopTimeout option, the container is
+// stopped within the timeout before it's removed, so it can shut down cleanly. Otherwise, it's killed right away.
+func (c *DockerContainer) Terminate(ctx context.Context, opts ...TerminateOption) error {
+	options := terminateOptions{stopTimeout: &c.terminateTimeout}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	select {
 	// close reaper if it was created
 	case c.terminationSignal <- true:
@@ -295,14 +351,24 @@ func (c *DockerContainer) Terminate(ctx context.Context) error {
 
 	defer c.provider.client.Close()
 
-	errs := []error{
-		c.terminatingHook(ctx),
+	errs := []error{c.terminatingHook(ctx)}
+
+	if timeout := *options.stopTimeout; timeout > 0 {
+		// the timeout of the stop is in seconds, rounded up so the container gets at least the timeout
+		timeoutSeconds := int(math.Ceil(timeout.Seconds()))
+		err := c.provider.client.ContainerStop(ctx, c.GetContainerID(), container.StopOptions{Timeout: &timeoutSeconds})
+		if err != nil && !errdefs.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("stop container: %w", err))
+		}
+	}
+
+	errs = append(errs,
 		c.provider.client.ContainerRemove(ctx, c.GetContainerID(), container.RemoveOptions{
 			RemoveVolumes: true,
 			Force:         true,
 		}),
 		c.terminatedHook(ctx),
-	}
+	)
 
 	if c.imageWasBuilt && !c.keepBuiltImage {
 		_, err := c.provider.client.ImageRemove(ctx, c.Image, types.ImageRemoveOptions{
@@ -318,6 +384,22 @@ func (c *DockerContainer) Terminate(ctx context.Context) error {
 	return errors.Join(errs...)
 }
 
+// Commit creates a new image from the current filesystem of the container, tagged with the reference if not
+// empty, and returns its id. The container is paused while it's committed. The image keeps the labels of the
+// container, so it's removed by the reaper at the end of the session, unless the reaper is disabled.
+func (c *DockerContainer) Commit(ctx context.Context, reference string) (string, error) {
+	resp, err := c.provider.client.ContainerCommit(ctx, c.ID, container.CommitOptions{
+		Reference: reference,
+		Pause:     true,
+	})
+	if err != nil {
+		return "", fmt.Errorf("commit container %s: %w", c.ID, err)
+	}
+	defer c.provider.Close()
+
+	return resp.ID, nil
+}
+
 // update container raw info
 func (c *DockerContainer) inspectRawContainer(ctx context.Context) (*types.ContainerJSON, error) {
 	defer c.provider.Close()
@@ -333,13 +415,46 @@ func (c *DockerContainer) inspectRawContainer(ctx context.Context) (*types.Conta
 // Logs will fetch both STDOUT and STDERR from the current container. Returns a
 // ReadCloser and leaves it up to the caller to extract what it wants.
 func (c *DockerContainer) Logs(ctx context.Context) (io.ReadCloser, error) {
-	const streamHeaderSize = 8
+	return c.LogsWithOptions(ctx, LogOptions{})
+}
+
+// LogOptions are the options to fetch the logs of a container with LogsWithOptions.
+// The zero value fetches all the logs, without following them.
+type LogOptions struct {
+	// Since only fetches the logs written after it, if set.
+	Since time.Time
+	// Tail only fetches the given number of lines from the end of the logs, if positive.
+	Tail int
+	// Follow keeps streaming the new logs of the container until the context is done.
+	Follow bool
+}
 
+// dockerOptions returns the Docker log options of both STDOUT and STDERR for the options.
+func (o LogOptions) dockerOptions() container.LogsOptions {
 	options := container.LogsOptions{
 		ShowStdout: true,
 		ShowStderr: true,
+		Follow:     o.Follow,
+	}
+
+	if !o.Since.IsZero() {
+		options.Since = o.Since.Format(time.RFC3339Nano)
 	}
 
+	if o.Tail > 0 {
+		options.Tail = strconv.Itoa(o.Tail)
+	}
+
+	return options
+}
+
+// LogsWithOptions works as Logs, fetching the logs of the current container with the given options,
+// e.g. LogOptions{Tail: 100} to only fetch the last 100 lines after a failure.
+func (c *DockerContainer) LogsWithOptions(ctx context.Context, logOptions LogOptions) (io.ReadCloser, error) {
+	const streamHeaderSize = 8
+
+	options := logOptions.dockerOptions()
+
 	rc, err := c.provider.client.ContainerLogs(ctx, c.ID, options)
 	if err != nil {
 		return nil, err
@@ -559,6 +674,8 @@ func (fc *FileFromContainer) Close() error {
 	return (*fc.underlying).Close()
 }
 
+// CopyFileFromContainer returns a reader of the content of a file in the container, which must be closed.
+// Use CopyFromContainer to copy a directory.
 func (c *DockerContainer) CopyFileFromContainer(ctx context.Context, filePath string) (io.ReadCloser, error) {
 	r, _, err := c.provider.client.CopyFromContainer(ctx, c.ID, filePath)
 	if err != nil {
@@ -570,11 +687,17 @@ func (c *DockerContainer) CopyFileFromContainer(ctx context.Context, filePath st
 
 	// if we got here we have exactly one file in the TAR-stream
 	// so we advance the index by one so the next call to Read will start reading it
-	_, err = tarReader.Next()
+	header, err := tarReader.Next()
 	if err != nil {
+		r.Close()
 		return nil, err
 	}
 
+	if header.Typeflag == tar.TypeDir {
+		r.Close()
+		return nil, fmt.Errorf("%s is a directory, use CopyFromContainer", filePath)
+	}
+
 	ret := &FileFromContainer{
 		underlying: &r,
 		tarreader:  tarReader,
@@ -583,8 +706,22 @@ func (c *DockerContainer) CopyFileFromContainer(ctx context.Context, filePath st
 	return ret, nil
 }
 
+// CopyFromContainer returns a tar stream of a file or a directory in the container, which must be closed.
+// The paths in the stream are relative to the parent of the path, e.g. "data/meta.properties" for the
+// "/var/lib/kafka/data" path, as with docker cp.
+func (c *DockerContainer) CopyFromContainer(ctx context.Context, containerPath string) (io.ReadCloser, error) {
+	r, _, err := c.provider.client.CopyFromContainer(ctx, c.ID, containerPath)
+	if err != nil {
+		return nil, fmt.Errorf("copy %s from container: %w", containerPath, err)
+	}
+	defer c.provider.Close()
+
+	return r, nil
+}
+
 // CopyDirToContainer copies the contents of a directory to a parent path in the container. This parent path must exist in the container first
-// as we cannot create it
+// as we cannot create it. The directory tree is copied recursively, including the empty directories, and the symlinks are copied as symlinks.
+// The files and directories get the given mode, or keep their permissions on the host if the mode is zero.
 func (c *DockerContainer) CopyDirToContainer(ctx context.Context, hostDirPath string, containerParentPath string, fileMode int64) error {
 	dir, err := isDir(hostDirPath)
 	if err != nil {
@@ -867,6 +1004,25 @@ func (n *DockerNetwork) Remove(ctx context.Context) error {
 	return n.provider.client.NetworkRemove(ctx, n.ID)
 }
 
+// Aliases returns the aliases of the container with the given ID in the network, e.g. the ones set by
+// the network.WithNetwork option. Depending on the version of the Docker daemon, they could include
+// the short ID of the container. It returns an error if the container is not attached to the network.
+func (n *DockerNetwork) Aliases(ctx context.Context, containerID string) ([]string, error) {
+	defer n.provider.Close()
+
+	inspect, err := n.provider.client.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, ok := inspect.NetworkSettings.Networks[n.Name]
+	if !ok {
+		return nil, fmt.Errorf("container %s is not attached to network %s", containerID, n.Name)
+	}
+
+	return settings.Aliases, nil
+}
+
 func (n *DockerNetwork) SetTerminationSignal(signal chan bool) {
 	n.terminationSignal = signal
 }
@@ -996,7 +1152,7 @@ func (p *DockerProvider) CreateContainer(ctx context.Context, req ContainerReque
 	var termSignal chan bool
 	// the reaper does not need to start a reaper for itself
 	isReaperContainer := strings.HasSuffix(imageName, config.ReaperDefaultImage)
-	if !tcConfig.RyukDisabled && !isReaperContainer {
+	if !tcConfig.RyukDisabled && !isReaperContainer && !req.ReaperDisabled {
 		r, err := reuseOrCreateReaper(context.WithValue(ctx, core.DockerHostContextKey, p.host), core.SessionID(), p)
 		if err != nil {
 			return nil, fmt.Errorf("%w: creating reaper failed", err)
@@ -1071,6 +1227,12 @@ func (p *DockerProvider) CreateContainer(ctx context.Context, req ContainerReque
 			pullOpt := types.ImagePullOptions{
 				Platform: req.ImagePlatform, // may be empty
 			}
+			if authConfig, ok := requestImageAuth(ctx, imageName, req.RegistryAuthConfigs); ok {
+				pullOpt.RegistryAuth, err = encodeImageAuth(authConfig)
+				if err != nil {
+					return nil, fmt.Errorf("encode registry auth for %s: %w", imageName, err)
+				}
+			}
 			if err := p.attemptToPullImage(ctx, imageName, pullOpt); err != nil {
 				return nil, err
 			}
@@ -1080,6 +1242,10 @@ func (p *DockerProvider) CreateContainer(ctx context.Context, req ContainerReque
 	if !isReaperContainer {
 		// add the labels that the reaper will use to terminate the container to the request
 		for k, v := range core.DefaultLabels(core.SessionID()) {
+			// the reaper filters the containers by session, so they are not reaped without that label
+			if req.ReaperDisabled && k == core.LabelSessionID {
+				continue
+			}
 			req.Labels[k] = v
 		}
 	}
@@ -1162,6 +1328,7 @@ func (p *DockerProvider) CreateContainer(ctx context.Context, req ContainerReque
 		Image:             imageName,
 		imageWasBuilt:     req.ShouldBuildImage(),
 		keepBuiltImage:    req.ShouldKeepBuiltImage(),
+		terminateTimeout:  req.TerminateTimeout,
 		sessionID:         core.SessionID(),
 		provider:          p,
 		terminationSignal: termSignal,
@@ -1199,6 +1366,29 @@ func (p *DockerProvider) findContainerByName(ctx context.Context, name string) (
 	return nil, nil
 }
 
+// FindContainersByLabel returns the containers, running or not, labeled with the key and the value,
+// e.g. to locate the containers started by a previous test with WithLabels.
+func (p *DockerProvider) FindContainersByLabel(ctx context.Context, key string, value string) ([]Container, error) {
+	filter := filters.NewArgs(filters.Arg("label", key+"="+value))
+	list, err := p.client.ContainerList(ctx, container.ListOptions{All: true, Filters: filter})
+	if err != nil {
+		return nil, fmt.Errorf("list containers labeled %s=%s: %w", key, value, err)
+	}
+	defer p.Close()
+
+	containers := make([]Container, 0, len(list))
+	for _, c := range list {
+		dc, err := p.containerFromDockerResponse(ctx, c)
+		if err != nil {
+			return nil, err
+		}
+
+		containers = append(containers, dc)
+	}
+
+	return containers, nil
+}
+
 func (p *DockerProvider) waitContainerCreation(ctx context.Context, name string) (*types.Container, error) {
 	var container *types.Container
 	return container, backoff.Retry(func() error {
@@ -1265,6 +1455,7 @@ func (p *DockerProvider) ReuseOrCreateContainer(ctx context.Context, req Contain
 		ID:                c.ID,
 		WaitingFor:        req.WaitingFor,
 		Image:             c.Image,
+		terminateTimeout:  req.TerminateTimeout,
 		sessionID:         sessionID,
 		provider:          p,
 		terminationSignal: termSignal,
@@ -1289,21 +1480,22 @@ func (p *DockerProvider) ReuseOrCreateContainer(ctx context.Context, req Contain
 
 // attemptToPullImage tries to pull the image while respecting the ctx cancellations.
 // Besides, if the image cannot be pulled due to ErrorNotFound then no need to retry but terminate immediately.
+// If the pull options have no registry auth, the credentials are read from the docker config.
 func (p *DockerProvider) attemptToPullImage(ctx context.Context, tag string, pullOpt types.ImagePullOptions) error {
-	registry, imageAuth, err := DockerImageAuth(ctx, tag)
-	if err != nil {
-		p.Logger.Printf("Failed to get image auth for %s. Setting empty credentials for the image: %s. Error is:%s", registry, tag, err)
-	} else {
-		// see https://github.com/docker/docs/blob/e8e1204f914767128814dca0ea008644709c117f/engine/api/sdk/examples.md?plain=1#L649-L657
-		encodedJSON, err := json.Marshal(imageAuth)
+	if pullOpt.RegistryAuth == "" {
+		registry, imageAuth, err := DockerImageAuth(ctx, tag)
 		if err != nil {
-			p.Logger.Printf("Failed to marshal image auth. Setting empty credentials for the image: %s. Error is:%s", tag, err)
+			p.Logger.Printf("Failed to get image auth for %s. Setting empty credentials for the image: %s. Error is:%s", registry, tag, err)
 		} else {
-			pullOpt.RegistryAuth = base64.URLEncoding.EncodeToString(encodedJSON)
+			pullOpt.RegistryAuth, err = encodeImageAuth(imageAuth)
+			if err != nil {
+				p.Logger.Printf("Failed to marshal image auth. Setting empty credentials for the image: %s. Error is:%s", tag, err)
+			}
 		}
 	}
 
 	var pull io.ReadCloser
+	var err error
 	err = backoff.Retry(func() error {
 		pull, err = p.client.ImagePull(ctx, tag, pullOpt)
 		if err != nil {
@@ -1317,6 +1509,9 @@ func (p *DockerProvider) attemptToPullImage(ctx context.Context, tag string, pul
 
 		return nil
 	}, backoff.WithContext(backoff.NewExponentialBackOff(), ctx))
+	if errdefs.IsUnauthorized(err) || errdefs.IsForbidden(err) {
+		return fmt.Errorf("pull image %s: not authorized by its registry, check the registry credentials: %w", tag, err)
+	}
 	if err != nil {
 		return err
 	}
@@ -1564,6 +1759,11 @@ func containerFromDockerResponse(ctx context.Context, response types.Container)
 		return nil, err
 	}
 
+	return provider.containerFromDockerResponse(ctx, response)
+}
+
+// containerFromDockerResponse builds a Docker container struct managed by the provider from the response of the Docker API
+func (p *DockerProvider) containerFromDockerResponse(ctx context.Context, response types.Container) (*DockerContainer, error) {
 	container := DockerContainer{}
 
 	container.ID = response.ID
@@ -1571,11 +1771,11 @@ func containerFromDockerResponse(ctx context.Context, response types.Container)
 	container.Image = response.Image
 	container.imageWasBuilt = false
 
-	container.logger = provider.Logger
+	container.logger = p.Logger
 	container.lifecycleHooks = []ContainerLifecycleHooks{
 		DefaultLoggingHook(container.logger),
 	}
-	container.provider = provider
+	container.provider = p
 
 	container.sessionID = core.SessionID()
 	container.consumers = []LogConsumer{}
@@ -1585,7 +1785,7 @@ func containerFromDockerResponse(ctx context.Context, response types.Container)
 	container.terminationSignal = nil
 
 	// populate the raw representation of the container
-	_, err = container.inspectRawContainer(ctx)
+	_, err := container.inspectRawContainer(ctx)
 	if err != nil {
 		return nil, err
 	}
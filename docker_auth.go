@@ -35,6 +35,29 @@ func DockerImageAuth(ctx context.Context, image string) (string, registry.AuthCo
 	return reg, registry.AuthConfig{}, dockercfg.ErrCredentialsNotFound
 }
 
+// requestImageAuth returns the auth config for the given Docker image from the auth configs of the
+// request, which are keyed by registry. It returns false if there is no auth config for its registry.
+func requestImageAuth(ctx context.Context, image string, cfgs map[string]registry.AuthConfig) (registry.AuthConfig, bool) {
+	if len(cfgs) == 0 {
+		return registry.AuthConfig{}, false
+	}
+
+	reg := core.ExtractRegistry(image, defaultRegistryFn(ctx))
+
+	return getRegistryAuth(reg, cfgs)
+}
+
+// encodeImageAuth encodes the auth config as expected by the RegistryAuth of the image pull options.
+// see https://github.com/docker/docs/blob/e8e1204f914767128814dca0ea008644709c117f/engine/api/sdk/examples.md?plain=1#L649-L657
+func encodeImageAuth(cfg registry.AuthConfig) (string, error) {
+	encodedJSON, err := json.Marshal(cfg)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.URLEncoding.EncodeToString(encodedJSON), nil
+}
+
 func getRegistryAuth(reg string, cfgs map[string]registry.AuthConfig) (registry.AuthConfig, bool) {
 	if cfg, ok := cfgs[reg]; ok {
 		return cfg, true
@@ -9,6 +9,7 @@ import (
 
 	"github.com/cpuguy83/dockercfg"
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/registry"
 	"github.com/docker/docker/client"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -187,6 +188,51 @@ func TestGetDockerConfig(t *testing.T) {
 	})
 }
 
+func TestRequestImageAuth(t *testing.T) {
+	origDefaultRegistryFn := defaultRegistryFn
+	t.Cleanup(func() {
+		defaultRegistryFn = origDefaultRegistryFn
+	})
+	defaultRegistryFn = func(ctx context.Context) string {
+		return core.IndexDockerIO
+	}
+
+	cfgs := map[string]registry.AuthConfig{
+		"myregistry.local": {Username: "gopher", Password: "secret", ServerAddress: "myregistry.local"},
+	}
+
+	t.Run("match the registry of the image", func(t *testing.T) {
+		cfg, ok := requestImageAuth(context.Background(), "myregistry.local/confluentinc/confluent-local:7.6.1", cfgs)
+		require.True(t, ok)
+
+		assert.Equal(t, "gopher", cfg.Username)
+		assert.Equal(t, "secret", cfg.Password)
+	})
+
+	t.Run("match registry authentication by host", func(t *testing.T) {
+		cfgs := map[string]registry.AuthConfig{
+			"https://myregistry.local": {Username: "gopher", Password: "secret"},
+		}
+
+		cfg, ok := requestImageAuth(context.Background(), "myregistry.local/confluentinc/confluent-local:7.6.1", cfgs)
+		require.True(t, ok)
+
+		assert.Equal(t, "gopher", cfg.Username)
+	})
+
+	t.Run("no auth config for the registry of the image", func(t *testing.T) {
+		cfg, ok := requestImageAuth(context.Background(), "confluentinc/confluent-local:7.6.1", cfgs)
+		require.False(t, ok)
+		require.Empty(t, cfg)
+	})
+
+	t.Run("no auth configs", func(t *testing.T) {
+		cfg, ok := requestImageAuth(context.Background(), "myregistry.local/confluentinc/confluent-local:7.6.1", nil)
+		require.False(t, ok)
+		require.Empty(t, cfg)
+	})
+}
+
 func TestBuildContainerFromDockerfile(t *testing.T) {
 	ctx := context.Background()
 	req := ContainerRequest{
@@ -308,6 +354,44 @@ func TestCreateContainerFromPrivateRegistry(t *testing.T) {
 	terminateContainerOnEnd(t, ctx, redisContainer)
 }
 
+func TestCreateContainerFromPrivateRegistryWithRegistryAuth(t *testing.T) {
+	// no credentials in the docker config for the registry
+	t.Setenv("DOCKER_AUTH_CONFIG", `{}`)
+
+	prepareLocalRegistryWithAuth(t)
+
+	ctx := context.Background()
+	req := GenericContainerRequest{
+		ContainerRequest: ContainerRequest{
+			Image:           "localhost:5001/redis:5.0-alpine",
+			AlwaysPullImage: true, // make sure the authentication takes place
+			ExposedPorts:    []string{"6379/tcp"},
+			WaitingFor:      wait.ForLog("Ready to accept connections"),
+		},
+		Started: true,
+	}
+
+	t.Run("valid credentials", func(t *testing.T) {
+		// withRegistryAuth {
+		err := WithRegistryAuth("localhost:5001", "testuser", "testpassword").Customize(&req)
+		// }
+		require.NoError(t, err)
+
+		redisContainer, err := GenericContainer(ctx, req)
+		require.NoError(t, err)
+		terminateContainerOnEnd(t, ctx, redisContainer)
+	})
+
+	t.Run("wrong credentials", func(t *testing.T) {
+		err := WithRegistryAuth("localhost:5001", "testuser", "wrongpassword").Customize(&req)
+		require.NoError(t, err)
+
+		_, err = GenericContainer(ctx, req)
+		require.Error(t, err)
+		require.NotContains(t, err.Error(), "wrongpassword")
+	})
+}
+
 func prepareLocalRegistryWithAuth(t *testing.T) {
 	ctx := context.Background()
 	wd, err := os.Getwd()
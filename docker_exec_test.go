@@ -43,6 +43,27 @@ func TestExecWithOptions(t *testing.T) {
 			},
 			want: "TEST_ENV=test\n",
 		},
+		{
+			name: "with env map",
+			cmds: []string{"env"},
+			opts: []tcexec.ProcessOption{
+				tcexec.WithEnv([]string{"TEST_ENV=test"}),
+				tcexec.WithEnvMap(map[string]string{"TEST_ENV_MAP": "test map"}),
+			},
+			want: "TEST_ENV=test\nTEST_ENV_MAP=test map\n",
+		},
+		{
+			name: "with user, working dir and env",
+			cmds: []string{"sh", "-c", "echo $(whoami) $(pwd) $TEST_ENV"},
+			// execWithOptions {
+			opts: []tcexec.ProcessOption{
+				tcexec.WithUser("nginx"),
+				tcexec.WithWorkingDir("/var/log/nginx"),
+				tcexec.WithEnvMap(map[string]string{"TEST_ENV": "test"}),
+			},
+			// }
+			want: "nginx /var/log/nginx test\n",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -2,6 +2,7 @@ package testcontainers_test
 
 import (
 	"context"
+	"io"
 	"os"
 	"path/filepath"
 	"testing"
@@ -10,6 +11,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/testcontainers/testcontainers-go"
+	tcexec "github.com/testcontainers/testcontainers-go/exec"
 	"github.com/testcontainers/testcontainers-go/wait"
 )
 
@@ -227,3 +229,48 @@ func TestCopyDirectoryToRunningContainerAsDir(t *testing.T) {
 	require.NoError(t, err)
 	require.NoError(t, container.Terminate(ctx))
 }
+
+func TestCopyDirectoryTreeToRunningContainer(t *testing.T) {
+	ctx, cnl := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cnl()
+
+	src := filepath.Join(t.TempDir(), "tree")
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "nested", "deeper"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "empty"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "readme.txt"), []byte("readme"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "nested", "deeper", "run.sh"), []byte("#!/bin/sh"), 0o755))
+	require.NoError(t, os.Symlink("readme.txt", filepath.Join(src, "link")))
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image: "docker.io/bash",
+			Cmd:   []string{"sleep", "30"},
+		},
+		Started: true,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, container.Terminate(context.Background()))
+	})
+
+	// a zero mode keeps the permissions of the host
+	err = container.CopyDirToContainer(ctx, src, "/tmp/tree", 0)
+	require.NoError(t, err)
+
+	_, reader, err := container.Exec(ctx, []string{
+		"stat", "-c", "%n %a %F",
+		"/tmp/tree/empty",
+		"/tmp/tree/nested/deeper/run.sh",
+		"/tmp/tree/readme.txt",
+		"/tmp/tree/link",
+	}, tcexec.Multiplexed())
+	require.NoError(t, err)
+
+	output, err := io.ReadAll(reader)
+	require.NoError(t, err)
+
+	require.Contains(t, string(output), "/tmp/tree/empty 755 directory")
+	require.Contains(t, string(output), "/tmp/tree/nested/deeper/run.sh 755 regular file")
+	require.Contains(t, string(output), "/tmp/tree/readme.txt 644 regular file")
+	require.Contains(t, string(output), "/tmp/tree/link 777 symbolic link")
+}
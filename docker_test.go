@@ -1,6 +1,7 @@
 package testcontainers
 
 import (
+	"archive/tar"
 	"bytes"
 	"context"
 	"errors"
@@ -18,13 +19,18 @@ import (
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/registry"
 	"github.com/docker/docker/api/types/strslice"
 	"github.com/docker/docker/client"
 	"github.com/docker/docker/errdefs"
+	"github.com/docker/go-connections/nat"
 	"github.com/docker/go-units"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/testcontainers/testcontainers-go/internal/core"
 	"github.com/testcontainers/testcontainers-go/wait"
 )
 
@@ -331,6 +337,295 @@ func TestContainerStateAfterTermination(t *testing.T) {
 	})
 }
 
+func TestContainerStateAfterStopAndStart(t *testing.T) {
+	ctx := context.Background()
+
+	readyChecks := 0
+
+	nginx, err := GenericContainer(ctx, GenericContainerRequest{
+		ProviderType: providerType,
+		ContainerRequest: ContainerRequest{
+			Image: nginxAlpineImage,
+			ExposedPorts: []string{
+				nginxDefaultPort,
+			},
+			WaitingFor: wait.ForAll(
+				wait.ForListeningPort(nginxDefaultPort),
+				wait.ForNop(func(ctx context.Context, target wait.StrategyTarget) error {
+					readyChecks++
+					return nil
+				}),
+			),
+		},
+		Started: true,
+	})
+	require.NoError(t, err)
+	terminateContainerOnEnd(t, ctx, nginx)
+
+	_, _, err = nginx.Exec(ctx, []string{"touch", "/tmp/.testcontainers"})
+	require.NoError(t, err)
+
+	networks, err := nginx.Networks(ctx)
+	require.NoError(t, err)
+
+	// stopStartContainer {
+	timeout := 10 * time.Second
+	err = nginx.Stop(ctx, &timeout)
+	require.NoError(t, err)
+
+	state, err := nginx.State(ctx)
+	require.NoError(t, err)
+	assert.False(t, state.Running)
+
+	// the wait strategies run again, so the container is ready when Start returns
+	err = nginx.Start(ctx)
+	require.NoError(t, err)
+	// }
+
+	assert.Equal(t, 2, readyChecks)
+
+	state, err = nginx.State(ctx)
+	require.NoError(t, err)
+	assert.True(t, state.Running)
+
+	// the filesystem and the network attachments are kept
+	code, _, err := nginx.Exec(ctx, []string{"ls", "/tmp/.testcontainers"})
+	require.NoError(t, err)
+	assert.Zero(t, code)
+
+	restartedNetworks, err := nginx.Networks(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, networks, restartedNetworks)
+
+	// the cache of the container representation is invalidated, so the new mapped port is returned
+	endpoint, err := nginx.PortEndpoint(ctx, nginxDefaultPort, "http")
+	require.NoError(t, err)
+
+	resp, err := http.Get(endpoint)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestDockerContainer_Commit(t *testing.T) {
+	ctx := context.Background()
+
+	nginx, err := GenericContainer(ctx, GenericContainerRequest{
+		ProviderType: providerType,
+		ContainerRequest: ContainerRequest{
+			Image: nginxAlpineImage,
+		},
+		Started: true,
+	})
+	require.NoError(t, err)
+	terminateContainerOnEnd(t, ctx, nginx)
+
+	_, _, err = nginx.Exec(ctx, []string{"touch", "/tmp/.testcontainers"})
+	require.NoError(t, err)
+
+	// commitContainer {
+	reference := "testcontainers/commit:" + nginx.GetContainerID()[:12]
+
+	imageID, err := nginx.(*DockerContainer).Commit(ctx, reference)
+	require.NoError(t, err)
+	// }
+
+	cli, err := NewDockerClientWithOpts(ctx)
+	require.NoError(t, err)
+	defer cli.Close()
+
+	t.Cleanup(func() {
+		_, err := cli.ImageRemove(ctx, imageID, types.ImageRemoveOptions{Force: true})
+		require.NoError(t, err)
+	})
+
+	img, _, err := cli.ImageInspectWithRaw(ctx, imageID)
+	require.NoError(t, err)
+
+	assert.Equal(t, imageID, img.ID)
+	assert.Equal(t, []string{reference}, img.RepoTags)
+	assert.Equal(t, nginx.GetContainerID(), img.Container)
+	assert.Equal(t, nginx.SessionID(), img.Config.Labels[core.LabelSessionID])
+}
+
+func TestDockerContainer_MappedPortInt(t *testing.T) {
+	containerWithPorts := func(networkMode container.NetworkMode, ports nat.PortMap) *DockerContainer {
+		return &DockerContainer{
+			raw: &types.ContainerJSON{
+				ContainerJSONBase: &types.ContainerJSONBase{
+					HostConfig: &container.HostConfig{NetworkMode: networkMode},
+				},
+				NetworkSettings: &types.NetworkSettings{
+					NetworkSettingsBase: types.NetworkSettingsBase{Ports: ports},
+				},
+			},
+		}
+	}
+
+	ctx := context.Background()
+
+	t.Run("mapped port", func(t *testing.T) {
+		c := containerWithPorts("bridge", nat.PortMap{
+			"80/tcp": []nat.PortBinding{{HostIP: "0.0.0.0", HostPort: "32768"}},
+		})
+
+		port, err := c.MappedPortInt(ctx, "80/tcp")
+		require.NoError(t, err)
+		assert.Equal(t, 32768, port)
+	})
+
+	t.Run("host network", func(t *testing.T) {
+		c := containerWithPorts("host", nil)
+
+		port, err := c.MappedPortInt(ctx, "80/tcp")
+		require.NoError(t, err)
+		assert.Equal(t, 80, port)
+	})
+
+	t.Run("port not found", func(t *testing.T) {
+		c := containerWithPorts("bridge", nat.PortMap{})
+
+		_, err := c.MappedPortInt(ctx, "80/tcp")
+		require.EqualError(t, err, "port not found")
+	})
+
+	t.Run("port range", func(t *testing.T) {
+		c := containerWithPorts("host", nil)
+
+		_, err := c.MappedPortInt(ctx, "8000-8001/tcp")
+		require.ErrorContains(t, err, "parse mapped port 8000-8001/tcp of 8000-8001/tcp")
+	})
+}
+
+func TestContainerWithReaperDisabled(t *testing.T) {
+	ctx := context.Background()
+
+	req := GenericContainerRequest{
+		ProviderType: providerType,
+		ContainerRequest: ContainerRequest{
+			Image: nginxAlpineImage,
+		},
+		Started: true,
+	}
+
+	// withReaperDisabled {
+	require.NoError(t, WithReaperDisabled().Customize(&req))
+
+	nginx, err := GenericContainer(ctx, req)
+	// }
+	require.NoError(t, err)
+
+	inspect, err := nginx.Inspect(ctx)
+	require.NoError(t, err)
+
+	// without the session label, the reaper does not match the container
+	assert.NotContains(t, inspect.Config.Labels, core.LabelSessionID)
+	assert.Equal(t, "true", inspect.Config.Labels[core.LabelBase])
+
+	// the reaper does not terminate the container, so the test does
+	require.NoError(t, nginx.Terminate(ctx))
+
+	cli, err := NewDockerClientWithOpts(ctx)
+	require.NoError(t, err)
+	defer cli.Close()
+
+	_, err = cli.ContainerInspect(ctx, nginx.GetContainerID())
+	require.True(t, client.IsErrNotFound(err), "expected the container to be removed, got: %v", err)
+}
+
+func TestDockerProvider_FindContainersByLabel(t *testing.T) {
+	ctx := context.Background()
+
+	// the value is unique, so the containers of previous runs are not found
+	value := uuid.NewString()
+
+	runLabeled := func(labels map[string]string) Container {
+		req := GenericContainerRequest{
+			ProviderType: providerType,
+			ContainerRequest: ContainerRequest{
+				Image: nginxAlpineImage,
+			},
+			Started: true,
+		}
+		require.NoError(t, WithLabels(labels).Customize(&req))
+
+		c, err := GenericContainer(ctx, req)
+		require.NoError(t, err)
+		terminateContainerOnEnd(t, ctx, c)
+
+		return c
+	}
+
+	// findContainersByLabel {
+	broker := runLabeled(map[string]string{"testcontainers.test.role": "broker-" + value})
+	runLabeled(map[string]string{"testcontainers.test.role": "client-" + value})
+
+	provider, err := NewDockerProvider()
+	require.NoError(t, err)
+	defer provider.Close()
+
+	containers, err := provider.FindContainersByLabel(ctx, "testcontainers.test.role", "broker-"+value)
+	require.NoError(t, err)
+	// }
+
+	require.Len(t, containers, 1)
+	assert.Equal(t, broker.GetContainerID(), containers[0].GetContainerID())
+	assert.True(t, containers[0].IsRunning())
+
+	// the labels of testcontainers are kept
+	inspect, err := containers[0].Inspect(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, broker.SessionID(), inspect.Config.Labels[core.LabelSessionID])
+}
+
+func TestContainerTerminationRemovesAnonymousVolumes(t *testing.T) {
+	ctx := context.Background()
+
+	cli, err := NewDockerClientWithOpts(ctx)
+	require.NoError(t, err)
+	defer cli.Close()
+
+	namedVolume := "testcontainers-named-" + uuid.NewString()
+	t.Cleanup(func() {
+		require.NoError(t, cli.VolumeRemove(ctx, namedVolume, true))
+	})
+
+	// the redis image declares a VOLUME for /data, so each run creates an anonymous volume
+	for i := 0; i < 2; i++ {
+		redis, err := GenericContainer(ctx, GenericContainerRequest{
+			ProviderType: providerType,
+			ContainerRequest: ContainerRequest{
+				Image:  "docker.io/redis:latest",
+				Mounts: ContainerMounts{VolumeMount(namedVolume, "/named")},
+			},
+			Started: true,
+		})
+		require.NoError(t, err)
+
+		inspect, err := redis.Inspect(ctx)
+		require.NoError(t, err)
+
+		var anonymousVolumes []string
+		for _, m := range inspect.Mounts {
+			if m.Type == mount.TypeVolume && m.Name != namedVolume {
+				anonymousVolumes = append(anonymousVolumes, m.Name)
+			}
+		}
+		require.NotEmpty(t, anonymousVolumes)
+
+		require.NoError(t, redis.Terminate(ctx))
+
+		for _, name := range anonymousVolumes {
+			_, err := cli.VolumeInspect(ctx, name)
+			require.True(t, errdefs.IsNotFound(err), "expected the anonymous volume %s to be removed, got: %v", name, err)
+		}
+
+		_, err = cli.VolumeInspect(ctx, namedVolume)
+		require.NoError(t, err, "expected the named volume to be kept")
+	}
+}
+
 func TestContainerTerminationRemovesDockerImage(t *testing.T) {
 	t.Run("if not built from Dockerfile", func(t *testing.T) {
 		ctx := context.Background()
@@ -944,6 +1239,35 @@ func TestEntrypoint(t *testing.T) {
 	terminateContainerOnEnd(t, ctx, c)
 }
 
+func TestEntrypointWithCmd(t *testing.T) {
+	ctx := context.Background()
+
+	req := GenericContainerRequest{
+		ProviderType: providerType,
+		ContainerRequest: ContainerRequest{
+			Image: "docker.io/alpine",
+			Cmd:   []string{"infinity"},
+		},
+		Started: true,
+	}
+
+	// withEntrypoint {
+	err := WithEntrypoint("sleep").Customize(&req)
+	// }
+	require.NoError(t, err)
+
+	c, err := GenericContainer(ctx, req)
+	require.NoError(t, err)
+	terminateContainerOnEnd(t, ctx, c)
+
+	inspect, err := c.Inspect(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"sleep"}, []string(inspect.Config.Entrypoint))
+	assert.Equal(t, []string{"infinity"}, []string(inspect.Config.Cmd))
+	assert.True(t, inspect.State.Running)
+}
+
 func TestWorkingDir(t *testing.T) {
 	/*
 		print the current working directory to ensure that
@@ -1707,6 +2031,88 @@ func TestDockerContainerCopyEmptyFileFromContainer(t *testing.T) {
 	assert.Empty(t, fileContentFromContainer)
 }
 
+func TestDockerContainerCopyFromContainer(t *testing.T) {
+	ctx := context.Background()
+
+	nginxC, err := GenericContainer(ctx, GenericContainerRequest{
+		ProviderType: providerType,
+		ContainerRequest: ContainerRequest{
+			Image: nginxAlpineImage,
+		},
+		Started: true,
+	})
+	require.NoError(t, err)
+	terminateContainerOnEnd(t, ctx, nginxC)
+
+	code, _, err := nginxC.Exec(ctx, []string{"sh", "-c", "mkdir -p /tmp/data/topic-0 && echo broker.id=1 > /tmp/data/meta.properties && echo -n log > /tmp/data/topic-0/00000000000000000000.log"})
+	require.NoError(t, err)
+	require.Zero(t, code)
+
+	// readTarStream returns the content of the regular files in the tar stream, by path
+	readTarStream := func(t *testing.T, r io.Reader) map[string]string {
+		t.Helper()
+
+		files := map[string]string{}
+		tr := tar.NewReader(r)
+		for {
+			header, err := tr.Next()
+			if errors.Is(err, io.EOF) {
+				return files
+			}
+			require.NoError(t, err)
+
+			if header.Typeflag != tar.TypeReg {
+				continue
+			}
+
+			content, err := io.ReadAll(tr)
+			require.NoError(t, err)
+			files[header.Name] = string(content)
+		}
+	}
+
+	t.Run("directory", func(t *testing.T) {
+		// copyFromContainer {
+		reader, err := nginxC.(*DockerContainer).CopyFromContainer(ctx, "/tmp/data")
+		require.NoError(t, err)
+		defer reader.Close()
+		// }
+
+		assert.Equal(t, map[string]string{
+			"data/meta.properties":                  "broker.id=1\n",
+			"data/topic-0/00000000000000000000.log": "log",
+		}, readTarStream(t, reader))
+	})
+
+	t.Run("file", func(t *testing.T) {
+		reader, err := nginxC.(*DockerContainer).CopyFromContainer(ctx, "/tmp/data/meta.properties")
+		require.NoError(t, err)
+		defer reader.Close()
+
+		assert.Equal(t, map[string]string{"meta.properties": "broker.id=1\n"}, readTarStream(t, reader))
+	})
+
+	t.Run("file content", func(t *testing.T) {
+		reader, err := nginxC.CopyFileFromContainer(ctx, "/tmp/data/meta.properties")
+		require.NoError(t, err)
+		defer reader.Close()
+
+		content, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Equal(t, "broker.id=1\n", string(content))
+	})
+
+	t.Run("file content of a directory", func(t *testing.T) {
+		_, err := nginxC.CopyFileFromContainer(ctx, "/tmp/data")
+		require.ErrorContains(t, err, "/tmp/data is a directory")
+	})
+
+	t.Run("missing path", func(t *testing.T) {
+		_, err := nginxC.(*DockerContainer).CopyFromContainer(ctx, "/tmp/missing")
+		require.Error(t, err)
+	})
+}
+
 func TestDockerContainerResources(t *testing.T) {
 	if providerType == ProviderPodman {
 		t.Skip("Rootless Podman does not support setting rlimit")
@@ -1880,6 +2286,95 @@ func TestContainerWithNoUserID(t *testing.T) {
 	assert.Equal(t, "0", actual)
 }
 
+func TestContainerLogsWithOptions(t *testing.T) {
+	ctx := context.Background()
+	req := ContainerRequest{
+		Image:      "docker.io/alpine:latest",
+		Cmd:        []string{"sh", "-c", "for i in 1 2 3 4 5; do echo line $i; done"},
+		WaitingFor: wait.ForExit(),
+	}
+	c, err := GenericContainer(ctx, GenericContainerRequest{
+		ProviderType:     providerType,
+		ContainerRequest: req,
+		Started:          true,
+	})
+
+	require.NoError(t, err)
+	terminateContainerOnEnd(t, ctx, c)
+
+	dockerContainer, ok := c.(*DockerContainer)
+	require.True(t, ok)
+
+	readLogs := func(t *testing.T, opts LogOptions) string {
+		t.Helper()
+
+		r, err := dockerContainer.LogsWithOptions(ctx, opts)
+		require.NoError(t, err)
+		defer r.Close()
+
+		b, err := io.ReadAll(r)
+		require.NoError(t, err)
+
+		return string(b)
+	}
+
+	t.Run("all", func(t *testing.T) {
+		assert.Equal(t, "line 1\nline 2\nline 3\nline 4\nline 5\n", readLogs(t, LogOptions{}))
+	})
+
+	t.Run("tail", func(t *testing.T) {
+		// logsWithOptions {
+		logs := readLogs(t, LogOptions{Tail: 2})
+		// }
+		assert.Equal(t, "line 4\nline 5\n", logs)
+	})
+
+	t.Run("since", func(t *testing.T) {
+		assert.Empty(t, readLogs(t, LogOptions{Since: time.Now().Add(time.Hour)}))
+	})
+}
+
+func TestLogOptions_dockerOptions(t *testing.T) {
+	since := time.Date(2024, 4, 1, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		opts   LogOptions
+		expect container.LogsOptions
+	}{
+		{
+			name:   "zero value",
+			opts:   LogOptions{},
+			expect: container.LogsOptions{ShowStdout: true, ShowStderr: true},
+		},
+		{
+			name:   "since",
+			opts:   LogOptions{Since: since},
+			expect: container.LogsOptions{ShowStdout: true, ShowStderr: true, Since: "2024-04-01T10:30:00Z"},
+		},
+		{
+			name:   "tail",
+			opts:   LogOptions{Tail: 100},
+			expect: container.LogsOptions{ShowStdout: true, ShowStderr: true, Tail: "100"},
+		},
+		{
+			name:   "negative tail",
+			opts:   LogOptions{Tail: -1},
+			expect: container.LogsOptions{ShowStdout: true, ShowStderr: true},
+		},
+		{
+			name:   "follow",
+			opts:   LogOptions{Follow: true},
+			expect: container.LogsOptions{ShowStdout: true, ShowStderr: true, Follow: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, tt.opts.dockerOptions())
+		})
+	}
+}
+
 func TestGetGatewayIP(t *testing.T) {
 	// When using docker compose with DinD mode, and using host port or http wait strategy
 	// It's need to invoke GetGatewayIP for get the host
@@ -2099,6 +2594,7 @@ type errMockCli struct {
 	imageBuildCount    int
 	containerListCount int
 	imagePullCount     int
+	imagePullOptions   types.ImagePullOptions
 }
 
 func (f *errMockCli) ImageBuild(_ context.Context, _ io.Reader, _ types.ImageBuildOptions) (types.ImageBuildResponse, error) {
@@ -2111,8 +2607,9 @@ func (f *errMockCli) ContainerList(_ context.Context, _ container.ListOptions) (
 	return []types.Container{{}}, f.err
 }
 
-func (f *errMockCli) ImagePull(_ context.Context, _ string, _ types.ImagePullOptions) (io.ReadCloser, error) {
+func (f *errMockCli) ImagePull(_ context.Context, _ string, options types.ImagePullOptions) (io.ReadCloser, error) {
 	f.imagePullCount++
+	f.imagePullOptions = options
 	return io.NopCloser(&bytes.Buffer{}), f.err
 }
 
@@ -2292,3 +2789,33 @@ func TestDockerProvider_attemptToPullImage_retries(t *testing.T) {
 		})
 	}
 }
+
+func TestDockerProvider_attemptToPullImage_registryAuth(t *testing.T) {
+	p, err := NewDockerProvider()
+	require.NoError(t, err)
+
+	t.Run("keep the registry auth of the pull options", func(t *testing.T) {
+		m := &errMockCli{}
+		p.client = m
+
+		auth, err := encodeImageAuth(registry.AuthConfig{Username: "gopher", Password: "secret"})
+		require.NoError(t, err)
+
+		err = p.attemptToPullImage(context.Background(), "myregistry.local/someTag", types.ImagePullOptions{RegistryAuth: auth})
+		require.NoError(t, err)
+
+		assert.Equal(t, auth, m.imagePullOptions.RegistryAuth)
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		m := &errMockCli{err: errdefs.Unauthorized(errors.New("authentication required"))}
+		p.client = m
+
+		auth, err := encodeImageAuth(registry.AuthConfig{Username: "gopher", Password: "wrong"})
+		require.NoError(t, err)
+
+		err = p.attemptToPullImage(context.Background(), "myregistry.local/someTag", types.ImagePullOptions{RegistryAuth: auth})
+		require.ErrorContains(t, err, "pull image myregistry.local/someTag: not authorized by its registry, check the registry credentials")
+		require.True(t, errdefs.IsUnauthorized(err))
+	})
+}
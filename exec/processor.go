@@ -3,6 +3,7 @@ package exec
 import (
 	"bytes"
 	"io"
+	"sort"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/pkg/stdcopy"
@@ -42,24 +43,45 @@ func (fn ProcessOptionFunc) Apply(opts *ProcessOptions) {
 	fn(opts)
 }
 
+// WithUser returns a [ProcessOption] that runs the command as the given user,
+// in any of the formats accepted by Docker, e.g. "appuser", "1000" or "1000:1000".
 func WithUser(user string) ProcessOption {
 	return ProcessOptionFunc(func(opts *ProcessOptions) {
 		opts.ExecConfig.User = user
 	})
 }
 
+// WithWorkingDir returns a [ProcessOption] that runs the command in the given directory of the container.
 func WithWorkingDir(workingDir string) ProcessOption {
 	return ProcessOptionFunc(func(opts *ProcessOptions) {
 		opts.ExecConfig.WorkingDir = workingDir
 	})
 }
 
+// WithEnv returns a [ProcessOption] that sets the environment variables of the command,
+// in the KEY=VALUE format, replacing the ones set by previous options.
 func WithEnv(env []string) ProcessOption {
 	return ProcessOptionFunc(func(opts *ProcessOptions) {
 		opts.ExecConfig.Env = env
 	})
 }
 
+// WithEnvMap returns a [ProcessOption] that adds the environment variables of the map
+// to the ones of the command, sorted by key.
+func WithEnvMap(env map[string]string) ProcessOption {
+	return ProcessOptionFunc(func(opts *ProcessOptions) {
+		keys := make([]string, 0, len(env))
+		for key := range env {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			opts.ExecConfig.Env = append(opts.ExecConfig.Env, key+"="+env[key])
+		}
+	})
+}
+
 // Multiplexed returns a [ProcessOption] that configures the command execution
 // to combine stdout and stderr into a single stream without Docker's multiplexing headers.
 func Multiplexed() ProcessOption {
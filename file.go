@@ -30,7 +30,9 @@ func isDir(path string) (bool, error) {
 	return false, nil
 }
 
-// tarDir compress a directory using tar + gzip algorithms
+// tarDir compress a directory using tar + gzip algorithms, preserving its structure, including the
+// empty directories, and its symlinks. The files and directories get the given mode, or keep their
+// own permissions if the mode is zero.
 func tarDir(src string, fileMode int64) (*bytes.Buffer, error) {
 	// always pass src as absolute path
 	abs, err := filepath.Abs(src)
@@ -57,14 +59,17 @@ func tarDir(src string, fileMode int64) (*bytes.Buffer, error) {
 			return fmt.Errorf("error traversing the file system: %w", errFn)
 		}
 
-		// if a symlink, skip file
+		// if a symlink, keep it as a symlink, pointing at the same target
+		var link string
 		if fi.Mode().Type() == os.ModeSymlink {
-			Logger.Printf(">> skipping symlink: %s\n", file)
-			return nil
+			link, err = os.Readlink(file)
+			if err != nil {
+				return fmt.Errorf("error reading symlink: %w", err)
+			}
 		}
 
 		// generate tar header
-		header, err := tar.FileInfoHeader(fi, file)
+		header, err := tar.FileInfoHeader(fi, link)
 		if err != nil {
 			return fmt.Errorf("error getting file info header: %w", err)
 		}
@@ -73,15 +78,17 @@ func tarDir(src string, fileMode int64) (*bytes.Buffer, error) {
 		// Since fs.FileInfo's Name method only returns the base name of the file it describes,
 		// it may be necessary to modify Header.Name to provide the full path name of the file.
 		header.Name = filepath.ToSlash(file[index:])
-		header.Mode = fileMode
+		if fileMode != 0 && link == "" {
+			header.Mode = fileMode
+		}
 
 		// write header
 		if err := tw.WriteHeader(header); err != nil {
 			return fmt.Errorf("error writing header: %w", err)
 		}
 
-		// if not a dir, write file content
-		if !fi.IsDir() {
+		// if a regular file, write file content
+		if fi.Mode().IsRegular() {
 			data, err := os.Open(file)
 			if err != nil {
 				return fmt.Errorf("error opening file: %w", err)
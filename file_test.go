@@ -113,6 +113,66 @@ func Test_TarDir(t *testing.T) {
 	}
 }
 
+func Test_TarDirTree(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "tree")
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "nested", "deeper"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "empty"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "readme.txt"), []byte("readme"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "nested", "deeper", "run.sh"), []byte("#!/bin/sh"), 0o755))
+	require.NoError(t, os.Symlink("readme.txt", filepath.Join(src, "link")))
+
+	// headers returns the tar headers of the directory, by name
+	headers := func(fileMode int64) map[string]*tar.Header {
+		buff, err := tarDir(src, fileMode)
+		require.NoError(t, err)
+
+		gzr, err := gzip.NewReader(buff)
+		require.NoError(t, err)
+		defer gzr.Close()
+
+		tr := tar.NewReader(gzr)
+
+		headers := map[string]*tar.Header{}
+		for {
+			header, err := tr.Next()
+			if err == io.EOF {
+				return headers
+			}
+			require.NoError(t, err)
+
+			headers[header.Name] = header
+		}
+	}
+
+	t.Run("preserve permissions", func(t *testing.T) {
+		hdrs := headers(0)
+
+		require.Contains(t, hdrs, "tree/empty")
+		assert.Equal(t, byte(tar.TypeDir), hdrs["tree/empty"].Typeflag)
+		assert.Equal(t, int64(0o750), hdrs["tree/empty"].Mode&0o777)
+
+		require.Contains(t, hdrs, "tree/nested/deeper/run.sh")
+		assert.Equal(t, int64(0o755), hdrs["tree/nested/deeper/run.sh"].Mode&0o777)
+
+		require.Contains(t, hdrs, "tree/readme.txt")
+		assert.Equal(t, int64(0o644), hdrs["tree/readme.txt"].Mode&0o777)
+
+		require.Contains(t, hdrs, "tree/link")
+		assert.Equal(t, byte(tar.TypeSymlink), hdrs["tree/link"].Typeflag)
+		assert.Equal(t, "readme.txt", hdrs["tree/link"].Linkname)
+	})
+
+	t.Run("override permissions", func(t *testing.T) {
+		hdrs := headers(0o700)
+
+		assert.Equal(t, int64(0o700), hdrs["tree/readme.txt"].Mode)
+		assert.Equal(t, int64(0o700), hdrs["tree/nested/deeper/run.sh"].Mode)
+
+		// the symlinks are kept as symlinks
+		assert.Equal(t, byte(tar.TypeSymlink), hdrs["tree/link"].Typeflag)
+	})
+}
+
 func Test_TarFile(t *testing.T) {
 	b, err := os.ReadFile(filepath.Join(".", "testdata", "Dockerfile"))
 	if err != nil {
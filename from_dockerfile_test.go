@@ -156,6 +156,66 @@ func TestBuildImageFromDockerfile_Target(t *testing.T) {
 	}
 }
 
+func TestBuildImageFromDockerfile_TargetWithBuildArgs(t *testing.T) {
+	ctx := context.Background()
+
+	// fromDockerfileWithTarget {
+	version := "3.7.0"
+
+	c, err := GenericContainer(ctx, GenericContainerRequest{
+		ContainerRequest: ContainerRequest{
+			FromDockerfile: FromDockerfile{
+				Context:    "testdata",
+				Dockerfile: "stages.Dockerfile",
+				BuildArgs: map[string]*string{
+					"CLIENT_VERSION": &version,
+				},
+				Target: "client",
+			},
+		},
+		Started: true,
+	})
+	// }
+	require.NoError(t, err)
+	terminateContainerOnEnd(t, ctx, c)
+
+	// the command of the non-final stage is run, reading the file written with the build arg
+	require.Eventually(t, func() bool {
+		state, err := c.State(ctx)
+		return err == nil && !state.Running
+	}, 10*time.Second, 100*time.Millisecond)
+
+	r, err := c.Logs(ctx)
+	require.NoError(t, err)
+
+	logs, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	assert.Equal(t, "client 3.7.0\n", string(logs))
+}
+
+func TestContainerRequest_BuildOptionsTarget(t *testing.T) {
+	req := ContainerRequest{
+		FromDockerfile: FromDockerfile{
+			ContextArchive: strings.NewReader(""),
+			Target:         "client",
+		},
+	}
+
+	opts, err := req.BuildOptions()
+	require.NoError(t, err)
+	assert.Equal(t, "client", opts.Target)
+
+	// the build options modifier can still override the target
+	req.FromDockerfile.BuildOptionsModifier = func(buildOptions *types.ImageBuildOptions) {
+		buildOptions.Target = "final"
+	}
+
+	opts, err = req.BuildOptions()
+	require.NoError(t, err)
+	assert.Equal(t, "final", opts.Target)
+}
+
 func ExampleGenericContainer_buildFromDockerfile() {
 	ctx := context.Background()
 
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"sync"
 
+	"github.com/docker/docker/errdefs"
+
 	"github.com/testcontainers/testcontainers-go/internal/core"
 )
 
@@ -71,6 +73,9 @@ func GenericContainer(ctx context.Context, req GenericContainerRequest) (Contain
 		c, err = provider.ReuseOrCreateContainer(ctx, req.ContainerRequest)
 	} else {
 		c, err = provider.CreateContainer(ctx, req.ContainerRequest)
+		if err != nil && req.Name != "" && errdefs.IsConflict(err) {
+			err = fmt.Errorf("container name %s already in use, enable the reuse to get the existing container: %w", req.Name, err)
+		}
 	}
 	if err != nil {
 		// At this point `c` might not be nil. Give the caller an opportunity to call Destroy on the container.
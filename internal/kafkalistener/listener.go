@@ -0,0 +1,164 @@
+// Package kafkalistener validates the custom listeners of the Kafka API, shared by the modules
+// running Kafka compatible brokers, i.e. the kafka and the redpanda modules, so that the same
+// listeners are accepted, and rejected with the same errors, by both.
+package kafkalistener
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// Listener is a custom listener of the Kafka API, as passed to the listener options of the modules.
+type Listener struct {
+	Name string
+	Ip   string
+	Port string
+}
+
+// Trim returns the listener with its fields trimmed, and its name uppercased, as the listener names
+// are case insensitive.
+func (l Listener) Trim() Listener {
+	return Listener{
+		Name: strings.ToUpper(strings.TrimSpace(l.Name)),
+		Ip:   strings.TrimSpace(l.Ip),
+		Port: strings.TrimSpace(l.Port),
+	}
+}
+
+// Reason is the reason why a listener is invalid.
+type Reason string
+
+const (
+	// ReservedName is the reason of a listener named after one of the reserved names, e.g. of the default listeners
+	ReservedName Reason = "reserved name"
+	// ReservedPort is the reason of a listener using one of the reserved ports, e.g. of the default listeners
+	ReservedPort Reason = "reserved port"
+	// DuplicateName is the reason of a listener named after a previous listener
+	DuplicateName Reason = "duplicate name"
+	// DuplicatePort is the reason of a listener using the port of a previous listener
+	DuplicatePort Reason = "duplicate port"
+	// InvalidPort is the reason of a listener whose port is not a number between 1 and 65535
+	InvalidPort Reason = "invalid port"
+	// EmptyName is the reason of a listener without a name
+	EmptyName Reason = "empty name"
+	// EmptyHost is the reason of a listener without a host
+	EmptyHost Reason = "empty host"
+	// ReservedHost is the reason of a listener whose host is the loopback or the wildcard address, used by the default listeners
+	ReservedHost Reason = "reserved host"
+)
+
+// ValidationError describes an invalid listener, so the caller can tell which listener and which of
+// its fields is invalid, and why, e.g. with errors.As.
+type ValidationError struct {
+	// Index is the index of the invalid listener in the listeners of the container.
+	Index int
+	// Name is the trimmed and uppercased name of the invalid listener. It's empty if the name is empty.
+	Name string
+	// Field is the name of the invalid field of the listener, e.g. Name, Ip or Port.
+	Field string
+	// Reason is the reason why the field is invalid.
+	Reason Reason
+	// detail describes the invalid value of the field.
+	detail string
+}
+
+// NewValidationError returns the ValidationError of the field of the listener at the index, whose
+// invalid value is described by the format and its arguments.
+func NewValidationError(index int, name string, field string, reason Reason, format string, args ...any) *ValidationError {
+	return &ValidationError{Index: index, Name: name, Field: field, Reason: reason, detail: fmt.Sprintf(format, args...)}
+}
+
+// Error returns the description of the invalid listener, identified by its name, or by its index if it has none.
+func (e *ValidationError) Error() string {
+	if e.Name == "" {
+		return fmt.Sprintf("listener at index %d: %s", e.Index, e.detail)
+	}
+
+	return fmt.Sprintf("listener %s: %s", e.Name, e.detail)
+}
+
+// Validator validates the custom listeners of a container, checking that their hosts and ports are
+// valid, and that their names and ports are unique, and do not collide with the reserved ones.
+type Validator struct {
+	reservedNames map[string]bool
+	reservedPorts map[int]bool
+	names         map[string]bool
+	ports         map[int]bool
+}
+
+// NewValidator returns a Validator rejecting the listeners using one of the reserved names, which
+// are compared case insensitively, or one of the reserved ports, e.g. the ones of the default listeners.
+func NewValidator(reservedNames []string, reservedPorts []int) *Validator {
+	v := &Validator{
+		reservedNames: make(map[string]bool, len(reservedNames)),
+		reservedPorts: make(map[int]bool, len(reservedPorts)),
+		names:         make(map[string]bool),
+		ports:         make(map[int]bool),
+	}
+
+	for _, name := range reservedNames {
+		v.reservedNames[strings.ToUpper(name)] = true
+	}
+
+	for _, port := range reservedPorts {
+		v.reservedPorts[port] = true
+	}
+
+	return v
+}
+
+// Validate validates the trimmed listener at the index of the listeners of the container, returning
+// its port. The name and the port of a valid listener are reserved, so the next ones can't reuse them.
+// An invalid listener is reported with a ValidationError.
+func (v *Validator) Validate(index int, l Listener) (int, error) {
+	if l.Name == "" {
+		return 0, NewValidationError(index, l.Name, "Name", EmptyName, "empty name")
+	}
+
+	if l.Ip == "" {
+		return 0, NewValidationError(index, l.Name, "Ip", EmptyHost, "empty host")
+	}
+
+	if IsImplicitHost(l.Ip) {
+		return 0, NewValidationError(index, l.Name, "Ip", ReservedHost, "host %s collides with the default listeners, use a network alias of the container instead", l.Ip)
+	}
+
+	port, err := strconv.Atoi(l.Port)
+	if err != nil || port < 1 || port > 65535 {
+		return 0, NewValidationError(index, l.Name, "Port", InvalidPort, "invalid port %q", l.Port)
+	}
+
+	if v.reservedNames[l.Name] {
+		return 0, NewValidationError(index, l.Name, "Name", ReservedName, "name %s is reserved", l.Name)
+	}
+
+	if v.names[l.Name] {
+		return 0, NewValidationError(index, l.Name, "Name", DuplicateName, "duplicate of listener name: %s", l.Name)
+	}
+	v.names[l.Name] = true
+
+	if v.reservedPorts[port] {
+		return 0, NewValidationError(index, l.Name, "Port", ReservedPort, "port %s is reserved", l.Port)
+	}
+
+	if v.ports[port] {
+		return 0, NewValidationError(index, l.Name, "Port", DuplicatePort, "duplicate of listener port: %s", l.Port)
+	}
+	v.ports[port] = true
+
+	return port, nil
+}
+
+// IsImplicitHost returns true if the host is the loopback or the wildcard address, which are used
+// by the default listeners, and are not reachable by other containers anyway.
+func IsImplicitHost(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+
+	ip := net.ParseIP(strings.Trim(host, "[]"))
+
+	return ip != nil && (ip.IsLoopback() || ip.IsUnspecified())
+}
@@ -0,0 +1,169 @@
+package kafkalistener
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrim(t *testing.T) {
+	l := Listener{Name: "\tcOnTrOller\n", Ip: " kafka ", Port: " 9095 "}.Trim()
+
+	assert.Equal(t, Listener{Name: "CONTROLLER", Ip: "kafka", Port: "9095"}, l)
+}
+
+func TestIsImplicitHost(t *testing.T) {
+	tests := []struct {
+		host     string
+		implicit bool
+	}{
+		{host: "localhost", implicit: true},
+		{host: "LocalHost", implicit: true},
+		{host: "127.0.0.1", implicit: true},
+		{host: "127.0.1.1", implicit: true},
+		{host: "0.0.0.0", implicit: true},
+		{host: "::1", implicit: true},
+		{host: "[::1]", implicit: true},
+		{host: "::", implicit: true},
+		{host: "[::]", implicit: true},
+		{host: "kafka", implicit: false},
+		{host: "10.0.0.1", implicit: false},
+		{host: "[fd00::1]", implicit: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			assert.Equal(t, tt.implicit, IsImplicitHost(tt.host))
+		})
+	}
+}
+
+func TestValidator(t *testing.T) {
+	reservedNames := []string{"CONTROLLER", "external"}
+	reservedPorts := []int{9093, 9094}
+
+	tests := []struct {
+		name      string
+		listeners []Listener
+		wantErr   string
+		reason    Reason
+	}{
+		{
+			name:      "valid",
+			listeners: []Listener{{Name: "test", Ip: "kafka", Port: "9095"}, {Name: "test2", Ip: "kafka", Port: "9096"}},
+		},
+		{
+			name:      "valid after trimming",
+			listeners: []Listener{{Name: " test ", Ip: " kafka ", Port: " 9095 "}},
+		},
+		{
+			name:      "empty name",
+			listeners: []Listener{{Name: " ", Ip: "kafka", Port: "9092"}},
+			wantErr:   "listener at index 0: empty name",
+			reason:    EmptyName,
+		},
+		{
+			name:      "empty host",
+			listeners: []Listener{{Name: "internal", Ip: " ", Port: "9092"}},
+			wantErr:   "listener INTERNAL: empty host",
+			reason:    EmptyHost,
+		},
+		{
+			name:      "loopback host",
+			listeners: []Listener{{Name: "internal", Ip: "LOCALHOST", Port: "9092"}},
+			wantErr:   "listener INTERNAL: host LOCALHOST collides with the default listeners",
+			reason:    ReservedHost,
+		},
+		{
+			name:      "wildcard host",
+			listeners: []Listener{{Name: "internal", Ip: "0.0.0.0", Port: "9092"}},
+			wantErr:   "listener INTERNAL: host 0.0.0.0 collides with the default listeners",
+			reason:    ReservedHost,
+		},
+		{
+			name:      "non-numeric port",
+			listeners: []Listener{{Name: "internal", Ip: "kafka", Port: "abc"}},
+			wantErr:   `listener INTERNAL: invalid port "abc"`,
+			reason:    InvalidPort,
+		},
+		{
+			name:      "out of range port",
+			listeners: []Listener{{Name: "internal", Ip: "kafka", Port: "70000"}},
+			wantErr:   `listener INTERNAL: invalid port "70000"`,
+			reason:    InvalidPort,
+		},
+		{
+			name:      "reserved name",
+			listeners: []Listener{{Name: "  cOnTrOller   ", Ip: "kafka", Port: "9092"}},
+			wantErr:   "listener CONTROLLER: name CONTROLLER is reserved",
+			reason:    ReservedName,
+		},
+		{
+			name:      "reserved name in lower case",
+			listeners: []Listener{{Name: "External", Ip: "kafka", Port: "9092"}},
+			wantErr:   "listener EXTERNAL: name EXTERNAL is reserved",
+			reason:    ReservedName,
+		},
+		{
+			name:      "reserved port",
+			listeners: []Listener{{Name: "internal", Ip: "kafka", Port: "9093"}},
+			wantErr:   "listener INTERNAL: port 9093 is reserved",
+			reason:    ReservedPort,
+		},
+		{
+			name:      "duplicate name",
+			listeners: []Listener{{Name: "test", Ip: "kafka", Port: "9092"}, {Name: "TEST", Ip: "kafka", Port: "9095"}},
+			wantErr:   "listener TEST: duplicate of listener name: TEST",
+			reason:    DuplicateName,
+		},
+		{
+			name:      "duplicate port",
+			listeners: []Listener{{Name: "test", Ip: "kafka", Port: "9092"}, {Name: "test2", Ip: "kafka", Port: "9092"}},
+			wantErr:   "listener TEST2: duplicate of listener port: 9092",
+			reason:    DuplicatePort,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewValidator(reservedNames, reservedPorts)
+
+			var err error
+			for i, l := range tt.listeners {
+				if _, err = v.Validate(i, l.Trim()); err != nil {
+					break
+				}
+			}
+
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorContains(t, err, tt.wantErr)
+
+			var validationErr *ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, tt.reason, validationErr.Reason)
+		})
+	}
+}
+
+func TestValidationError(t *testing.T) {
+	v := NewValidator(nil, nil)
+
+	_, err := v.Validate(0, Listener{Name: "BROKER", Ip: "kafka", Port: "9092"})
+	require.NoError(t, err)
+
+	_, err = v.Validate(1, Listener{Name: "CROSS", Ip: "kafka", Port: "9092"})
+
+	// the second listener is reported, identified by its index and its name
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, ValidationError{Index: 1, Name: "CROSS", Field: "Port", Reason: DuplicatePort, detail: "duplicate of listener port: 9092"}, *validationErr)
+	assert.Equal(t, "listener CROSS: duplicate of listener port: 9092", validationErr.Error())
+
+	_, err = v.Validate(2, Listener{Ip: "kafka", Port: "9093"})
+	require.EqualError(t, err, "listener at index 2: empty name")
+}
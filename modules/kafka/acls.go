@@ -0,0 +1,75 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/IBM/sarama"
+)
+
+// ACLBinding represents an access control entry bound to a resource of the Kafka container.
+type ACLBinding struct {
+	// ResourceType is the type of the resource, e.g. sarama.AclResourceTopic.
+	ResourceType sarama.AclResourceType
+	// ResourceName is the name of the resource, e.g. the name of the topic.
+	ResourceName string
+	// PatternType is the pattern type of the resource name. Defaults to sarama.AclPatternLiteral.
+	PatternType sarama.AclResourcePatternType
+	// Principal is the principal the entry applies to, e.g. "User:alice".
+	Principal string
+	// Host is the host the entry applies to. Defaults to "*", i.e. any host.
+	Host string
+	// Operation is the operation the entry applies to, e.g. sarama.AclOperationWrite.
+	Operation sarama.AclOperation
+	// PermissionType defines whether the operation is allowed or denied. Defaults to sarama.AclPermissionAllow.
+	PermissionType sarama.AclPermissionType
+}
+
+// resourceAcls converts the binding into sarama's resource ACLs, applying the defaults.
+func (b ACLBinding) resourceAcls() *sarama.ResourceAcls {
+	acls := &sarama.ResourceAcls{
+		Resource: sarama.Resource{
+			ResourceType:        b.ResourceType,
+			ResourceName:        b.ResourceName,
+			ResourcePatternType: b.PatternType,
+		},
+		Acls: []*sarama.Acl{
+			{
+				Principal:      b.Principal,
+				Host:           b.Host,
+				Operation:      b.Operation,
+				PermissionType: b.PermissionType,
+			},
+		},
+	}
+
+	if acls.Resource.ResourcePatternType == sarama.AclPatternUnknown {
+		acls.Resource.ResourcePatternType = sarama.AclPatternLiteral
+	}
+
+	if acls.Acls[0].Host == "" {
+		acls.Acls[0].Host = "*"
+	}
+
+	if acls.Acls[0].PermissionType == sarama.AclPermissionUnknown {
+		acls.Acls[0].PermissionType = sarama.AclPermissionAllow
+	}
+
+	return acls
+}
+
+// CreateACL creates the access control entry defined by the binding, connecting to the brokers
+// of the container. The authorizer must be enabled with the WithAuthorizer option.
+func (kc *KafkaContainer) CreateACL(ctx context.Context, binding ACLBinding) error {
+	admin, err := kc.clusterAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	defer admin.Close()
+
+	if err := admin.CreateACLs([]*sarama.ResourceAcls{binding.resourceAcls()}); err != nil {
+		return fmt.Errorf("create acl for %s on %s: %w", binding.Principal, binding.ResourceName, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/IBM/sarama"
+)
+
+// APIVersionRange is the range of versions of an API supported by the broker.
+type APIVersionRange struct {
+	Min int16
+	Max int16
+}
+
+// APIVersions returns the versions of the APIs supported by the broker, mapping each API key, e.g. 0 for
+// Produce, to the minimum and maximum versions of the API. It sends an ApiVersions request with the config returned by
+// SaramaConfig to the first broker of the listener matching the config, so it authenticates if SASL was
+// enabled and trusts the CA of the broker if TLS was enabled.
+func (kc *KafkaContainer) APIVersions(ctx context.Context) (map[int16]APIVersionRange, error) {
+	config, err := kc.SaramaConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	brokers, err := kc.clientBrokers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return apiVersions(ctx, brokers[0], config)
+}
+
+// apiVersions sends an ApiVersions request to the broker at the address, returning the range
+// of versions of each API, or an error if the context is done before the broker responds.
+func apiVersions(ctx context.Context, addr string, config *sarama.Config) (map[int16]APIVersionRange, error) {
+	broker := sarama.NewBroker(addr)
+	if err := broker.Open(config); err != nil {
+		return nil, fmt.Errorf("open broker %s: %w", addr, err)
+	}
+	defer broker.Close()
+
+	type result struct {
+		response *sarama.ApiVersionsResponse
+		err      error
+	}
+
+	// the request does not take a context, so the result is buffered in case it's abandoned
+	results := make(chan result, 1)
+	go func() {
+		response, err := broker.ApiVersions(&sarama.ApiVersionsRequest{})
+		results <- result{response: response, err: err}
+	}()
+
+	select {
+	case r := <-results:
+		if r.err != nil {
+			return nil, fmt.Errorf("api versions: %w", r.err)
+		}
+
+		if kerr := sarama.KError(r.response.ErrorCode); kerr != sarama.ErrNoError {
+			return nil, fmt.Errorf("api versions: %w", kerr)
+		}
+
+		versions := make(map[int16]APIVersionRange, len(r.response.ApiKeys))
+		for _, key := range r.response.ApiKeys {
+			versions[key.ApiKey] = APIVersionRange{Min: key.MinVersion, Max: key.MaxVersion}
+		}
+
+		return versions, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,208 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/network"
+)
+
+// defaultBrokerCount is the number of brokers started by RunCluster if WithBrokerCount is not used
+const defaultBrokerCount = 3
+
+// KafkaCluster represents a cluster of Kafka containers sharing a KRaft quorum,
+// connected through a dedicated network.
+type KafkaCluster struct {
+	// ClusterID is the id of the cluster, shared by all the brokers.
+	ClusterID string
+	// Containers are the broker containers, ordered by node id.
+	Containers []*KafkaContainer
+	// Network is the network connecting the brokers.
+	Network *testcontainers.DockerNetwork
+}
+
+// RunCluster creates a cluster of inter-connected Kafka containers, one per broker, which act both as
+// brokers and controllers of a shared KRaft quorum. The number of brokers is set with WithBrokerCount,
+// defaulting to 3. The brokers are attached to a new network, where each one is reachable using the
+// "broker-<node id>" alias, and the options are applied to every broker.
+// The id of the cluster is generated, unless the WithClusterID option is used.
+func RunCluster(ctx context.Context, opts ...testcontainers.ContainerCustomizer) (*KafkaCluster, error) {
+	settings := defaultOptions()
+	for _, opt := range opts {
+		if apply, ok := opt.(Option); ok {
+			apply(&settings)
+		}
+	}
+
+	brokerCount := settings.BrokerCount
+	if brokerCount == 0 {
+		brokerCount = defaultBrokerCount
+	}
+
+	if brokerCount < 1 {
+		return nil, fmt.Errorf("invalid broker count: %d", brokerCount)
+	}
+
+	if settings.SchemaRegistry {
+		return nil, errors.New("schema registry not supported by RunCluster")
+	}
+
+	if settings.Connect {
+		return nil, errors.New("kafka connect not supported by RunCluster")
+	}
+
+	if settings.NodeID != 0 {
+		return nil, errors.New("node id not supported by RunCluster, which assigns the node ids of its brokers")
+	}
+
+	if settings.BrokerPort != "" {
+		return nil, errors.New("broker port not supported by RunCluster, which sets the internal listener of its brokers")
+	}
+
+	if settings.ReuseName != "" {
+		return nil, errors.New("reuse not supported by RunCluster, which starts new brokers")
+	}
+
+	if err := validateTopicDefaults(settings, brokerCount); err != nil {
+		return nil, fmt.Errorf("topic defaults validation: %w", err)
+	}
+
+	clusterID := GenerateClusterID()
+
+	nw, err := network.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("new network: %w", err)
+	}
+
+	cluster := &KafkaCluster{
+		Containers: make([]*KafkaContainer, brokerCount),
+		Network:    nw,
+	}
+
+	// the brokers must be started in parallel, as none of them is ready until the quorum is formed
+	var wg sync.WaitGroup
+	errs := make([]error, brokerCount)
+	for i := 0; i < brokerCount; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			nodeID := i + 1
+			cluster.Containers[i], errs[i] = RunContainer(ctx, brokerOptions(nw, clusterID, nodeID, brokerCount, opts)...)
+			if errs[i] != nil {
+				errs[i] = fmt.Errorf("broker %d: %w", nodeID, errs[i])
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, errors.Join(err, cluster.Terminate(ctx))
+	}
+
+	cluster.ClusterID = cluster.Containers[0].ClusterID
+
+	if len(settings.InitialTopics) > 0 {
+		if err := createInitialTopics(ctx, cluster.Containers[0], settings, cluster.Containers[0].image); err != nil {
+			return nil, errors.Join(fmt.Errorf("initial topics: %w", err), cluster.Terminate(ctx))
+		}
+	}
+
+	return cluster, nil
+}
+
+// brokerOptions returns the options for the broker with the given node id. The internal listener,
+// used for the inter-broker communication, is advertised with the network alias of the broker.
+func brokerOptions(nw *testcontainers.DockerNetwork, clusterID string, nodeID int, brokerCount int, opts []testcontainers.ContainerCustomizer) []testcontainers.ContainerCustomizer {
+	alias := brokerAlias(nodeID)
+
+	// the generated cluster id can be overridden by the options, as the
+	// cluster id derived from a label is the same for all the brokers
+	brokerOpts := []testcontainers.ContainerCustomizer{
+		WithListener([]KafkaListener{{Name: "INTERNAL", Ip: alias, Port: "9092"}}),
+		WithClusterID(clusterID),
+	}
+	brokerOpts = append(brokerOpts, opts...)
+
+	// each call to RunContainer starts one broker of the cluster
+	brokerOpts = append(brokerOpts,
+		WithBrokerCount(1),
+		withClusterSize(brokerCount),
+		network.WithNetwork([]string{alias}, nw),
+		testcontainers.WithEnv(brokerEnvs(nodeID, brokerCount)),
+	)
+
+	return brokerOpts
+}
+
+// brokerEnvs returns the environment variables identifying the broker in the quorum,
+// and setting the replication of the internal topics according to the size of the cluster.
+func brokerEnvs(nodeID int, brokerCount int) map[string]string {
+	replicationFactor := strconv.Itoa(min(brokerCount, 3))
+
+	return map[string]string{
+		"KAFKA_NODE_ID":                                  strconv.Itoa(nodeID),
+		"KAFKA_BROKER_ID":                                strconv.Itoa(nodeID),
+		"KAFKA_CONTROLLER_QUORUM_VOTERS":                 quorumVoters(brokerCount),
+		"KAFKA_OFFSETS_TOPIC_REPLICATION_FACTOR":         replicationFactor,
+		"KAFKA_TRANSACTION_STATE_LOG_REPLICATION_FACTOR": replicationFactor,
+	}
+}
+
+// quorumVoters returns the controller quorum voters of the cluster, built from the node id
+// and the network alias of each broker, e.g. "1@broker-1:9094,2@broker-2:9094".
+func quorumVoters(brokerCount int) string {
+	voters := make([]string, 0, brokerCount)
+	for nodeID := 1; nodeID <= brokerCount; nodeID++ {
+		voters = append(voters, fmt.Sprintf("%d@%s:9094", nodeID, brokerAlias(nodeID)))
+	}
+
+	return strings.Join(voters, ",")
+}
+
+func brokerAlias(nodeID int) string {
+	return fmt.Sprintf("broker-%d", nodeID)
+}
+
+// Brokers retrieves the broker connection strings of all the brokers in the cluster,
+// defined by the exposed public port of each one.
+func (kc *KafkaCluster) Brokers(ctx context.Context) ([]string, error) {
+	var brokers []string
+	for _, c := range kc.Containers {
+		b, err := c.Brokers(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		brokers = append(brokers, b...)
+	}
+
+	return brokers, nil
+}
+
+// Terminate terminates all the brokers of the cluster with the given options, and then removes the network connecting them.
+func (kc *KafkaCluster) Terminate(ctx context.Context, opts ...testcontainers.TerminateOption) error {
+	var errs []error
+	for _, c := range kc.Containers {
+		if c == nil {
+			continue
+		}
+
+		if err := c.Terminate(ctx, opts...); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if kc.Network != nil {
+		if err := kc.Network.Remove(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("remove network: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
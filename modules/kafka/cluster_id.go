@@ -0,0 +1,37 @@
+package kafka
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+
+	"github.com/google/uuid"
+)
+
+// GenerateClusterID returns a new random KRaft cluster id, i.e. a base64 encoded UUID.
+func GenerateClusterID() string {
+	id := uuid.New()
+	return base64.RawURLEncoding.EncodeToString(id[:])
+}
+
+// normalizeClusterID returns the cluster id to be used by the broker, and the human label it was
+// derived from, if any. A valid cluster id is returned as is, with an empty label. Any other value
+// is a label, deterministically hashed into a valid cluster id. An empty value generates a new id.
+func normalizeClusterID(value string) (string, string) {
+	if value == "" {
+		return GenerateClusterID(), ""
+	}
+
+	if isValidClusterID(value) {
+		return value, ""
+	}
+
+	sum := sha256.Sum256([]byte(value))
+
+	return base64.RawURLEncoding.EncodeToString(sum[:16]), value
+}
+
+// isValidClusterID returns true if the value is a base64 encoded UUID, as required by KRaft.
+func isValidClusterID(value string) bool {
+	id, err := base64.RawURLEncoding.DecodeString(value)
+	return err == nil && len(id) == 16
+}
@@ -0,0 +1,131 @@
+package kafka_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/IBM/sarama"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/kafka"
+)
+
+func TestKafkaCluster(t *testing.T) {
+	ctx := context.Background()
+
+	// runCluster {
+	cluster, err := kafka.RunCluster(ctx,
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+		kafka.WithBrokerCount(3),
+	)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := cluster.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate cluster: %s", err)
+		}
+	})
+
+	if len(cluster.Containers) != 3 {
+		t.Fatalf("expected 3 brokers, got %d", len(cluster.Containers))
+	}
+
+	err = cluster.Containers[0].CreateTopics(ctx, kafka.TopicSpec{
+		Name:              "replicated-topic",
+		Partitions:        3,
+		ReplicationFactor: 3,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// getClusterBrokers {
+	brokers, err := cluster.Brokers(ctx)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(brokers) != 3 {
+		t.Fatalf("expected 3 broker endpoints, got %d", len(brokers))
+	}
+
+	client, err := sarama.NewClient(brokers, sarama.NewConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	if len(client.Brokers()) != 3 {
+		t.Fatalf("expected the metadata to include 3 brokers, got %d", len(client.Brokers()))
+	}
+
+	partitions, err := client.Partitions("replicated-topic")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, partition := range partitions {
+		replicas, err := client.Replicas("replicated-topic", partition)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(replicas) != 3 {
+			t.Fatalf("expected 3 replicas for partition %d, got %d", partition, len(replicas))
+		}
+	}
+}
+
+func TestRunContainer_brokerCount(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := kafka.RunContainer(ctx,
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+		kafka.WithBrokerCount(3),
+	)
+	if err == nil {
+		t.Fatal("expected an error when starting more than one broker with RunContainer, got nil")
+	}
+}
+
+func TestKafkaCluster_withReuse(t *testing.T) {
+	_, err := kafka.RunCluster(context.Background(), kafka.WithReuse("shared-kafka"))
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestKafkaCluster_withInitialTopics(t *testing.T) {
+	ctx := context.Background()
+
+	cluster, err := kafka.RunCluster(ctx,
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+		kafka.WithBrokerCount(3),
+		kafka.WithInitialTopics(kafka.TopicSpec{Name: "replicated-topic", ReplicationFactor: 3}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := cluster.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate cluster: %s", err)
+		}
+	})
+
+	// the topic is created once, when all the brokers are ready
+	for _, broker := range cluster.Containers {
+		topics, err := broker.ListTopics(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(topics) != 1 || topics[0] != "replicated-topic" {
+			t.Fatalf("expected the initial topic, got %v", topics)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/IBM/sarama"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// DescribeConfig returns the effective configuration of the broker, mapping the name of each config entry
+// to its value, so a test can assert that the values set with WithConfig took effect in the running broker.
+// It includes the static entries, e.g. set in the server properties, the dynamic ones, altered at runtime,
+// and the defaults. The values of the sensitive entries, e.g. the passwords, are not returned by the broker,
+// so they are empty. Use DescribeConfigEntries to know the source of each entry.
+func (kc *KafkaContainer) DescribeConfig(ctx context.Context) (map[string]string, error) {
+	entries, err := kc.DescribeConfigEntries(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		values[entry.Name] = entry.Value
+	}
+
+	return values, nil
+}
+
+// DescribeConfigEntries returns the config entries of the broker sorted by name, with the source of each entry,
+// e.g. sarama.SourceStaticBroker for an entry of the server properties, sarama.SourceDynamicBroker for an entry
+// altered at runtime for the broker, or sarama.SourceDefault for an entry left to its default value.
+// It sends a DescribeConfigs request with the admin client returned by AdminClient.
+func (kc *KafkaContainer) DescribeConfigEntries(ctx context.Context) ([]sarama.ConfigEntry, error) {
+	nodeID, err := containerNodeID(ctx, kc.Container)
+	if err != nil {
+		return nil, err
+	}
+
+	admin, err := kc.AdminClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer admin.Close()
+
+	type result struct {
+		entries []sarama.ConfigEntry
+		err     error
+	}
+
+	// the request does not take a context, so the result is buffered in case it's abandoned
+	results := make(chan result, 1)
+	go func() {
+		entries, err := admin.DescribeConfig(sarama.ConfigResource{Type: sarama.BrokerResource, Name: nodeID})
+		results <- result{entries: entries, err: err}
+	}()
+
+	select {
+	case r := <-results:
+		if r.err != nil {
+			return nil, fmt.Errorf("describe config of broker %s: %w", nodeID, r.err)
+		}
+
+		sort.Slice(r.entries, func(i, j int) bool {
+			return r.entries[i].Name < r.entries[j].Name
+		})
+
+		return r.entries, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// containerNodeID returns the node id the broker was started with, which is set by WithNodeID or by RunCluster
+// for each of its brokers, and defaults to 1.
+func containerNodeID(ctx context.Context, c testcontainers.Container) (string, error) {
+	inspect, err := c.Inspect(ctx)
+	if err != nil {
+		return "", fmt.Errorf("inspect container: %w", err)
+	}
+
+	// the broker id is the only one set in ZooKeeper mode
+	for _, prefix := range []string{"KAFKA_NODE_ID=", "KAFKA_BROKER_ID="} {
+		for _, env := range inspect.Config.Env {
+			if value, ok := strings.CutPrefix(env, prefix); ok && value != "" {
+				return value, nil
+			}
+		}
+	}
+
+	return "1", nil
+}
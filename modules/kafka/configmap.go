@@ -0,0 +1,68 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// BootstrapServers returns the brokers returned by Brokers, joined with commas, as expected by the
+// bootstrap.servers property of the clients based on librdkafka, like confluent-kafka-go.
+func (kc *KafkaContainer) BootstrapServers(ctx context.Context) (string, error) {
+	brokers, err := kc.Brokers(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.Join(brokers, ","), nil
+}
+
+// ConfigMap returns the properties of the clients based on librdkafka, like the kafka.ConfigMap of
+// confluent-kafka-go, connecting to the container. It's the counterpart of SaramaConfig: if SASL was
+// enabled, the properties authenticate with the first user in alphabetical order, if any, on the SASL listener,
+// and if TLS was enabled, they trust the CA of the broker on the SSL listener. As those are different
+// listeners, an error is returned if both SASL and TLS are enabled.
+func (kc *KafkaContainer) ConfigMap(ctx context.Context) (map[string]string, error) {
+	if kc.opts.SASL != nil && kc.opts.TLS != nil {
+		return nil, errors.New("both sasl and tls are enabled, on different listeners")
+	}
+
+	servers, err := kc.clientBrokers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	config := map[string]string{
+		"bootstrap.servers": strings.Join(servers, ","),
+	}
+
+	if kc.opts.SASL != nil {
+		config["security.protocol"] = "SASL_PLAINTEXT"
+		config["sasl.mechanisms"] = kc.opts.SASL.Mechanism
+
+		if usernames := sortedUsernames(kc.opts.SASL.Users); len(usernames) > 0 {
+			config["sasl.username"] = usernames[0]
+			config["sasl.password"] = kc.opts.SASL.Users[usernames[0]]
+		}
+	}
+
+	if kc.opts.TLS != nil {
+		config["security.protocol"] = "SSL"
+		config["ssl.ca.pem"] = string(trustedCA(*kc.opts.TLS))
+	}
+
+	return config, nil
+}
+
+// clientBrokers returns the brokers of the listener the clients configured by SaramaConfig and ConfigMap
+// connect to, i.e. the SASL listener if SASL was enabled, or the SSL listener if TLS was enabled.
+func (kc *KafkaContainer) clientBrokers(ctx context.Context) ([]string, error) {
+	switch {
+	case kc.opts.SASL != nil:
+		return kc.SASLBrokers(ctx)
+	case kc.opts.TLS != nil:
+		return kc.TLSBrokers(ctx)
+	default:
+		return kc.Brokers(ctx)
+	}
+}
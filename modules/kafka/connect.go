@@ -0,0 +1,126 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/docker/go-connections/nat"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const (
+	// connectPort is the port of the Kafka Connect REST API
+	connectPort = nat.Port("8083/tcp")
+
+	// defaultConnectImage is the Kafka Connect image used when the version
+	// of the broker image cannot be matched
+	defaultConnectImage = "confluentinc/cp-kafka-connect:7.5.0"
+
+	// connectStartupTimeout is the time the Connect worker has to serve its REST API,
+	// including the installation of the plugins from Confluent Hub
+	connectStartupTimeout = 3 * time.Minute
+)
+
+// ErrConnectNotEnabled is returned when the Kafka Connect URL is requested but Kafka Connect was not enabled
+var ErrConnectNotEnabled = errors.New("kafka connect not enabled")
+
+// connectPluginRegex matches the Confluent Hub coordinates of a plugin, i.e. owner/name:version
+var connectPluginRegex = regexp.MustCompile(`^[\w.-]+/[\w.-]+:[\w.-]+$`)
+
+// validateConnectPlugins validates that the plugins are Confluent Hub coordinates, as they are
+// installed by the command of the Connect worker.
+func validateConnectPlugins(settings options) error {
+	for _, plugin := range settings.ConnectPlugins {
+		if !connectPluginRegex.MatchString(plugin) {
+			return fmt.Errorf("invalid plugin %q, expected owner/name:version", plugin)
+		}
+	}
+
+	return nil
+}
+
+// connectImage returns the Kafka Connect image matching the version of the
+// confluent-local broker image, or the default one for the rest of the images.
+func connectImage(brokerImage string) string {
+	repository, tag := splitImage(brokerImage)
+	if tag == "" || !strings.HasSuffix(repository, "confluentinc/confluent-local") {
+		return defaultConnectImage
+	}
+
+	return "confluentinc/cp-kafka-connect:" + tag
+}
+
+// connectCmd returns the command of the Connect worker, installing the plugins from Confluent Hub
+// before running the worker.
+func connectCmd(plugins []string) []string {
+	var script strings.Builder
+	for _, plugin := range plugins {
+		script.WriteString("confluent-hub install --no-prompt " + plugin + " && ")
+	}
+	script.WriteString("exec /etc/confluent/docker/run")
+
+	return []string{"bash", "-c", script.String()}
+}
+
+// runConnect starts the Kafka Connect worker in the given network, pointed at the broker through
+// the given listener, and waits for its REST API to serve the connectors.
+func runConnect(ctx context.Context, image string, networkName string, listener KafkaListener, plugins []string) (testcontainers.Container, error) {
+	req := testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        image,
+			ExposedPorts: []string{string(connectPort)},
+			Networks:     []string{networkName},
+			Cmd:          connectCmd(plugins),
+			Env: map[string]string{
+				"CONNECT_BOOTSTRAP_SERVERS":                 fmt.Sprintf("PLAINTEXT://%s:%s", listener.Ip, listener.Port),
+				"CONNECT_REST_PORT":                         connectPort.Port(),
+				"CONNECT_REST_ADVERTISED_HOST_NAME":         "connect",
+				"CONNECT_GROUP_ID":                          "testcontainers-connect",
+				"CONNECT_CONFIG_STORAGE_TOPIC":              "testcontainers-connect-configs",
+				"CONNECT_OFFSET_STORAGE_TOPIC":              "testcontainers-connect-offsets",
+				"CONNECT_STATUS_STORAGE_TOPIC":              "testcontainers-connect-status",
+				"CONNECT_CONFIG_STORAGE_REPLICATION_FACTOR": "1",
+				"CONNECT_OFFSET_STORAGE_REPLICATION_FACTOR": "1",
+				"CONNECT_STATUS_STORAGE_REPLICATION_FACTOR": "1",
+				"CONNECT_KEY_CONVERTER":                     "org.apache.kafka.connect.json.JsonConverter",
+				"CONNECT_VALUE_CONVERTER":                   "org.apache.kafka.connect.json.JsonConverter",
+				"CONNECT_PLUGIN_PATH":                       "/usr/share/java,/usr/share/confluent-hub-components",
+			},
+			WaitingFor: wait.ForHTTP("/connectors").WithPort(connectPort).WithStartupTimeout(connectStartupTimeout),
+		},
+		Started: true,
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, req)
+	if err != nil {
+		return container, fmt.Errorf("kafka connect: %w", err)
+	}
+
+	return container, nil
+}
+
+// ConnectURL returns the URL of the Kafka Connect REST API, defined by the exposed Kafka Connect port.
+// It returns ErrConnectNotEnabled if Kafka Connect was not enabled for the container.
+func (kc *KafkaContainer) ConnectURL(ctx context.Context) (string, error) {
+	if kc.connect == nil {
+		return "", ErrConnectNotEnabled
+	}
+
+	host, err := kc.connect.Host(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	port, err := kc.connect.MappedPort(ctx, connectPort)
+	if err != nil {
+		return "", err
+	}
+
+	return "http://" + hostPort(host, port.Port()), nil
+}
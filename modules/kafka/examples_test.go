@@ -34,7 +34,7 @@ func ExampleRunContainer() {
 		log.Fatalf("failed to get container state: %s", err) // nolint:gocritic
 	}
 
-	fmt.Println(kafkaContainer.ClusterID)
+	fmt.Println(kafkaContainer.ClusterLabel)
 	fmt.Println(state.Running)
 
 	// Output:
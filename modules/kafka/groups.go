@@ -0,0 +1,85 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+
+	tcexec "github.com/testcontainers/testcontainers-go/exec"
+)
+
+// ErrConsumerGroupActive is returned by ResetConsumerGroupOffsets when consumers are connected to the group,
+// as the offsets of an active group cannot be reset.
+var ErrConsumerGroupActive = errors.New("consumer group is active")
+
+// ResetConsumerGroupOffsets resets the committed offsets of the consumer group on all the partitions of the
+// topic, to the earliest offsets if toEarliest is true, and to the latest ones otherwise, so a test re-running
+// against a reused broker can consume the topic again with the same group. It runs kafka-consumer-groups
+// --reset-offsets in the container, connecting to the first internal listener, and like the command, it
+// fails with ErrConsumerGroupActive if consumers are connected to the group.
+func (kc *KafkaContainer) ResetConsumerGroupOffsets(ctx context.Context, group, topic string, toEarliest bool) error {
+	if group == "" || topic == "" {
+		return errors.New("empty group or topic")
+	}
+
+	listeners, err := kc.Listeners(ctx)
+	if err != nil {
+		return err
+	}
+
+	// the first listener is an internal one, bound to all the interfaces of the container
+	bootstrap := net.JoinHostPort("localhost", listeners[0].Port)
+
+	to := "--to-latest"
+	if toEarliest {
+		to = "--to-earliest"
+	}
+
+	cmd := []string{
+		consumerGroupsTool(detectImageFlavor(kc.image)),
+		"--bootstrap-server", bootstrap,
+		"--group", group,
+		"--topic", topic,
+		"--reset-offsets", to, "--execute",
+	}
+
+	code, r, err := kc.Exec(ctx, cmd, tcexec.Multiplexed())
+	if err != nil {
+		return fmt.Errorf("exec kafka-consumer-groups: %w", err)
+	}
+
+	output, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("read kafka-consumer-groups output: %w", err)
+	}
+
+	return resetOffsetsError(code, string(output))
+}
+
+// consumerGroupsTool returns the kafka-consumer-groups command of the image flavor.
+func consumerGroupsTool(flavor imageFlavor) string {
+	if flavor == flavorApache {
+		return "/opt/kafka/bin/kafka-consumer-groups.sh"
+	}
+
+	return "kafka-consumer-groups"
+}
+
+// resetOffsetsError returns the error of a kafka-consumer-groups --reset-offsets run, given its exit code and
+// output. The command exits with 0 when the group is active, only printing the error, so the output is checked too.
+func resetOffsetsError(code int, output string) error {
+	output = strings.TrimSpace(output)
+
+	if strings.Contains(output, "can only be reset if the group") {
+		return fmt.Errorf("%w: %s", ErrConsumerGroupActive, output)
+	}
+
+	if code != 0 || strings.Contains(output, "Error:") {
+		return fmt.Errorf("reset offsets, exit code %d: %s", code, output)
+	}
+
+	return nil
+}
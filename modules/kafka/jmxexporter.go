@@ -0,0 +1,172 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/docker/go-connections/nat"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+const (
+	// jmxExporterPort is the port of the HTTP endpoint of the Prometheus JMX exporter, only exposed
+	// when the exporter is enabled. It's the default port of the exporter.
+	jmxExporterPort = nat.Port("9404/tcp")
+
+	// jmxExporterVersion is the version of the jmx_prometheus_javaagent downloaded from Maven Central
+	jmxExporterVersion = "1.0.1"
+
+	// jmxExporterAgentFile is the location of the jmx_prometheus_javaagent in the container
+	jmxExporterAgentFile = "/usr/share/java/testcontainers/jmx_prometheus_javaagent.jar"
+	// jmxExporterConfigFile is the location of the config provided with WithJMXExporter
+	jmxExporterConfigFile = "/etc/kafka/testcontainers.jmx-exporter.yml"
+)
+
+// ErrJMXExporterNotEnabled is returned when the metrics URL is requested but the JMX exporter was not enabled
+var ErrJMXExporterNotEnabled = errors.New("jmx exporter not enabled")
+
+// jmxExporterAgentURL is the URL the jmx_prometheus_javaagent is downloaded from. It's a variable so the
+// tests can serve the agent.
+var jmxExporterAgentURL = "https://repo1.maven.org/maven2/io/prometheus/jmx/jmx_prometheus_javaagent/" +
+	jmxExporterVersion + "/jmx_prometheus_javaagent-" + jmxExporterVersion + ".jar"
+
+// jmxExporterAgentCache holds the jmx_prometheus_javaagent once downloaded, so it's downloaded once
+// per test binary, however many containers enable the exporter.
+var jmxExporterAgentCache struct {
+	sync.Mutex
+	content []byte
+}
+
+// jmxExporterConfig represents the config of the Prometheus JMX exporter, in YAML.
+type jmxExporterConfig struct {
+	YAML string
+}
+
+// WithJMXExporter enables the Prometheus JMX exporter of the broker, with the given YAML config,
+// e.g. "rules:\n- pattern: \".*\"\n" to export all the MBeans. The jmx_prometheus_javaagent is
+// downloaded from Maven Central and copied into the container with the config, and the javaagent
+// flag is appended to the KAFKA_OPTS of the broker. The metrics are served over HTTP on a dedicated
+// port, which cannot be used by the listeners nor by the JMX agent. Use the MetricsURL method to get
+// the URL of the metrics endpoint.
+func WithJMXExporter(configYAML string) Option {
+	return func(o *options) {
+		o.JMXExporter = &jmxExporterConfig{YAML: configYAML}
+	}
+}
+
+// validateJMXExporter validates that the config of the JMX exporter, if enabled, is not empty,
+// and that the port of the exporter is not used by any of the listeners nor the JMX agent.
+func validateJMXExporter(settings options) error {
+	if settings.JMXExporter == nil {
+		return nil
+	}
+
+	if strings.TrimSpace(settings.JMXExporter.YAML) == "" {
+		return errors.New("empty config")
+	}
+
+	port := jmxExporterPort.Port()
+
+	if port == settings.brokerPort() {
+		return fmt.Errorf("port %s is used by the internal listener", port)
+	}
+
+	if port == strconv.Itoa(settings.JMXPort) {
+		return fmt.Errorf("port %s is used by the jmx agent", port)
+	}
+
+	for _, item := range settings.Listeners {
+		if item.Port == port {
+			return fmt.Errorf("port %s is used by the listener %s", port, item.Name)
+		}
+	}
+
+	return nil
+}
+
+// jmxExporterHook returns the line of the starter script appending the javaagent flag to the KAFKA_OPTS
+// of the broker. It's exported by the starter script rather than set in the environment of the container,
+// as the command line tools run in the container would start the agent too, failing to bind its port.
+func jmxExporterHook() string {
+	flag := fmt.Sprintf("-javaagent:%s=%s:%s", jmxExporterAgentFile, jmxExporterPort.Port(), jmxExporterConfigFile)
+
+	return `export KAFKA_OPTS="${KAFKA_OPTS:+$KAFKA_OPTS }` + flag + `"`
+}
+
+// copyJMXExporter returns a hook copying the jmx_prometheus_javaagent and its config into the container.
+func copyJMXExporter(config jmxExporterConfig) testcontainers.ContainerHook {
+	return func(ctx context.Context, c testcontainers.Container) error {
+		agent, err := jmxExporterAgent(ctx)
+		if err != nil {
+			return fmt.Errorf("download jmx exporter agent: %w", err)
+		}
+
+		if err := c.CopyToContainer(ctx, agent, jmxExporterAgentFile, 0o644); err != nil {
+			return fmt.Errorf("copy jmx exporter agent: %w", err)
+		}
+
+		return c.CopyToContainer(ctx, []byte(config.YAML), jmxExporterConfigFile, 0o644)
+	}
+}
+
+// jmxExporterAgent returns the content of the jmx_prometheus_javaagent, downloading it on the first call.
+func jmxExporterAgent(ctx context.Context) ([]byte, error) {
+	jmxExporterAgentCache.Lock()
+	defer jmxExporterAgentCache.Unlock()
+
+	if jmxExporterAgentCache.content != nil {
+		return jmxExporterAgentCache.content, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, jmxExporterAgentURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("get %s: unexpected status %s", jmxExporterAgentURL, resp.Status)
+	}
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", jmxExporterAgentURL, err)
+	}
+
+	jmxExporterAgentCache.content = content
+
+	return content, nil
+}
+
+// MetricsURL returns the URL of the metrics endpoint of the Prometheus JMX exporter, e.g.
+// "http://localhost:32768/metrics", to scrape the broker metrics in the Prometheus text format.
+// It returns ErrJMXExporterNotEnabled if the JMX exporter was not enabled for the container.
+func (kc *KafkaContainer) MetricsURL(ctx context.Context) (string, error) {
+	if kc.opts.JMXExporter == nil {
+		return "", ErrJMXExporterNotEnabled
+	}
+
+	host, err := kc.Host(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	port, err := kc.MappedPort(ctx, jmxExporterPort)
+	if err != nil {
+		return "", err
+	}
+
+	return "http://" + hostPort(host, port.Port()) + "/metrics", nil
+}
@@ -1,19 +1,101 @@
 package kafka
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
 	"fmt"
 	"math"
+	"net"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/docker/go-connections/nat"
-	"golang.org/x/mod/semver"
 
 	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/internal/kafkalistener"
 	"github.com/testcontainers/testcontainers-go/wait"
 )
 
 const publicPort = nat.Port("9093/tcp")
+
+const (
+	// controllerPort is the port of the controller listener, only exposed when requested
+	controllerPort = nat.Port("9094/tcp")
+
+	// saslPort is the port of the SASL listener, only exposed when SASL is enabled
+	saslPort         = nat.Port("9095/tcp")
+	saslListenerName = "SASL"
+
+	// restProxyPort is the port of the REST proxy, exposed when it's enabled or bundled in the image
+	restProxyPort = nat.Port("8082/tcp")
+
+	// tlsPort is the port of the SSL listener, only exposed when TLS is enabled
+	tlsPort         = nat.Port("9096/tcp")
+	tlsListenerName = "TLS"
+
+	// secretsDir is the directory where the confluent images expect the credentials
+	secretsDir        = "/etc/kafka/secrets"
+	tlsKeystoreFile   = secretsDir + "/testcontainers.keystore.pem"
+	tlsTruststoreFile = secretsDir + "/testcontainers.truststore.pem"
+	// jaasConfigFile is the location of the JAAS file provided with WithJAASConfigFile
+	jaasConfigFile = secretsDir + "/testcontainers_jaas.conf"
+
+	// dataDir is the data directory of the broker, where the WithDataVolume volume is mounted
+	dataDir = "/var/lib/kafka/data"
+
+	// serverPropertiesFile is the location of the server.properties file provided with
+	// WithServerPropertiesFile, appended to the broker config generated from the environment
+	serverPropertiesFile = "/etc/kafka/testcontainers.server.properties"
+
+	saslMechanismPlain    = "PLAIN"
+	saslMechanismScram256 = "SCRAM-SHA-256"
+	saslMechanismScram512 = "SCRAM-SHA-512"
+)
+
+// reservedConfigEnvs are the environment variables managed by the module, which
+// cannot be overridden with the WithConfig or WithEnv options.
+var reservedConfigEnvs = map[string]bool{
+	"KAFKA_LISTENERS":                      true,
+	"KAFKA_ADVERTISED_LISTENERS":           true,
+	"KAFKA_LISTENER_SECURITY_PROTOCOL_MAP": true,
+	"KAFKA_INTER_BROKER_LISTENER_NAME":     true,
+	"KAFKA_CONTROLLER_LISTENER_NAMES":      true,
+	"KAFKA_PROCESS_ROLES":                  true,
+	"KAFKA_NODE_ID":                        true,
+	"KAFKA_CLUSTER_ID":                     true,
+}
+
+// ErrSASLNotEnabled is returned when the SASL listener is requested but SASL was not enabled
+var ErrSASLNotEnabled = errors.New("sasl not enabled")
+
+// ErrTLSNotEnabled is returned when the SSL listener is requested but TLS was not enabled
+var ErrTLSNotEnabled = errors.New("tls not enabled")
+
+// reservedPorts are the ports of the listeners and services of the module, which cannot be used
+// by the default internal listener nor by the JMX agent.
+var reservedPorts = []nat.Port{publicPort, controllerPort, saslPort, tlsPort, restProxyPort}
+
+// ErrJMXNotEnabled is returned when the JMX endpoint is requested but JMX was not enabled
+var ErrJMXNotEnabled = errors.New("jmx not enabled")
+
+// ErrControllerNotExposed is returned when the controller endpoint is requested but the controller
+// listener was not exposed
+var ErrControllerNotExposed = errors.New("controller not exposed")
+
+// ErrRestProxyNotEnabled is returned when the REST proxy URL is requested but the REST proxy was not enabled
+var ErrRestProxyNotEnabled = errors.New("rest proxy not enabled")
+
 const (
 	starterScript = "/usr/sbin/testcontainers_start.sh"
 
@@ -23,28 +105,262 @@ source /etc/confluent/docker/bash-config
 export KAFKA_ADVERTISED_LISTENERS=%s
 echo Starting Kafka KRaft mode
 sed -i '/KAFKA_ZOOKEEPER_CONNECT/d' /etc/confluent/docker/configure
-echo 'kafka-storage format --ignore-formatted -t "$(kafka-storage random-uuid)" -c /etc/kafka/kafka.properties' >> /etc/confluent/docker/configure
+echo 'if [ -f /etc/kafka/testcontainers.server.properties ]; then cat /etc/kafka/testcontainers.server.properties >> /etc/kafka/kafka.properties; fi' >> /etc/confluent/docker/configure
+echo 'kafka-storage format --ignore-formatted -t "%s" -c /etc/kafka/kafka.properties%s' >> /etc/confluent/docker/configure
 echo '' > /etc/confluent/docker/ensure
 /etc/confluent/docker/configure
-/etc/confluent/docker/launch`
+exec /etc/confluent/docker/launch`
+	// }
+
+	// apacheStarterScript {
+	apacheStarterScriptContent = `#!/bin/bash
+export KAFKA_ADVERTISED_LISTENERS=%s
+export CLUSTER_ID="%s"
+echo Starting Kafka KRaft mode
+. /etc/kafka/docker/bash-config
+. /etc/kafka/docker/configureDefaults
+. /etc/kafka/docker/configure
+if [ -f /etc/kafka/testcontainers.server.properties ]; then cat /etc/kafka/testcontainers.server.properties >> /opt/kafka/config/server.properties; fi
+/opt/kafka/bin/kafka-storage.sh format --ignore-formatted -t "$CLUSTER_ID" -c /opt/kafka/config/server.properties%s
+. /etc/kafka/docker/launch`
 	// }
 )
 
+// imageFlavor represents the family of the Kafka image, as each one ships
+// different scripts and config locations.
+type imageFlavor int
+
+const (
+	// flavorConfluent is the flavor of the confluentinc/confluent-local images, used by default
+	flavorConfluent imageFlavor = iota
+	// flavorApache is the flavor of the official apache/kafka images
+	flavorApache
+)
+
+// detectImageFlavor returns the flavor of the image, checking the repository name
+// regardless of the registry it's pulled from. Unknown images are considered confluent ones.
+func detectImageFlavor(fqName string) imageFlavor {
+	repository, _ := splitImage(fqName)
+	if strings.HasSuffix(repository, "apache/kafka") {
+		return flavorApache
+	}
+
+	return flavorConfluent
+}
+
+// splitImage returns the lowercased repository and the tag of the image, which is empty
+// if the image is not tagged. A port in the registry host is not considered a tag.
+func splitImage(fqName string) (string, string) {
+	repository, tag := fqName, ""
+	if idx := strings.LastIndex(fqName, ":"); idx > strings.LastIndex(fqName, "/") {
+		repository, tag = fqName[:idx], fqName[idx+1:]
+	}
+
+	return strings.ToLower(repository), tag
+}
+
+// starterScript returns the content of the starter script for the flavor, advertising the
+// given listeners and formatting the storage with the given cluster id, passing the extra
+// arguments to the command formatting it. The hook lines are run right before the broker is launched.
+func (f imageFlavor) starterScript(advertisedListeners string, clusterID string, storageFormatArgs string, hook []string) string {
+	content := fmt.Sprintf(starterScriptContent, advertisedListeners, clusterID, storageFormatArgs)
+	if f == flavorApache {
+		content = fmt.Sprintf(apacheStarterScriptContent, advertisedListeners, clusterID, storageFormatArgs)
+	}
+
+	return withStartupScriptHook(content, hook)
+}
+
+// withStartupScriptHook inserts the hook lines into the content of the starter script, right before
+// its last line, which launches the broker.
+func withStartupScriptHook(content string, hook []string) string {
+	if len(hook) == 0 {
+		return content
+	}
+
+	idx := strings.LastIndex(content, "\n") + 1
+
+	return content[:idx] + strings.Join(hook, "\n") + "\n" + content[idx:]
+}
+
+// execRegex matches the exec builtin used as a command, which would replace the process of the script
+var execRegex = regexp.MustCompile(`(^|[;&|(\s])exec(\s|$)`)
+
+// validateStartupScriptHook validates that the hook lines do not replace the process of the starter
+// script with exec, as the broker would not be launched then.
+func validateStartupScriptHook(settings options) error {
+	for i, line := range settings.StartupScriptHook {
+		if execRegex.MatchString(line) {
+			return fmt.Errorf("line %d: exec would replace the broker process: %s", i, line)
+		}
+	}
+
+	return nil
+}
+
 // KafkaContainer represents the Kafka container type used in the module
 type KafkaContainer struct {
 	testcontainers.Container
+	// ClusterID is the KRaft cluster id used by the broker, i.e. a base64 encoded UUID.
+	// It's empty in ZooKeeper mode, where the cluster id is generated by ZooKeeper.
 	ClusterID string
-	Listeners KafkaListener
+	// ClusterLabel is the human label passed to WithClusterID, from which the cluster id is derived.
+	// It's empty if a valid cluster id was passed.
+	ClusterLabel string
+	opts         options
+	image        string
+	// startupScript is the starter script generated for the broker
+	startupScript string
+	// logDir is the data directory of the broker
+	logDir string
+	// brokers caches the broker connection strings of the listeners mapped to the host, until the container is stopped
+	brokers brokersCache
+
+	// schemaRegistry is the Schema Registry container, if enabled
+	schemaRegistry testcontainers.Container
+	// connect is the Kafka Connect worker container, if enabled
+	connect testcontainers.Container
+	// zookeeper is the ZooKeeper container, if the broker runs in ZooKeeper mode
+	zookeeper testcontainers.Container
+	// network is the network created for the Schema Registry, Kafka Connect or ZooKeeper, if any
+	network *testcontainers.DockerNetwork
 }
 
 type KafkaListener struct {
 	Name string
 	Ip   string
 	Port string
+	// AdvertisedHost is the host advertised to the clients for the listener, when it differs from Ip,
+	// e.g. to reach the broker from another network. It defaults to Ip when empty.
+	AdvertisedHost string
+	// Protocol is the security protocol of the listener: PLAINTEXT, SASL_PLAINTEXT, SSL or SASL_SSL.
+	// It defaults to PLAINTEXT when empty. The configuration of the protocol, e.g. the keystore of an SSL
+	// listener or the JAAS config of a SASL one, is left to the caller, e.g. with WithConfig and WithFiles.
+	// The ListenerInfo.Protocol field of the listeners returned by the Listeners method reports the protocol in use.
+	Protocol string
+}
+
+// ListenerInfo describes a listener advertised by the broker, as returned by the Listeners method of the container.
+type ListenerInfo struct {
+	Name string
+	Ip   string
+	Port string
+	// AdvertisedHost is the host advertised to the clients for the listener, when it differs from Ip.
+	AdvertisedHost string
+	// Protocol is the security protocol of the listener, e.g. PLAINTEXT, SASL_PLAINTEXT or SSL.
+	Protocol string
+	// Internal is true if the listener is only reachable from the container network.
+	Internal bool
+	// HostPort is the port mapped to the host for the listeners reachable from the host, and empty otherwise.
+	HostPort string
+}
+
+// listenerProtocols are the security protocols supported by the custom listeners
+var listenerProtocols = []string{"PLAINTEXT", "SASL_PLAINTEXT", "SSL", "SASL_SSL"}
+
+// protocol returns the security protocol of the listener, defaulting to PLAINTEXT.
+func (l KafkaListener) protocol() string {
+	if l.Protocol == "" {
+		return "PLAINTEXT"
+	}
+
+	return l.Protocol
+}
+
+// containerRequest is the request of a broker, computed from the options, along with the state
+// needed by RunContainer to return the KafkaContainer once the broker is started.
+type containerRequest struct {
+	testcontainers.GenericContainerRequest
+	settings     options
+	clusterID    string
+	clusterLabel string
+	// startupScript is the starter script generated by the first post start hook
+	startupScript string
+	// logDir is the data directory of the broker
+	logDir string
 }
 
 // RunContainer creates an instance of the Kafka container type
 func RunContainer(ctx context.Context, opts ...testcontainers.ContainerCustomizer) (*KafkaContainer, error) {
+	r, err := newContainerRequest(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	genericContainerReq, settings := &r.GenericContainerRequest, r.settings
+
+	kc := &KafkaContainer{ClusterID: r.clusterID, ClusterLabel: r.clusterLabel, opts: settings, image: genericContainerReq.Image, logDir: r.logDir}
+
+	// fail releases the network and the containers started so far, so none of them is leaked on error
+	fail := func(err error) (*KafkaContainer, error) {
+		return nil, errors.Join(err, kc.Terminate(ctx))
+	}
+
+	if settings.SchemaRegistry || settings.Connect || settings.Zookeeper {
+		kc.network, err = attachSchemaRegistryNetwork(ctx, genericContainerReq)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// the broker in ZooKeeper mode connects to ZooKeeper as soon as it starts
+	if settings.Zookeeper {
+		kc.zookeeper, err = runZookeeper(ctx, zookeeperImage(genericContainerReq.Image), genericContainerReq.Networks[0])
+		if err != nil {
+			return fail(err)
+		}
+	} else {
+		configureControllerQuorumVoters(genericContainerReq)
+	}
+
+	kc.Container, err = testcontainers.GenericContainer(ctx, *genericContainerReq)
+	if err != nil {
+		return fail(err)
+	}
+
+	// the starter script is generated by the post start hook of the broker
+	kc.startupScript = r.startupScript
+
+	// a reused container keeps the cluster id it was started with
+	if settings.ReuseName != "" {
+		id, err := containerClusterID(ctx, kc.Container)
+		if err != nil {
+			return fail(err)
+		}
+
+		if id != r.clusterID {
+			kc.ClusterID, kc.ClusterLabel = id, ""
+		}
+
+		// the hooks are not run again for a running container, so its starter script is read back
+		if kc.startupScript == "" {
+			kc.startupScript, err = containerStartupScript(ctx, kc.Container)
+			if err != nil {
+				return fail(err)
+			}
+		}
+	}
+
+	if settings.SchemaRegistry {
+		kc.schemaRegistry, err = runSchemaRegistry(ctx, schemaRegistryImage(kc.image), genericContainerReq.Networks[0], settings.Listeners[0])
+		if err != nil {
+			return fail(err)
+		}
+	}
+
+	if settings.Connect {
+		kc.connect, err = runConnect(ctx, connectImage(kc.image), genericContainerReq.Networks[0], settings.Listeners[0], settings.ConnectPlugins)
+		if err != nil {
+			return fail(err)
+		}
+	}
+
+	return kc, nil
+}
+
+// newContainerRequest validates the options and computes the request of the broker, without
+// the network of the Schema Registry and Kafka Connect, which is created by RunContainer.
+func newContainerRequest(opts ...testcontainers.ContainerCustomizer) (*containerRequest, error) {
 	req := testcontainers.ContainerRequest{
 		Image:        "confluentinc/confluent-local:7.5.0",
 		ExposedPorts: []string{string(publicPort)},
@@ -68,7 +384,7 @@ func RunContainer(ctx context.Context, opts ...testcontainers.ContainerCustomize
 		},
 		Entrypoint: []string{"sh"},
 		// this CMD will wait for the starter script to be copied into the container and then execute it
-		Cmd: []string{"-c", "while [ ! -f " + starterScript + " ]; do sleep 0.1; done; bash " + starterScript},
+		Cmd: []string{"-c", "while [ ! -f " + starterScript + " ]; do sleep 0.1; done; exec bash " + starterScript},
 	}
 
 	genericContainerReq := testcontainers.GenericContainerRequest{
@@ -86,208 +402,1593 @@ func RunContainer(ctx context.Context, opts ...testcontainers.ContainerCustomize
 		}
 	}
 
+	settings.podman = usesPodman(genericContainerReq.ProviderType)
+
+	if settings.BrokerCount > 1 {
+		return nil, fmt.Errorf("broker count %d not supported by RunContainer, use RunCluster instead", settings.BrokerCount)
+	}
+
 	if err := trimValidateListeners(settings.Listeners); err != nil {
 		return nil, fmt.Errorf("listeners validation: %w", err)
 	}
 
-	// apply envs for listeners
-	envChange := editEnvsForListeners(settings.Listeners)
-	for key, item := range envChange {
-		genericContainerReq.Env[key] = item
+	if err := validateSASL(settings); err != nil {
+		return nil, fmt.Errorf("sasl validation: %w", err)
 	}
 
-	genericContainerReq.ContainerRequest.LifecycleHooks =
-		[]testcontainers.ContainerLifecycleHooks{
-			{
-				PostStarts: []testcontainers.ContainerHook{
-					// 1. copy the starter script into the container
-					func(ctx context.Context, c testcontainers.Container) error {
-						if len(settings.Listeners) == 0 {
-							defaultInternal, err := internalListener(ctx, c)
-							if err != nil {
-								return fmt.Errorf("can't create default internal listener: %w", err)
-							}
-							settings.Listeners = append(settings.Listeners, defaultInternal)
-						}
+	if err := validateTLS(settings); err != nil {
+		return nil, fmt.Errorf("tls validation: %w", err)
+	}
 
-						defaultExternal, err := externalListener(ctx, c)
-						if err != nil {
-							return fmt.Errorf("can't create default external listener: %w", err)
-						}
+	flavor := detectImageFlavor(genericContainerReq.Image)
 
-						settings.Listeners = append(settings.Listeners, defaultExternal)
+	if err := validateInitialTopics(settings); err != nil {
+		return nil, fmt.Errorf("initial topics validation: %w", err)
+	}
 
-						var advertised []string
-						for _, item := range settings.Listeners {
-							advertised = append(advertised, fmt.Sprintf("%s://%s:%s", item.Name, item.Ip, item.Port))
-						}
+	if err := validateZookeeper(settings, genericContainerReq.Image); err != nil {
+		return nil, fmt.Errorf("zookeeper validation: %w", err)
+	}
 
-						scriptContent := fmt.Sprintf(starterScriptContent, strings.Join(advertised, ","))
+	if err := validateSeedData(settings); err != nil {
+		return nil, fmt.Errorf("seed data validation: %w", err)
+	}
 
-						return c.CopyToContainer(ctx, []byte(scriptContent), starterScript, 0o755)
-					},
-					// 2. wait for the Kafka server to be ready
-					func(ctx context.Context, c testcontainers.Container) error {
-						return wait.ForLog(".*Transitioning from RECOVERY to RUNNING.*").AsRegexp().WaitUntilReady(ctx, c)
-					},
-				},
-			},
+	if err := validateConnectPlugins(settings); err != nil {
+		return nil, fmt.Errorf("connect validation: %w", err)
+	}
+
+	if settings.SchemaRegistry || settings.Connect {
+		// the Schema Registry and Kafka Connect containers connect to the first listener without any credentials
+		if len(settings.Listeners) > 0 && settings.Listeners[0].protocol() != "PLAINTEXT" {
+			return nil, fmt.Errorf("listeners validation: listener %s: the schema registry and kafka connect need a PLAINTEXT first listener", settings.Listeners[0].Name)
 		}
 
-	err := validateKRaftVersion(genericContainerReq.Image)
-	if err != nil {
-		return nil, err
+		withSchemaRegistryListener(&genericContainerReq, &settings)
 	}
 
-	clusterID := genericContainerReq.Env["CLUSTER_ID"]
+	if err := validateConfig(settings); err != nil {
+		return nil, fmt.Errorf("config validation: %w", err)
+	}
 
-	configureControllerQuorumVoters(&genericContainerReq)
+	settings.AdvertisedHost = strings.TrimSpace(settings.AdvertisedHost)
+	if err := validateAdvertisedHost(settings.AdvertisedHost); err != nil {
+		return nil, fmt.Errorf("advertised host validation: %w", err)
+	}
 
-	container, err := testcontainers.GenericContainer(ctx, genericContainerReq)
-	if err != nil {
-		return nil, err
+	if err := validateTopicDefaults(settings, max(settings.clusterSize, 1)); err != nil {
+		return nil, fmt.Errorf("topic defaults validation: %w", err)
 	}
 
-	return &KafkaContainer{Container: container, ClusterID: clusterID}, nil
-}
+	if err := validateNodeID(settings); err != nil {
+		return nil, fmt.Errorf("node id validation: %w", err)
+	}
 
-func trimValidateListeners(listeners []KafkaListener) error {
-	// Trim
-	for i := 0; i < len(listeners); i++ {
-		listeners[i].Name = strings.ToUpper(strings.Trim(listeners[i].Name, " "))
-		listeners[i].Ip = strings.Trim(listeners[i].Ip, " ")
-		listeners[i].Port = strings.Trim(listeners[i].Port, " ")
+	if err := validateBrokerPort(settings); err != nil {
+		return nil, fmt.Errorf("broker port validation: %w", err)
 	}
 
-	// Validate
-	var ports map[string]bool = make(map[string]bool, len(listeners)+2)
-	var names map[string]bool = make(map[string]bool, len(listeners)+2)
+	settings.InterBrokerListener = strings.TrimSpace(settings.InterBrokerListener)
+	if err := validateInterBrokerListener(settings); err != nil {
+		return nil, fmt.Errorf("inter-broker listener validation: %w", err)
+	}
 
-	// check for default listeners
-	ports["9094"] = true
-	ports["9093"] = true
+	if err := validateClientQuotas(settings); err != nil {
+		return nil, fmt.Errorf("client quotas validation: %w", err)
+	}
 
-	// check for default listeners
-	names["CONTROLLER"] = true
-	names["EXTERNAL"] = true
+	if err := validateCompression(settings); err != nil {
+		return nil, fmt.Errorf("compression validation: %w", err)
+	}
 
-	for _, item := range listeners {
-		if names[item.Name] {
-			return fmt.Errorf("duplicate of listener name: %s", item.Name)
-		}
-		names[item.Name] = true
+	settings.LogLevel = strings.ToUpper(strings.TrimSpace(settings.LogLevel))
+	if err := validateLogLevel(settings); err != nil {
+		return nil, fmt.Errorf("log level validation: %w", err)
+	}
 
-		if ports[item.Port] {
-			return fmt.Errorf("duplicate of listener port: %s", item.Port)
-		}
-		ports[item.Port] = true
+	if err := validateAuthorizer(settings); err != nil {
+		return nil, fmt.Errorf("authorizer validation: %w", err)
 	}
 
-	return nil
-}
+	if err := validateJMX(settings); err != nil {
+		return nil, fmt.Errorf("jmx validation: %w", err)
+	}
 
-func editEnvsForListeners(listeners []KafkaListener) map[string]string {
-	if len(listeners) == 0 {
-		// no change
-		return map[string]string{}
+	if err := validateJMXExporter(settings); err != nil {
+		return nil, fmt.Errorf("jmx exporter validation: %w", err)
 	}
 
-	envs := map[string]string{
-		"KAFKA_LISTENERS":                      "CONTROLLER://0.0.0.0:9094, EXTERNAL://0.0.0.0:9093",
-		"KAFKA_REST_BOOTSTRAP_SERVERS":         "CONTROLLER://0.0.0.0:9094, EXTERNAL://0.0.0.0:9093",
-		"KAFKA_LISTENER_SECURITY_PROTOCOL_MAP": "CONTROLLER:PLAINTEXT, EXTERNAL:PLAINTEXT",
+	if err := validateEnv(settings); err != nil {
+		return nil, fmt.Errorf("env validation: %w", err)
 	}
 
-	// expect first listener has common network between kafka instances
-	envs["KAFKA_INTER_BROKER_LISTENER_NAME"] = listeners[0].Name
+	if err := validateServerProperties(settings); err != nil {
+		return nil, fmt.Errorf("server properties validation: %w", err)
+	}
 
-	// expect small number of listeners, so joins is okay
-	for _, item := range listeners {
-		envs["KAFKA_LISTENERS"] = strings.Join(
-			[]string{
-				envs["KAFKA_LISTENERS"],
-				fmt.Sprintf("%s://0.0.0.0:%s", item.Name, item.Port),
-			},
-			",",
-		)
+	if err := validateJAASConfigFile(settings); err != nil {
+		return nil, fmt.Errorf("jaas config file validation: %w", err)
+	}
 
-		envs["KAFKA_REST_BOOTSTRAP_SERVERS"] = envs["KAFKA_LISTENERS"]
+	if err := validateStartupScriptHook(settings); err != nil {
+		return nil, fmt.Errorf("startup script hook validation: %w", err)
+	}
 
-		envs["KAFKA_LISTENER_SECURITY_PROTOCOL_MAP"] = strings.Join(
-			[]string{
-				envs["KAFKA_LISTENER_SECURITY_PROTOCOL_MAP"],
-				item.Name + ":" + "PLAINTEXT",
-			},
-			",",
-		)
+	settings.ReuseName = strings.TrimSpace(settings.ReuseName)
+	if err := validateReuse(settings); err != nil {
+		return nil, fmt.Errorf("reuse validation: %w", err)
 	}
 
-	return envs
-}
+	if err := validateName(genericContainerReq.Name, settings); err != nil {
+		return nil, fmt.Errorf("name validation: %w", err)
+	}
 
-// Brokers retrieves the broker connection strings from Kafka with only one entry,
-// defined by the exposed public port.
-func (kc *KafkaContainer) Brokers(ctx context.Context) ([]string, error) {
-	host, err := kc.Host(ctx)
-	if err != nil {
-		return nil, err
+	if err := validateFixedBrokerPort(settings); err != nil {
+		return nil, fmt.Errorf("fixed broker port validation: %w", err)
 	}
 
-	port, err := kc.MappedPort(ctx, publicPort)
-	if err != nil {
-		return nil, err
+	if err := validateFiles(settings); err != nil {
+		return nil, fmt.Errorf("files validation: %w", err)
 	}
 
-	return []string{fmt.Sprintf("%s:%d", host, port.Int())}, nil
-}
+	// apply envs for listeners
+	envChange := editEnvsForListeners(settings.Listeners)
+	for key, item := range envChange {
+		genericContainerReq.Env[key] = item
+	}
 
-// configureControllerQuorumVoters sets the quorum voters for the controller. For that, it will
-// check if there are any network aliases defined for the container and use the first alias in the
-// first network. Else, it will use localhost.
-func configureControllerQuorumVoters(req *testcontainers.GenericContainerRequest) {
-	if req.Env == nil {
-		req.Env = map[string]string{}
+	if settings.InterBrokerListener != "" {
+		genericContainerReq.Env["KAFKA_INTER_BROKER_LISTENER_NAME"] = settings.InterBrokerListener
 	}
 
-	if req.Env["KAFKA_CONTROLLER_QUORUM_VOTERS"] == "" {
-		host := "localhost"
-		if len(req.Networks) > 0 {
-			nw := req.Networks[0]
-			if len(req.NetworkAliases[nw]) > 0 {
-				host = req.NetworkAliases[nw][0]
-			}
+	if flavor == flavorApache {
+		// the REST proxy is only bundled in the confluent images
+		if settings.RestProxy {
+			return nil, fmt.Errorf("rest proxy validation: not bundled in the %s image", genericContainerReq.Image)
 		}
 
-		req.Env["KAFKA_CONTROLLER_QUORUM_VOTERS"] = fmt.Sprintf("1@%s:9094", host)
+		delete(genericContainerReq.Env, "KAFKA_REST_BOOTSTRAP_SERVERS")
 	}
-	// }
-}
 
-// validateKRaftVersion validates if the image version is compatible with KRaft mode,
-// which is available since version 7.0.0.
-func validateKRaftVersion(fqName string) error {
-	if fqName == "" {
-		return fmt.Errorf("image cannot be empty")
+	// the REST endpoint of the confluent-local images is always exposed, for KafkaRestURL
+	if settings.RestProxy || bundlesKafkaRest(genericContainerReq.Image) {
+		genericContainerReq.ExposedPorts = append(genericContainerReq.ExposedPorts, string(restProxyPort))
 	}
 
-	image := fqName[:strings.LastIndex(fqName, ":")]
-	version := fqName[strings.LastIndex(fqName, ":")+1:]
+	// apply envs for the SASL listener
+	if settings.SASL != nil {
+		for key, item := range editEnvsForSASL(genericContainerReq.Env, *settings.SASL) {
+			genericContainerReq.Env[key] = item
+		}
 
-	if !strings.EqualFold(image, "confluentinc/confluent-local") {
-		// do not validate if the image is not the official one.
-		// not raising an error here, letting the image to start and
-		// eventually evaluate an error if it exists.
-		return nil
+		// the listener JAAS config would take precedence over the JAAS file
+		if settings.JAASConfigFile != "" {
+			delete(genericContainerReq.Env, saslJAASConfigEnv(*settings.SASL))
+		}
+
+		genericContainerReq.ExposedPorts = append(genericContainerReq.ExposedPorts, string(saslPort))
 	}
 
-	// semver requires the version to start with a "v"
-	if !strings.HasPrefix(version, "v") {
-		version = fmt.Sprintf("v%s", version)
+	// apply envs and files for the SSL listener
+	if settings.TLS != nil {
+		for key, item := range editEnvsForTLS(genericContainerReq.Env) {
+			genericContainerReq.Env[key] = item
+		}
+
+		files, err := tlsFiles(*settings.TLS)
+		if err != nil {
+			return nil, err
+		}
+
+		genericContainerReq.ExposedPorts = append(genericContainerReq.ExposedPorts, string(tlsPort))
+		genericContainerReq.Files = append(genericContainerReq.Files, files...)
 	}
 
-	if semver.Compare(version, "v7.4.0") < 0 { // version < v7.4.0
-		return fmt.Errorf("version=%s. KRaft mode is only available since version 7.4.0", version)
+	// the listeners replacing the default internal one already use the broker port
+	if settings.BrokerPort != "" && len(settings.Listeners) == 0 {
+		for key, item := range brokerPortEnvs(settings.BrokerPort) {
+			genericContainerReq.Env[key] = item
+		}
 	}
 
-	return nil
+	if settings.NodeID > 0 {
+		genericContainerReq.Env["KAFKA_NODE_ID"] = strconv.Itoa(settings.NodeID)
+		genericContainerReq.Env["KAFKA_BROKER_ID"] = strconv.Itoa(settings.NodeID)
+	}
+
+	for key, item := range topicDefaultsEnvs(settings) {
+		genericContainerReq.Env[key] = item
+	}
+
+	if settings.Compression != "" {
+		genericContainerReq.Env["KAFKA_COMPRESSION_TYPE"] = settings.Compression
+	}
+
+	if settings.Transactions {
+		for key, item := range transactionEnvs(settings) {
+			genericContainerReq.Env[key] = item
+		}
+	}
+
+	if settings.IdempotenceReady {
+		genericContainerReq.Env["KAFKA_MIN_INSYNC_REPLICAS"] = "1"
+	}
+
+	if settings.LogLevel != "" {
+		for key, item := range logLevelEnvs(settings) {
+			genericContainerReq.Env[key] = item
+		}
+	}
+
+	if settings.Authorizer {
+		for key, item := range authorizerEnvs(settings.SuperUsers) {
+			genericContainerReq.Env[key] = item
+		}
+	}
+
+	// enable the JMX agent, advertising the hostname of the container
+	if settings.JMXPort > 0 {
+		if genericContainerReq.Hostname == "" {
+			genericContainerReq.Hostname = brokerNetworkAlias
+		}
+
+		for key, item := range jmxEnvs(flavor, settings.JMXPort, genericContainerReq.Hostname) {
+			genericContainerReq.Env[key] = item
+		}
+
+		genericContainerReq.ExposedPorts = append(genericContainerReq.ExposedPorts, fmt.Sprintf("%d/tcp", settings.JMXPort))
+	}
+
+	// expose the metrics endpoint of the JMX exporter, started with the broker by the starter script
+	if settings.JMXExporter != nil {
+		genericContainerReq.ExposedPorts = append(genericContainerReq.ExposedPorts, string(jmxExporterPort))
+	}
+
+	// bind the external listener to the fixed host port, the advertised listeners use the mapped port
+	if settings.FixedBrokerPort > 0 {
+		for i, port := range genericContainerReq.ExposedPorts {
+			if port == string(publicPort) {
+				genericContainerReq.ExposedPorts[i] = fmt.Sprintf("%d:%s", settings.FixedBrokerPort, publicPort)
+			}
+		}
+	}
+
+	// publish the controller listener, which is otherwise only reachable from the container network
+	if settings.ExposedController {
+		genericContainerReq.ExposedPorts = append(genericContainerReq.ExposedPorts, string(controllerPort))
+	}
+
+	// mount the data volume, storing both the topic data and the metadata log
+	if settings.DataVolume != "" {
+		genericContainerReq.Mounts = append(genericContainerReq.Mounts, testcontainers.VolumeMount(settings.DataVolume, dataDir))
+		genericContainerReq.Env["KAFKA_LOG_DIRS"] = dataDir
+		genericContainerReq.Env["KAFKA_METADATA_LOG_DIR"] = dataDir
+	}
+
+	// copy the files of the users, before the starter script is copied by the post start hook
+	genericContainerReq.Files = append(genericContainerReq.Files, settings.Files...)
+
+	// copy the JAAS file, which is passed to the JVM with the KAFKA_OPTS set below
+	if settings.JAASConfigFile != "" {
+		genericContainerReq.Files = append(genericContainerReq.Files, testcontainers.ContainerFile{
+			HostFilePath:      settings.JAASConfigFile,
+			ContainerFilePath: jaasConfigFile,
+			FileMode:          0o644,
+		})
+	}
+
+	// copy the server.properties file, which is appended to the generated broker config
+	if settings.ServerPropertiesFile != "" {
+		genericContainerReq.Files = append(genericContainerReq.Files, testcontainers.ContainerFile{
+			HostFilePath:      settings.ServerPropertiesFile,
+			ContainerFilePath: serverPropertiesFile,
+			FileMode:          0o644,
+		})
+	}
+
+	// the cluster id of a broker in ZooKeeper mode is generated by ZooKeeper
+	var clusterID, clusterLabel string
+	if settings.Zookeeper {
+		for key, item := range zookeeperEnvs(genericContainerReq.Env) {
+			genericContainerReq.Env[key] = item
+		}
+
+		for _, key := range kraftEnvs {
+			delete(genericContainerReq.Env, key)
+		}
+	} else {
+		clusterID, clusterLabel = normalizeClusterID(genericContainerReq.Env["CLUSTER_ID"])
+		genericContainerReq.Env["CLUSTER_ID"] = clusterID
+	}
+
+	// apply envs for the broker config
+	for key, item := range configEnvs(settings.Config) {
+		genericContainerReq.Env[key] = item
+	}
+
+	// apply the envs set by the users, on top of the managed ones
+	for key, item := range settings.Env {
+		genericContainerReq.Env[key] = item
+	}
+
+	// the JAAS file is added to the JVM flags set by the users, if any
+	if settings.JAASConfigFile != "" {
+		genericContainerReq.Env["KAFKA_OPTS"] = appendJVMFlag(genericContainerReq.Env["KAFKA_OPTS"], "-Djava.security.auth.login.config="+jaasConfigFile)
+	}
+
+	// the starter script generated by the first hook is kept for the StartupScript method
+	r := &containerRequest{}
+
+	genericContainerReq.ContainerRequest.LifecycleHooks =
+		[]testcontainers.ContainerLifecycleHooks{
+			{
+				PostStarts: []testcontainers.ContainerHook{
+					// 1. copy the starter script into the container
+					func(ctx context.Context, c testcontainers.Container) error {
+						listeners, err := advertisedListeners(ctx, c, settings)
+						if err != nil {
+							return err
+						}
+
+						var storageFormatArgs string
+						if settings.SASL != nil {
+							storageFormatArgs = scramCredentialsArgs(*settings.SASL)
+						}
+
+						// the JVM flags of the JMX exporter only apply to the broker
+						hook := settings.StartupScriptHook
+						if settings.JMXExporter != nil {
+							hook = append([]string{jmxExporterHook()}, hook...)
+						}
+
+						scriptContent := flavor.starterScript(advertisedListenersConfig(listeners), clusterID, storageFormatArgs, hook)
+						if settings.Zookeeper {
+							scriptContent = zookeeperStarterScript(advertisedListenersConfig(listeners), hook)
+						}
+						r.startupScript = scriptContent
+
+						return c.CopyToContainer(ctx, []byte(scriptContent), starterScript, 0o755)
+					},
+					// 2. wait for the Kafka server to be ready
+					func(ctx context.Context, c testcontainers.Container) error {
+						return wait.ForLog(readyLog(settings)).AsRegexp().WaitUntilReady(ctx, c)
+					},
+					// 3. wait for the controller quorum to serve metadata and coordinate consumer groups,
+					// probing the broker through the external listener
+					func(ctx context.Context, c testcontainers.Container) error {
+						return wait.ForKafka().
+							WithPort(publicPort).
+							WithQuorum().
+							WithPollInterval(500*time.Millisecond).
+							WaitUntilReady(ctx, c)
+					},
+				},
+			},
+		}
+
+	if settings.JMXExporter != nil {
+		// copy the JMX exporter agent and its config once the container is created, before it's started
+		genericContainerReq.LifecycleHooks[0].PostCreates = append(genericContainerReq.LifecycleHooks[0].PostCreates,
+			copyJMXExporter(*settings.JMXExporter),
+		)
+	}
+
+	if settings.RestProxy {
+		// 4. wait for the REST proxy to serve requests
+		genericContainerReq.LifecycleHooks[0].PostStarts = append(genericContainerReq.LifecycleHooks[0].PostStarts,
+			func(ctx context.Context, c testcontainers.Container) error {
+				return wait.ForHTTP("/v3/clusters").WithPort(restProxyPort).WaitUntilReady(ctx, c)
+			},
+		)
+	}
+
+	if len(settings.ClientQuotas) > 0 {
+		// 5. set the client quotas once the broker is ready
+		genericContainerReq.LifecycleHooks[0].PostStarts = append(genericContainerReq.LifecycleHooks[0].PostStarts,
+			func(ctx context.Context, c testcontainers.Container) error {
+				return configureClientQuotas(ctx, c, settings, genericContainerReq.Image)
+			},
+		)
+	}
+
+	// the brokers of a cluster leave the initial topics to RunCluster, which creates them once all the brokers are ready
+	if len(settings.InitialTopics) > 0 && settings.clusterSize == 0 {
+		// 6. create the initial topics once the broker is ready
+		genericContainerReq.LifecycleHooks[0].PostStarts = append(genericContainerReq.LifecycleHooks[0].PostStarts,
+			func(ctx context.Context, c testcontainers.Container) error {
+				return createInitialTopics(ctx, c, settings, genericContainerReq.Image)
+			},
+		)
+	}
+
+	if len(settings.SeedData) > 0 {
+		// 7. produce the seed data once the initial topics are created
+		genericContainerReq.LifecycleHooks[0].PostStarts = append(genericContainerReq.LifecycleHooks[0].PostStarts,
+			func(ctx context.Context, c testcontainers.Container) error {
+				return produceSeedData(ctx, c, settings, genericContainerReq.Image)
+			},
+		)
+	}
+
+	if settings.GracefulShutdownTimeout > 0 {
+		// the broker must complete its controlled shutdown before the container is removed
+		genericContainerReq.LifecycleHooks[0].PreTerminates = append(genericContainerReq.LifecycleHooks[0].PreTerminates,
+			gracefulShutdown(settings),
+		)
+	}
+
+	if _, err := parseKafkaVersion(genericContainerReq.Image); err != nil {
+		return nil, err
+	}
+
+	if settings.ReuseName != "" {
+		genericContainerReq.Name = settings.ReuseName
+		genericContainerReq.Reuse = true
+	}
+
+	r.GenericContainerRequest, r.settings = genericContainerReq, settings
+	r.clusterID, r.clusterLabel = clusterID, clusterLabel
+	r.logDir = brokerLogDir(genericContainerReq.Env, serverPropertiesContent(settings), genericContainerReq.Image)
+
+	return r, nil
+}
+
+// ListenerValidationReason is the reason why a listener passed to WithListener is invalid.
+type ListenerValidationReason = kafkalistener.Reason
+
+const (
+	// ReservedName is the reason of a listener named after one of the listeners of the module, i.e. CONTROLLER or EXTERNAL
+	ReservedName = kafkalistener.ReservedName
+	// ReservedPort is the reason of a listener using one of the ports reserved by the module, e.g. 9093 or 9094
+	ReservedPort = kafkalistener.ReservedPort
+	// DuplicateName is the reason of a listener named after a previous listener
+	DuplicateName = kafkalistener.DuplicateName
+	// DuplicatePort is the reason of a listener using the port of a previous listener
+	DuplicatePort = kafkalistener.DuplicatePort
+	// InvalidPort is the reason of a listener whose port is not a number between 1 and 65535
+	InvalidPort = kafkalistener.InvalidPort
+	// EmptyName is the reason of a listener without a name
+	EmptyName = kafkalistener.EmptyName
+	// EmptyHost is the reason of a listener without a host
+	EmptyHost = kafkalistener.EmptyHost
+	// ReservedHost is the reason of a listener whose host is the loopback or the wildcard address, used by the default listeners
+	ReservedHost = kafkalistener.ReservedHost
+	// WildcardAdvertisedHost is the reason of a listener advertising the wildcard address
+	WildcardAdvertisedHost ListenerValidationReason = "wildcard advertised host"
+	// UnsupportedProtocol is the reason of a listener whose security protocol is not supported
+	UnsupportedProtocol ListenerValidationReason = "unsupported protocol"
+)
+
+// ListenerValidationError is returned, wrapped, when starting a container with an invalid listener passed
+// to WithListener, so the caller can tell which listener and which of its fields is invalid, and why,
+// e.g. with errors.As. Its Field is one of Name, Ip, Port, AdvertisedHost or Protocol.
+type ListenerValidationError = kafkalistener.ValidationError
+
+// trimValidateListeners trims the listeners, uppercasing their names and protocols, and validates that their names
+// and ports are unique, not colliding with the default listeners, and that their hosts, ports and protocols are valid.
+// The first invalid listener is reported with a ListenerValidationError.
+func trimValidateListeners(listeners []KafkaListener) error {
+	// Trim
+	for i := 0; i < len(listeners); i++ {
+		l := kafkalistener.Listener{Name: listeners[i].Name, Ip: listeners[i].Ip, Port: listeners[i].Port}.Trim()
+		listeners[i].Name, listeners[i].Ip, listeners[i].Port = l.Name, l.Ip, l.Port
+		listeners[i].AdvertisedHost = strings.TrimSpace(listeners[i].AdvertisedHost)
+		listeners[i].Protocol = strings.ToUpper(strings.TrimSpace(listeners[i].Protocol))
+	}
+
+	// Validate, against the names of the default listeners and the ports reserved by the module, but the
+	// SASL and TLS ones, which are only reserved when they are enabled, by validateSASL and validateTLS
+	var ports []int
+	for _, port := range reservedPorts {
+		if port != saslPort && port != tlsPort {
+			ports = append(ports, port.Int())
+		}
+	}
+
+	validator := kafkalistener.NewValidator([]string{"CONTROLLER", "EXTERNAL"}, ports)
+
+	for i, item := range listeners {
+		if _, err := validator.Validate(i, kafkalistener.Listener{Name: item.Name, Ip: item.Ip, Port: item.Port}); err != nil {
+			return err
+		}
+
+		if ip := net.ParseIP(strings.Trim(item.AdvertisedHost, "[]")); ip != nil && ip.IsUnspecified() {
+			return kafkalistener.NewValidationError(i, item.Name, "AdvertisedHost", WildcardAdvertisedHost, "advertised host %s is the wildcard address", item.AdvertisedHost)
+		}
+
+		if !slices.Contains(listenerProtocols, item.protocol()) {
+			return kafkalistener.NewValidationError(i, item.Name, "Protocol", UnsupportedProtocol, "unsupported security protocol %q, expected one of %s", item.Protocol, strings.Join(listenerProtocols, ", "))
+		}
+	}
+
+	return nil
+}
+
+// advertisedListenersConfig returns the value of the advertised.listeners broker config for the listeners,
+// advertising the AdvertisedHost of each listener, or its Ip if not set.
+func advertisedListenersConfig(listeners []ListenerInfo) string {
+	advertised := make([]string, 0, len(listeners))
+	for _, item := range listeners {
+		host := item.AdvertisedHost
+		if host == "" {
+			host = item.Ip
+		}
+
+		advertised = append(advertised, item.Name+"://"+hostPort(host, item.Port))
+	}
+
+	return strings.Join(advertised, ",")
+}
+
+func editEnvsForListeners(listeners []KafkaListener) map[string]string {
+	if len(listeners) == 0 {
+		// no change
+		return map[string]string{}
+	}
+
+	envs := map[string]string{
+		"KAFKA_LISTENERS":                      "CONTROLLER://0.0.0.0:9094, EXTERNAL://0.0.0.0:9093",
+		"KAFKA_REST_BOOTSTRAP_SERVERS":         "CONTROLLER://0.0.0.0:9094, EXTERNAL://0.0.0.0:9093",
+		"KAFKA_LISTENER_SECURITY_PROTOCOL_MAP": "CONTROLLER:PLAINTEXT, EXTERNAL:PLAINTEXT",
+	}
+
+	// expect first listener has common network between kafka instances
+	envs["KAFKA_INTER_BROKER_LISTENER_NAME"] = listeners[0].Name
+
+	// expect small number of listeners, so joins is okay
+	for _, item := range listeners {
+		envs["KAFKA_LISTENERS"] = strings.Join(
+			[]string{
+				envs["KAFKA_LISTENERS"],
+				fmt.Sprintf("%s://0.0.0.0:%s", item.Name, item.Port),
+			},
+			",",
+		)
+
+		envs["KAFKA_REST_BOOTSTRAP_SERVERS"] = envs["KAFKA_LISTENERS"]
+
+		envs["KAFKA_LISTENER_SECURITY_PROTOCOL_MAP"] = strings.Join(
+			[]string{
+				envs["KAFKA_LISTENER_SECURITY_PROTOCOL_MAP"],
+				item.Name + ":" + item.protocol(),
+			},
+			",",
+		)
+	}
+
+	return envs
+}
+
+// validateSASL validates the SASL configuration, if any: the mechanism must be supported,
+// there must be at least one user, unless the PLAIN users are defined in the JAAS file,
+// and the SASL listener name and port must not be used by any custom listener.
+func validateSASL(settings options) error {
+	if settings.SASL == nil {
+		return nil
+	}
+
+	switch settings.SASL.Mechanism {
+	case saslMechanismPlain, saslMechanismScram256, saslMechanismScram512:
+	default:
+		return fmt.Errorf("unsupported sasl mechanism: %s. Supported mechanisms are: %s, %s, %s",
+			settings.SASL.Mechanism, saslMechanismPlain, saslMechanismScram256, saslMechanismScram512)
+	}
+
+	jaasUsers := settings.SASL.Mechanism == saslMechanismPlain && settings.JAASConfigFile != ""
+	if len(settings.SASL.Users) == 0 && !jaasUsers {
+		return fmt.Errorf("at least one user must be provided for the %s mechanism", settings.SASL.Mechanism)
+	}
+
+	return validateReservedListener(settings.Listeners, saslListenerName, saslPort)
+}
+
+// validateTLS validates the TLS configuration, if any: the cert and key must be a valid
+// keypair, and the SSL listener name and port must not be used by any custom listener.
+func validateTLS(settings options) error {
+	if settings.TLS == nil {
+		return nil
+	}
+
+	if _, err := tls.X509KeyPair(settings.TLS.Cert, settings.TLS.Key); err != nil {
+		return fmt.Errorf("invalid keypair: %w", err)
+	}
+
+	return validateReservedListener(settings.Listeners, tlsListenerName, tlsPort)
+}
+
+// validateConfig validates that the broker config does not override any of the
+// properties managed by the module.
+func validateConfig(settings options) error {
+	for key := range settings.Config {
+		if reason := reservedEnv(configEnvKey(key), settings); reason != "" {
+			return fmt.Errorf("reserved config key: %s is %s", key, reason)
+		}
+	}
+
+	return nil
+}
+
+// validateAdvertisedHost validates that the advertised host, if any, can be used in the
+// comma separated list of advertised listeners.
+func validateAdvertisedHost(host string) error {
+	if strings.ContainsAny(host, ", \t\n/") {
+		return fmt.Errorf("invalid host %q", host)
+	}
+
+	return nil
+}
+
+// validateTopicDefaults validates that the default number of partitions and replication
+// factor, if any, are positive, and that the replication factor can be satisfied by the
+// given number of brokers.
+func validateTopicDefaults(settings options, brokerCount int) error {
+	if settings.DefaultPartitions < 0 {
+		return fmt.Errorf("invalid default partitions: %d", settings.DefaultPartitions)
+	}
+
+	if settings.DefaultReplicationFactor < 0 {
+		return fmt.Errorf("invalid default replication factor: %d", settings.DefaultReplicationFactor)
+	}
+
+	if settings.DefaultReplicationFactor > brokerCount {
+		return fmt.Errorf("default replication factor %d is higher than the broker count %d", settings.DefaultReplicationFactor, brokerCount)
+	}
+
+	if settings.OffsetsTopicReplicationFactor < 0 {
+		return fmt.Errorf("invalid offsets topic replication factor: %d", settings.OffsetsTopicReplicationFactor)
+	}
+
+	if settings.OffsetsTopicReplicationFactor > brokerCount {
+		return fmt.Errorf("offsets topic replication factor %d is higher than the broker count %d", settings.OffsetsTopicReplicationFactor, brokerCount)
+	}
+
+	return nil
+}
+
+// topicDefaultsEnvs returns the environment variables for the automatic creation of topics, and for
+// the default number of partitions and replication factor of the auto-created topics. The replication
+// factor also applies to the internal offsets and transaction state topics, so they don't stay
+// under-replicated, unless the replication factor of the offsets topic is set.
+func topicDefaultsEnvs(settings options) map[string]string {
+	envs := map[string]string{}
+
+	if settings.AutoCreateTopics != nil {
+		envs["KAFKA_AUTO_CREATE_TOPICS_ENABLE"] = strconv.FormatBool(*settings.AutoCreateTopics)
+	}
+
+	if settings.DefaultPartitions > 0 {
+		envs["KAFKA_NUM_PARTITIONS"] = strconv.Itoa(settings.DefaultPartitions)
+	}
+
+	if settings.DefaultReplicationFactor > 0 {
+		replicationFactor := strconv.Itoa(settings.DefaultReplicationFactor)
+		envs["KAFKA_DEFAULT_REPLICATION_FACTOR"] = replicationFactor
+		envs["KAFKA_OFFSETS_TOPIC_REPLICATION_FACTOR"] = replicationFactor
+		envs["KAFKA_TRANSACTION_STATE_LOG_REPLICATION_FACTOR"] = replicationFactor
+	}
+
+	if settings.OffsetsTopicReplicationFactor > 0 {
+		envs["KAFKA_OFFSETS_TOPIC_REPLICATION_FACTOR"] = strconv.Itoa(settings.OffsetsTopicReplicationFactor)
+	}
+
+	return envs
+}
+
+// transactionEnvs returns the environment variables configuring the transaction state log for the
+// transactional producers: it's replicated like the offsets topic, to all the brokers up to three unless
+// a default replication factor is set, a single in-sync replica is enough to commit the transactions,
+// and it has a single partition, so the first transaction doesn't wait for fifty partitions to be created.
+func transactionEnvs(settings options) map[string]string {
+	replicationFactor := min(max(settings.clusterSize, 1), 3)
+	if settings.DefaultReplicationFactor > 0 {
+		replicationFactor = settings.DefaultReplicationFactor
+	}
+
+	return map[string]string{
+		"KAFKA_TRANSACTION_STATE_LOG_REPLICATION_FACTOR": strconv.Itoa(replicationFactor),
+		"KAFKA_TRANSACTION_STATE_LOG_MIN_ISR":            "1",
+		"KAFKA_TRANSACTION_STATE_LOG_NUM_PARTITIONS":     "1",
+	}
+}
+
+// compressionCodecs are the codecs accepted by WithCompression, as understood by the broker
+var compressionCodecs = []string{"gzip", "snappy", "lz4", "zstd"}
+
+// validateCompression validates that the compression codec, if set, is one of the codecs supported
+// by the broker. The "producer" and "uncompressed" values are not codecs, so they are rejected.
+func validateCompression(settings options) error {
+	if settings.Compression == "" {
+		return nil
+	}
+
+	for _, codec := range compressionCodecs {
+		if settings.Compression == codec {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unsupported codec %q, expected one of %s", settings.Compression, strings.Join(compressionCodecs, ", "))
+}
+
+// logLevels are the log4j levels accepted by WithLogLevel
+var logLevels = []string{"TRACE", "DEBUG", "INFO", "WARN", "ERROR", "FATAL", "OFF"}
+
+// validateLogLevel validates that the log level, if set, is a log4j level.
+func validateLogLevel(settings options) error {
+	if settings.LogLevel == "" {
+		return nil
+	}
+
+	for _, level := range logLevels {
+		if settings.LogLevel == level {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unsupported log level %q, expected one of %s", settings.LogLevel, strings.Join(logLevels, ", "))
+}
+
+// logLevelEnvs returns the environment variables setting the level of the root logger of the broker.
+// Above the INFO level, the loggers of the lines the module waits for, when the broker starts up and
+// shuts down, are kept at the INFO level: the BrokerLifecycleManager and the BrokerServer in KRaft mode,
+// and the KafkaServer in ZooKeeper mode.
+func logLevelEnvs(settings options) map[string]string {
+	envs := map[string]string{
+		"KAFKA_LOG4J_ROOT_LOGLEVEL": settings.LogLevel,
+	}
+
+	switch settings.LogLevel {
+	case "TRACE", "DEBUG", "INFO":
+	default:
+		if settings.Zookeeper {
+			envs["KAFKA_LOG4J_LOGGERS"] = "kafka.server.KafkaServer=INFO"
+		} else {
+			envs["KAFKA_LOG4J_LOGGERS"] = "kafka.server.BrokerLifecycleManager=INFO,kafka.server.BrokerServer=INFO"
+		}
+	}
+
+	return envs
+}
+
+// validateAuthorizer validates that the super users, if the authorizer is enabled,
+// are valid principals, as they are joined with semicolons in the super.users property.
+func validateAuthorizer(settings options) error {
+	if !settings.Authorizer {
+		return nil
+	}
+
+	for _, principal := range settings.SuperUsers {
+		if strings.Contains(principal, ";") {
+			return fmt.Errorf("invalid super user %q: it cannot contain semicolons", principal)
+		}
+
+		if _, name, _ := strings.Cut(superUserPrincipal(principal), ":"); strings.TrimSpace(name) == "" {
+			return fmt.Errorf("invalid super user %q: empty name", principal)
+		}
+	}
+
+	return nil
+}
+
+// superUserPrincipal returns the principal with the "User" type if it has no type.
+func superUserPrincipal(principal string) string {
+	if !strings.Contains(principal, ":") {
+		return "User:" + principal
+	}
+
+	return principal
+}
+
+// authorizerEnvs returns the environment variables enabling the standard KRaft ACL authorizer with
+// the given super users. The anonymous user is always a super user, as the controller and inter-broker
+// listeners, and the plaintext one used by the module, do not authenticate the clients.
+func authorizerEnvs(superUsers []string) map[string]string {
+	principals := []string{"User:ANONYMOUS"}
+	for _, principal := range superUsers {
+		principals = append(principals, superUserPrincipal(principal))
+	}
+
+	return map[string]string{
+		"KAFKA_AUTHORIZER_CLASS_NAME": "org.apache.kafka.metadata.authorizer.StandardAuthorizer",
+		"KAFKA_SUPER_USERS":           strings.Join(principals, ";"),
+	}
+}
+
+// validateBrokerPort validates that the port of the default internal listener, if any,
+// is a valid port not reserved by the module, and that no custom listener replaces it.
+func validateBrokerPort(settings options) error {
+	if settings.BrokerPort == "" {
+		return nil
+	}
+
+	if port, err := strconv.Atoi(settings.BrokerPort); err != nil || port < 1 || port > 65535 {
+		return fmt.Errorf("invalid port %q", settings.BrokerPort)
+	}
+
+	for _, item := range reservedPorts {
+		if item.Port() == settings.BrokerPort {
+			return fmt.Errorf("port %s is reserved by the module", settings.BrokerPort)
+		}
+	}
+
+	if len(settings.Listeners) > 0 {
+		return errors.New("the custom listeners replace the default internal listener, set their ports instead")
+	}
+
+	return nil
+}
+
+// validateFixedBrokerPort validates that the fixed host port of the external listener, if any, is a valid
+// port which is not in use, unless the container is reused, in which case it may be bound by the running broker.
+func validateFixedBrokerPort(settings options) error {
+	if settings.FixedBrokerPort == 0 {
+		return nil
+	}
+
+	if settings.FixedBrokerPort < 1 || settings.FixedBrokerPort > 65535 {
+		return fmt.Errorf("invalid port: %d", settings.FixedBrokerPort)
+	}
+
+	if settings.clusterSize > 0 {
+		return errors.New("the brokers of a cluster cannot bind the same host port")
+	}
+
+	if settings.ReuseName != "" {
+		return nil
+	}
+
+	l, err := net.Listen("tcp", fmt.Sprintf(":%d", settings.FixedBrokerPort))
+	if err != nil {
+		return fmt.Errorf("host port %d is already in use: %w", settings.FixedBrokerPort, err)
+	}
+
+	return l.Close()
+}
+
+// brokerPortEnvs returns the environment variables for the listeners of the broker, with the
+// default internal listener on the given port.
+func brokerPortEnvs(port string) map[string]string {
+	listeners := fmt.Sprintf("EXTERNAL://0.0.0.0:9093,INTERNAL://0.0.0.0:%s,CONTROLLER://0.0.0.0:9094", port)
+
+	return map[string]string{
+		"KAFKA_LISTENERS":              listeners,
+		"KAFKA_REST_BOOTSTRAP_SERVERS": listeners,
+	}
+}
+
+// validateNodeID validates that the node id, if any, is positive.
+func validateNodeID(settings options) error {
+	if settings.NodeID < 0 {
+		return fmt.Errorf("invalid node id: %d", settings.NodeID)
+	}
+
+	return nil
+}
+
+// validateJMX validates that the JMX port, if any, is a valid port not used
+// by any of the listeners nor the REST proxy.
+func validateJMX(settings options) error {
+	if settings.JMXPort == 0 {
+		return nil
+	}
+
+	if settings.JMXPort < 1 || settings.JMXPort > 65535 {
+		return fmt.Errorf("invalid port: %d", settings.JMXPort)
+	}
+
+	port := strconv.Itoa(settings.JMXPort)
+
+	if port == settings.brokerPort() {
+		return fmt.Errorf("port %s is used by the internal listener", port)
+	}
+
+	for _, item := range reservedPorts {
+		if item.Port() == port {
+			return fmt.Errorf("port %s is reserved by the module", port)
+		}
+	}
+
+	for _, item := range settings.Listeners {
+		if item.Port == port {
+			return fmt.Errorf("port %s is used by the listener %s", port, item.Name)
+		}
+	}
+
+	return nil
+}
+
+// jmxEnvs returns the environment variables enabling the remote JMX agent on the given port,
+// advertising the given hostname. The confluent images build the JMX options from the
+// KAFKA_JMX_* variables, while the apache images read them from the JMX_PORT and
+// KAFKA_JMX_OPTS ones. In both cases, the RMI registry uses the same port as the agent.
+func jmxEnvs(flavor imageFlavor, port int, hostname string) map[string]string {
+	if flavor == flavorApache {
+		return map[string]string{
+			"JMX_PORT": strconv.Itoa(port),
+			"KAFKA_JMX_OPTS": strings.Join([]string{
+				"-Dcom.sun.management.jmxremote",
+				"-Dcom.sun.management.jmxremote.authenticate=false",
+				"-Dcom.sun.management.jmxremote.ssl=false",
+				"-Dcom.sun.management.jmxremote.local.only=false",
+				"-Djava.rmi.server.hostname=" + hostname,
+				fmt.Sprintf("-Dcom.sun.management.jmxremote.rmi.port=%d", port),
+			}, " "),
+		}
+	}
+
+	return map[string]string{
+		"KAFKA_JMX_PORT":     strconv.Itoa(port),
+		"KAFKA_JMX_HOSTNAME": hostname,
+	}
+}
+
+// validateEnv validates that the environment variables set with WithEnv do not
+// override any of the ones managed by the module.
+func validateEnv(settings options) error {
+	for key := range settings.Env {
+		if reason := reservedEnv(key, settings); reason != "" {
+			return fmt.Errorf("reserved env: %s is %s", key, reason)
+		}
+	}
+
+	return nil
+}
+
+// reservedEnv returns the reason why the environment variable is managed by the module,
+// including the data directories if a data volume is mounted. It returns an empty
+// string if the variable can be set by the users.
+func reservedEnv(env string, settings options) string {
+	switch {
+	case env == "KAFKA_INTER_BROKER_LISTENER_NAME":
+		return "managed by the module, use WithInterBrokerListener instead"
+	case reservedConfigEnvs[env]:
+		return "managed by the module"
+	case env == "CLUSTER_ID":
+		return "managed by the module, use WithClusterID instead"
+	case env == "KAFKA_REST_BOOTSTRAP_SERVERS":
+		return "managed by the module, as it follows the listeners"
+	case settings.DataVolume != "" && (env == "KAFKA_LOG_DIRS" || env == "KAFKA_METADATA_LOG_DIR"):
+		return "managed by the module when using a data volume"
+	case settings.Compression != "" && env == "KAFKA_COMPRESSION_TYPE":
+		return "managed by the module when using WithCompression"
+	case settings.Transactions && strings.HasPrefix(env, "KAFKA_TRANSACTION_STATE_LOG_"):
+		return "managed by the module when using WithTransactions"
+	case settings.Zookeeper && env == "KAFKA_ZOOKEEPER_CONNECT":
+		return "managed by the module when using WithZookeeper"
+	case settings.IdempotenceReady && env == "KAFKA_MIN_INSYNC_REPLICAS":
+		return "managed by the module when using WithIdempotenceReady"
+	case settings.LogLevel != "" && (env == "KAFKA_LOG4J_ROOT_LOGLEVEL" || env == "KAFKA_LOG4J_LOGGERS"):
+		return "managed by the module when using WithLogLevel"
+	}
+
+	return ""
+}
+
+// managedFiles are the files copied into the container by the module, which cannot be overwritten
+// by the files provided with WithFiles.
+var managedFiles = map[string]string{
+	starterScript:        "the starter script",
+	serverPropertiesFile: "the server.properties file, use WithServerPropertiesFile instead",
+	tlsKeystoreFile:      "the TLS keystore, use WithTLS instead",
+	tlsTruststoreFile:    "the TLS truststore, use WithTLS instead",
+	jaasConfigFile:       "the JAAS file, use WithJAASConfigFile instead",
+}
+
+// validateFiles validates that the files provided with WithFiles have a container path, and that
+// they do not overwrite any of the files managed by the module.
+func validateFiles(settings options) error {
+	for i, file := range settings.Files {
+		if strings.TrimSpace(file.ContainerFilePath) == "" {
+			return fmt.Errorf("file at index %d: empty container path", i)
+		}
+
+		if reason, ok := managedFiles[path.Clean(file.ContainerFilePath)]; ok {
+			return fmt.Errorf("file %s: managed by the module, as %s", file.ContainerFilePath, reason)
+		}
+	}
+
+	return nil
+}
+
+// validateJAASConfigFile validates that the JAAS file, if any, is a regular file.
+func validateJAASConfigFile(settings options) error {
+	if settings.JAASConfigFile == "" {
+		return nil
+	}
+
+	info, err := os.Stat(settings.JAASConfigFile)
+	if err != nil {
+		return fmt.Errorf("stat jaas config file: %w", err)
+	}
+
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("%s is not a regular file", settings.JAASConfigFile)
+	}
+
+	return nil
+}
+
+// validateServerProperties validates that the server.properties file, if any, can be read and does
+// not define any of the properties managed by the module, like the listeners or the advertised listeners.
+func validateServerProperties(settings options) error {
+	if settings.ServerPropertiesFile == "" {
+		return nil
+	}
+
+	content, err := os.ReadFile(settings.ServerPropertiesFile)
+	if err != nil {
+		return fmt.Errorf("read server properties: %w", err)
+	}
+
+	for _, key := range propertiesKeys(content) {
+		if reason := reservedEnv(configEnvKey(key), settings); reason != "" {
+			return fmt.Errorf("reserved server property: %s is %s", key, reason)
+		}
+	}
+
+	return nil
+}
+
+// propertiesKeys returns the keys defined in the content of a Java properties file,
+// skipping comments and blank lines. Keys are separated from the values by '=', ':' or whitespace.
+func propertiesKeys(content []byte) []string {
+	var keys []string
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+			continue
+		}
+
+		if idx := strings.IndexAny(line, "=: \t"); idx >= 0 {
+			line = line[:idx]
+		}
+
+		keys = append(keys, line)
+	}
+
+	return keys
+}
+
+// configEnvs returns the environment variables for the broker config properties.
+func configEnvs(config map[string]string) map[string]string {
+	envs := make(map[string]string, len(config))
+	for key, value := range config {
+		envs[configEnvKey(key)] = value
+	}
+
+	return envs
+}
+
+// configEnvKey converts a broker config property into the environment variable read by the
+// Kafka images, which convert underscores into dots, double underscores into underscores
+// and triple underscores into dashes. E.g. "message.max.bytes" into KAFKA_MESSAGE_MAX_BYTES.
+func configEnvKey(key string) string {
+	key = strings.TrimSpace(key)
+	key = strings.ReplaceAll(key, "_", "__")
+	key = strings.ReplaceAll(key, "-", "___")
+	key = strings.ReplaceAll(key, ".", "_")
+
+	return "KAFKA_" + strings.ToUpper(key)
+}
+
+// validateInterBrokerListener validates that the inter-broker listener, if set, is one of the plaintext
+// listeners of the broker: the custom listeners, or the default internal one, or the external one.
+// The SASL and TLS listeners are not supported, as the brokers would need credentials to connect to them.
+func validateInterBrokerListener(settings options) error {
+	name := settings.InterBrokerListener
+	if name == "" {
+		return nil
+	}
+
+	switch {
+	case name == "CONTROLLER":
+		return errors.New("the controller listener cannot be the inter-broker listener")
+	case name == saslListenerName && settings.SASL != nil, name == tlsListenerName && settings.TLS != nil:
+		return fmt.Errorf("listener %s not supported as the inter-broker listener, use a plaintext listener instead", name)
+	}
+
+	names := []string{"INTERNAL"}
+	if len(settings.Listeners) > 0 {
+		names = names[:0]
+		for _, item := range settings.Listeners {
+			names = append(names, item.Name)
+		}
+	}
+	names = append(names, "EXTERNAL")
+
+	for _, item := range names {
+		if item == name {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown listener %s, expected one of %s", name, strings.Join(names, ", "))
+}
+
+// validateReservedListener checks that the name and port of a listener managed by the module
+// are not used by any of the custom listeners.
+func validateReservedListener(listeners []KafkaListener, name string, port nat.Port) error {
+	for _, item := range listeners {
+		if item.Name == name {
+			return fmt.Errorf("duplicate of listener name: %s", item.Name)
+		}
+
+		if item.Port == port.Port() {
+			return fmt.Errorf("duplicate of listener port: %s", item.Port)
+		}
+	}
+
+	return nil
+}
+
+// editEnvsForSASL returns the environment variables needed to add the SASL listener
+// to the current ones, including the JAAS configuration with the given users.
+func editEnvsForSASL(current map[string]string, sasl saslConfig) map[string]string {
+	return map[string]string{
+		"KAFKA_LISTENERS": strings.Join(
+			[]string{
+				current["KAFKA_LISTENERS"],
+				fmt.Sprintf("%s://0.0.0.0:%s", saslListenerName, saslPort.Port()),
+			},
+			",",
+		),
+		"KAFKA_LISTENER_SECURITY_PROTOCOL_MAP": strings.Join(
+			[]string{
+				current["KAFKA_LISTENER_SECURITY_PROTOCOL_MAP"],
+				saslListenerName + ":" + "SASL_PLAINTEXT",
+			},
+			",",
+		),
+		"KAFKA_SASL_ENABLED_MECHANISMS": sasl.Mechanism,
+		saslJAASConfigEnv(sasl):         jaasConfig(sasl),
+	}
+}
+
+// saslJAASConfigEnv returns the environment variable of the JAAS config of the SASL listener for the mechanism.
+// The confluent images convert triple underscores into dashes, e.g. SCRAM___SHA___256 into scram-sha-256.
+func saslJAASConfigEnv(sasl saslConfig) string {
+	return fmt.Sprintf("KAFKA_LISTENER_NAME_%s_%s_SASL_JAAS_CONFIG", saslListenerName, strings.ReplaceAll(sasl.Mechanism, "-", "___"))
+}
+
+// appendJVMFlag appends the flag to the space separated JVM flags, e.g. the ones of KAFKA_OPTS.
+func appendJVMFlag(flags string, flag string) string {
+	return strings.TrimSpace(strings.TrimSpace(flags) + " " + flag)
+}
+
+// editEnvsForTLS returns the environment variables needed to add the SSL listener
+// to the current ones, using the PEM keystore and truststore copied into the container.
+func editEnvsForTLS(current map[string]string) map[string]string {
+	return map[string]string{
+		"KAFKA_LISTENERS": strings.Join(
+			[]string{
+				current["KAFKA_LISTENERS"],
+				fmt.Sprintf("%s://0.0.0.0:%s", tlsListenerName, tlsPort.Port()),
+			},
+			",",
+		),
+		"KAFKA_LISTENER_SECURITY_PROTOCOL_MAP": strings.Join(
+			[]string{
+				current["KAFKA_LISTENER_SECURITY_PROTOCOL_MAP"],
+				tlsListenerName + ":" + "SSL",
+			},
+			",",
+		),
+		"KAFKA_LISTENER_NAME_TLS_SSL_KEYSTORE_TYPE":       "PEM",
+		"KAFKA_LISTENER_NAME_TLS_SSL_KEYSTORE_LOCATION":   tlsKeystoreFile,
+		"KAFKA_LISTENER_NAME_TLS_SSL_TRUSTSTORE_TYPE":     "PEM",
+		"KAFKA_LISTENER_NAME_TLS_SSL_TRUSTSTORE_LOCATION": tlsTruststoreFile,
+	}
+}
+
+// tlsFiles returns the keystore, containing the key and the cert, and the truststore,
+// containing the CA, to be copied into the container before it starts. The key is
+// converted to the PKCS #8 encoding, as it's the only one supported by the PEM keystores.
+func tlsFiles(cfg tlsConfig) ([]testcontainers.ContainerFile, error) {
+	keyPair, err := tls.X509KeyPair(cfg.Cert, cfg.Key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid keypair: %w", err)
+	}
+
+	pkcs8Key, err := x509.MarshalPKCS8PrivateKey(keyPair.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("can't encode the key: %w", err)
+	}
+
+	keystore := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8Key})
+	keystore = append(keystore, cfg.Cert...)
+
+	return []testcontainers.ContainerFile{
+		{
+			Reader:            bytes.NewReader(keystore),
+			ContainerFilePath: tlsKeystoreFile,
+			FileMode:          0o644,
+		},
+		{
+			Reader:            bytes.NewReader(trustedCA(cfg)),
+			ContainerFilePath: tlsTruststoreFile,
+			FileMode:          0o644,
+		},
+	}, nil
+}
+
+// trustedCA returns the CA to trust, which is the cert itself when it's self-signed.
+func trustedCA(cfg tlsConfig) []byte {
+	if len(cfg.CA) == 0 {
+		return cfg.Cert
+	}
+
+	return cfg.CA
+}
+
+// jaasConfig builds the broker side JAAS configuration for the SASL mechanism.
+// For PLAIN, the users are defined in the JAAS configuration itself, while for SCRAM
+// they are stored as credentials in the cluster metadata when formatting the storage.
+func jaasConfig(sasl saslConfig) string {
+	if sasl.Mechanism != saslMechanismPlain {
+		return "org.apache.kafka.common.security.scram.ScramLoginModule required;"
+	}
+
+	entries := []string{"org.apache.kafka.common.security.plain.PlainLoginModule required"}
+	for _, username := range sortedUsernames(sasl.Users) {
+		entries = append(entries, fmt.Sprintf("user_%s=%q", username, sasl.Users[username]))
+	}
+
+	return strings.Join(entries, " ") + ";"
+}
+
+// scramCredentialsArgs returns the arguments for the kafka-storage format command
+// that provision the SCRAM credentials of the users. It returns an empty string
+// for non-SCRAM mechanisms.
+func scramCredentialsArgs(sasl saslConfig) string {
+	if sasl.Mechanism == saslMechanismPlain {
+		return ""
+	}
+
+	var args string
+	for _, username := range sortedUsernames(sasl.Users) {
+		args += fmt.Sprintf(` --add-scram "%s=[name=%s,password=%s]"`, sasl.Mechanism, username, sasl.Users[username])
+	}
+
+	return args
+}
+
+// sortedUsernames returns the usernames of the users map in alphabetical order,
+// to produce a deterministic configuration.
+func sortedUsernames(users map[string]string) []string {
+	usernames := make([]string, 0, len(users))
+	for username := range users {
+		usernames = append(usernames, username)
+	}
+	sort.Strings(usernames)
+
+	return usernames
+}
+
+// brokersCache holds the broker connection strings by container port, as the host of the daemon and
+// the mapped ports do not change while the container runs.
+type brokersCache struct {
+	sync.Mutex
+	brokers map[nat.Port][]string
+}
+
+// get returns a copy of the cached brokers of the container port, if any.
+func (c *brokersCache) get(containerPort nat.Port) ([]string, bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	brokers, ok := c.brokers[containerPort]
+	return slices.Clone(brokers), ok
+}
+
+// set caches a copy of the brokers of the container port.
+func (c *brokersCache) set(containerPort nat.Port, brokers []string) {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.brokers == nil {
+		c.brokers = make(map[nat.Port][]string)
+	}
+
+	c.brokers[containerPort] = slices.Clone(brokers)
+}
+
+// reset drops the cached brokers of all the container ports.
+func (c *brokersCache) reset() {
+	c.Lock()
+	defer c.Unlock()
+
+	c.brokers = nil
+}
+
+// Terminate terminates the Kafka Connect and Schema Registry containers, if any, then the Kafka container,
+// then the ZooKeeper container, if any, and finally removes the network created for them, if any.
+// The options, e.g. testcontainers.StopTimeout, only apply to the Kafka container.
+func (kc *KafkaContainer) Terminate(ctx context.Context, opts ...testcontainers.TerminateOption) error {
+	var errs []error
+	if kc.connect != nil {
+		if err := kc.connect.Terminate(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("terminate kafka connect: %w", err))
+		}
+	}
+
+	if kc.schemaRegistry != nil {
+		if err := kc.schemaRegistry.Terminate(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("terminate schema registry: %w", err))
+		}
+	}
+
+	// the broker is not set if RunContainer failed before starting it
+	if kc.Container != nil {
+		if err := kc.Container.Terminate(ctx, opts...); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// the broker is terminated first, so it does not lose its ZooKeeper session while running
+	if kc.zookeeper != nil {
+		if err := kc.zookeeper.Terminate(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("terminate zookeeper: %w", err))
+		}
+	}
+
+	if kc.network != nil {
+		if err := kc.network.Remove(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("remove network: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Start starts the container, dropping the cached broker connection strings, as the ports of a restarted
+// container are mapped to new host ports.
+func (kc *KafkaContainer) Start(ctx context.Context) error {
+	kc.brokers.reset()
+
+	return kc.Container.Start(ctx)
+}
+
+// Stop stops the container, dropping the cached broker connection strings, which are not reachable anymore.
+func (kc *KafkaContainer) Stop(ctx context.Context, timeout *time.Duration) error {
+	defer kc.brokers.reset()
+
+	return kc.Container.Stop(ctx, timeout)
+}
+
+// Brokers retrieves the broker connection strings from Kafka with only one entry,
+// defined by the exposed public port, and the host set with WithAdvertisedHost, if any.
+// It returns the error of the context as soon as it's done. The result is cached after the
+// first successful call, so the Docker daemon is not queried again, until the container is
+// stopped or started. Use RefreshBrokers to compute it again.
+func (kc *KafkaContainer) Brokers(ctx context.Context) ([]string, error) {
+	return kc.mappedBrokers(ctx, publicPort)
+}
+
+// RefreshBrokers drops the broker connection strings cached by Brokers, SASLBrokers and TLSBrokers,
+// and returns the ones computed again by Brokers, e.g. if the container was restarted with the
+// Docker API directly, so its ports are mapped to new host ports.
+func (kc *KafkaContainer) RefreshBrokers(ctx context.Context) ([]string, error) {
+	kc.brokers.reset()
+
+	return kc.Brokers(ctx)
+}
+
+// mappedBrokers returns the broker connection string of the listener mapped to the given port,
+// caching it once computed. The Docker API calls may not honor the context, e.g. against a stuck
+// daemon, or may not be issued at all if the container info is cached, so the context is checked
+// on its own.
+func (kc *KafkaContainer) mappedBrokers(ctx context.Context, containerPort nat.Port) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if brokers, ok := kc.brokers.get(containerPort); ok {
+		return brokers, nil
+	}
+
+	type result struct {
+		brokers []string
+		err     error
+	}
+
+	// buffered, so the goroutine does not leak if the context is done first
+	done := make(chan result, 1)
+	go func() {
+		host, err := listenerHost(ctx, kc, kc.opts)
+		if err != nil {
+			done <- result{err: err}
+			return
+		}
+
+		port, err := kc.MappedPort(ctx, containerPort)
+		if err != nil {
+			done <- result{err: err}
+			return
+		}
+
+		done <- result{brokers: []string{hostPort(host, port.Port())}}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case r := <-done:
+		if r.err != nil {
+			return nil, r.err
+		}
+
+		kc.brokers.set(containerPort, r.brokers)
+
+		return r.brokers, nil
+	}
+}
+
+// Listeners returns the listeners advertised by the broker: the custom listeners set with WithListener,
+// or the default internal one, followed by the external listener, and by the SASL and TLS ones if
+// they are enabled. Each listener reports whether it's internal, its mapped host port, if any, and
+// its security protocol.
+func (kc *KafkaContainer) Listeners(ctx context.Context) ([]ListenerInfo, error) {
+	return advertisedListeners(ctx, kc, kc.opts)
+}
+
+// Mode returns the mode the broker was configured with, i.e. ModeKRaft, or ModeZookeeper if it was
+// started with WithZookeeper, so the tests can branch on the topology of the cluster.
+func (kc *KafkaContainer) Mode() string {
+	if kc.opts.Zookeeper {
+		return ModeZookeeper
+	}
+
+	return ModeKRaft
+}
+
+// StartupScript returns the starter script the module generated for the broker, as it was copied into
+// the container before the broker was launched, e.g. to check the advertised listeners, or to debug
+// a listener config the broker does not start with.
+func (kc *KafkaContainer) StartupScript() string {
+	return kc.startupScript
+}
+
+// NetworkIP returns the IP address of the container in the given network, e.g. to advertise it in a
+// listener, or to check the network aliases resolve to it. It's not named ContainerIP, which returns
+// the IP address in the primary network for any container. It returns an error if the container is
+// not attached to the network.
+func (kc *KafkaContainer) NetworkIP(ctx context.Context, networkName string) (string, error) {
+	inspect, err := kc.Inspect(ctx)
+	if err != nil {
+		return "", fmt.Errorf("inspect container: %w", err)
+	}
+
+	endpoint, ok := inspect.NetworkSettings.Networks[networkName]
+	if !ok || endpoint == nil {
+		return "", fmt.Errorf("container not attached to the network %s", networkName)
+	}
+
+	return endpoint.IPAddress, nil
+}
+
+// SASLBrokers retrieves the broker connection strings of the SASL listener, defined by
+// the exposed SASL port. It returns ErrSASLNotEnabled if SASL was not enabled for the container.
+func (kc *KafkaContainer) SASLBrokers(ctx context.Context) ([]string, error) {
+	if kc.opts.SASL == nil {
+		return nil, ErrSASLNotEnabled
+	}
+
+	return kc.mappedBrokers(ctx, saslPort)
+}
+
+// configureControllerQuorumVoters sets the quorum voters for the controller. For that, it will
+// check if there are any network aliases defined for the container and use the first alias in the
+// first network. Else, it will use localhost.
+func configureControllerQuorumVoters(req *testcontainers.GenericContainerRequest) {
+	if req.Env == nil {
+		req.Env = map[string]string{}
+	}
+
+	if req.Env["KAFKA_CONTROLLER_QUORUM_VOTERS"] == "" {
+		host := "localhost"
+		if len(req.Networks) > 0 {
+			nw := req.Networks[0]
+			if len(req.NetworkAliases[nw]) > 0 {
+				host = req.NetworkAliases[nw][0]
+			}
+		}
+
+		nodeID := req.Env["KAFKA_NODE_ID"]
+		if nodeID == "" {
+			nodeID = "1"
+		}
+
+		req.Env["KAFKA_CONTROLLER_QUORUM_VOTERS"] = fmt.Sprintf("%s@%s:9094", nodeID, host)
+	}
+	// }
+}
+
+// JMXEndpoint returns the host and mapped port of the JMX agent, e.g. "localhost:32768".
+// It returns ErrJMXNotEnabled if JMX was not enabled for the container.
+func (kc *KafkaContainer) JMXEndpoint(ctx context.Context) (string, error) {
+	if kc.opts.JMXPort == 0 {
+		return "", ErrJMXNotEnabled
+	}
+
+	host, err := kc.Host(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	port, err := kc.MappedPort(ctx, nat.Port(fmt.Sprintf("%d/tcp", kc.opts.JMXPort)))
+	if err != nil {
+		return "", err
+	}
+
+	return hostPort(host, port.Port()), nil
+}
+
+// ControllerEndpoint returns the host and mapped port of the controller listener, e.g. "localhost:32768",
+// for the tools connecting to the controller quorum directly. It returns ErrControllerNotExposed if the
+// controller listener was not exposed for the container.
+func (kc *KafkaContainer) ControllerEndpoint(ctx context.Context) (string, error) {
+	if !kc.opts.ExposedController {
+		return "", ErrControllerNotExposed
+	}
+
+	host, err := kc.Host(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	port, err := kc.MappedPort(ctx, controllerPort)
+	if err != nil {
+		return "", err
+	}
+
+	return hostPort(host, port.Port()), nil
+}
+
+// RestProxyURL returns the URL of the REST proxy, defined by the exposed REST proxy port.
+// It returns ErrRestProxyNotEnabled if the REST proxy was not enabled for the container.
+func (kc *KafkaContainer) RestProxyURL(ctx context.Context) (string, error) {
+	if !kc.opts.RestProxy {
+		return "", ErrRestProxyNotEnabled
+	}
+
+	host, err := kc.Host(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	port, err := kc.MappedPort(ctx, restProxyPort)
+	if err != nil {
+		return "", err
+	}
+
+	return "http://" + hostPort(host, port.Port()), nil
+}
+
+// TLSBrokers retrieves the broker connection strings of the SSL listener, defined by
+// the exposed TLS port. It returns ErrTLSNotEnabled if TLS was not enabled for the container.
+func (kc *KafkaContainer) TLSBrokers(ctx context.Context) ([]string, error) {
+	if kc.opts.TLS == nil {
+		return nil, ErrTLSNotEnabled
+	}
+
+	return kc.mappedBrokers(ctx, tlsPort)
+}
+
+// TLSConfig returns a TLS configuration trusting the CA, or the self-signed cert, provided
+// with WithTLS, so it can be used by the clients connecting to the SSL listener.
+// E.g. sarama's config.Net.TLS.Config. It returns ErrTLSNotEnabled if TLS was not enabled.
+func (kc *KafkaContainer) TLSConfig() (*tls.Config, error) {
+	if kc.opts.TLS == nil {
+		return nil, ErrTLSNotEnabled
+	}
+
+	certPool := x509.NewCertPool()
+	if !certPool.AppendCertsFromPEM(trustedCA(*kc.opts.TLS)) {
+		return nil, errors.New("failed to append the CA certificate to the pool")
+	}
+
+	return &tls.Config{
+		RootCAs:    certPool,
+		MinVersion: tls.VersionTLS12,
+	}, nil
 }
@@ -1,13 +1,28 @@
 package kafka_test
 
 import (
+	"bytes"
 	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
 	"fmt"
 	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/IBM/sarama"
+	"github.com/google/uuid"
+	"github.com/mdelapenya/tlscert"
 
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/modules/kafka"
@@ -16,78 +31,143 @@ import (
 )
 
 func TestKafka(t *testing.T) {
-	topic := "some-topic"
+	images := []string{
+		"confluentinc/confluent-local:7.5.0",
+		"apache/kafka:3.7.0",
+	}
+
+	for _, image := range images {
+		t.Run(image, func(t *testing.T) {
+			topic := "some-topic"
+
+			ctx := context.Background()
+
+			kafkaContainer, err := kafka.RunContainer(ctx, kafka.WithClusterID("kraftCluster"), testcontainers.WithImage(image))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Clean up the container after the test is complete
+			t.Cleanup(func() {
+				if err := kafkaContainer.Terminate(ctx); err != nil {
+					t.Fatalf("failed to terminate container: %s", err)
+				}
+			})
+
+			assertAdvertisedListeners(t, kafkaContainer)
+
+			if !strings.EqualFold(kafkaContainer.ClusterLabel, "kraftCluster") {
+				t.Fatalf("expected clusterLabel to be %s, got %s", "kraftCluster", kafkaContainer.ClusterLabel)
+			}
+
+			// the cluster id is derived from the label, as a base64 encoded UUID
+			if len(kafkaContainer.ClusterID) != 22 {
+				t.Fatalf("expected clusterID to be a base64 encoded UUID, got %s", kafkaContainer.ClusterID)
+			}
+
+			// getBrokers {
+			brokers, err := kafkaContainer.Brokers(ctx)
+			// }
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			config := sarama.NewConfig()
+			client, err := sarama.NewConsumerGroup(brokers, "groupName", config)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			consumer, ready, done, cancel := NewTestKafkaConsumer(t)
+			go func() {
+				if err := client.Consume(context.Background(), []string{topic}, consumer); err != nil {
+					cancel()
+				}
+			}()
+
+			// wait for the consumer to be ready
+			<-ready
+
+			// perform assertions
+
+			// set config to true because successfully delivered messages will be returned on the Successes channel
+			config.Producer.Return.Successes = true
+
+			producer, err := sarama.NewSyncProducer(brokers, config)
+			if err != nil {
+				cancel()
+				t.Fatal(err)
+			}
+
+			if _, _, err := producer.SendMessage(&sarama.ProducerMessage{
+				Topic: topic,
+				Key:   sarama.StringEncoder("key"),
+				Value: sarama.StringEncoder("value"),
+			}); err != nil {
+				cancel()
+				t.Fatal(err)
+			}
+
+			<-done
+
+			if !strings.EqualFold(string(consumer.message.Key), "key") {
+				t.Fatalf("expected key to be %s, got %s", "key", string(consumer.message.Key))
+			}
+			if !strings.EqualFold(string(consumer.message.Value), "value") {
+				t.Fatalf("expected value to be %s, got %s", "value", string(consumer.message.Value))
+			}
+		})
+	}
+}
 
+func TestKafka_generatedClusterID(t *testing.T) {
 	ctx := context.Background()
 
-	kafkaContainer, err := kafka.RunContainer(ctx, kafka.WithClusterID("kraftCluster"), testcontainers.WithImage("confluentinc/confluent-local:7.5.0"))
+	kafkaContainer, err := kafka.RunContainer(ctx, testcontainers.WithImage("confluentinc/confluent-local:7.6.1"))
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	// Clean up the container after the test is complete
 	t.Cleanup(func() {
 		if err := kafkaContainer.Terminate(ctx); err != nil {
 			t.Fatalf("failed to terminate container: %s", err)
 		}
 	})
 
-	assertAdvertisedListeners(t, kafkaContainer)
-
-	if !strings.EqualFold(kafkaContainer.ClusterID, "kraftCluster") {
-		t.Fatalf("expected clusterID to be %s, got %s", "kraftCluster", kafkaContainer.ClusterID)
+	// without WithClusterID, a new cluster id is generated, with no label
+	if len(kafkaContainer.ClusterID) != 22 || kafkaContainer.ClusterLabel != "" {
+		t.Fatalf("expected a generated cluster id with no label, got %q and %q", kafkaContainer.ClusterID, kafkaContainer.ClusterLabel)
 	}
 
-	// getBrokers {
 	brokers, err := kafkaContainer.Brokers(ctx)
-	// }
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	config := sarama.NewConfig()
-	client, err := sarama.NewConsumerGroup(brokers, "groupName", config)
+	config, err := kafkaContainer.SaramaConfig()
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	consumer, ready, done, cancel := NewTestKafkaConsumer(t)
-	go func() {
-		if err := client.Consume(context.Background(), []string{topic}, consumer); err != nil {
-			cancel()
-		}
-	}()
-
-	// wait for the consumer to be ready
-	<-ready
-
-	// perform assertions
-
-	// set config to true because successfully delivered messages will be returned on the Successes channel
-	config.Producer.Return.Successes = true
-
-	producer, err := sarama.NewSyncProducer(brokers, config)
+	client, err := sarama.NewClient(brokers, config)
 	if err != nil {
-		cancel()
 		t.Fatal(err)
 	}
+	defer client.Close()
 
-	if _, _, err := producer.SendMessage(&sarama.ProducerMessage{
-		Topic: topic,
-		Key:   sarama.StringEncoder("key"),
-		Value: sarama.StringEncoder("value"),
-	}); err != nil {
-		cancel()
+	controller, err := client.Controller()
+	if err != nil {
 		t.Fatal(err)
 	}
 
-	<-done
-
-	if !strings.EqualFold(string(consumer.message.Key), "key") {
-		t.Fatalf("expected key to be %s, got %s", "key", string(consumer.message.Key))
+	// the broker reports the generated cluster id
+	metadata, err := controller.GetMetadata(&sarama.MetadataRequest{Version: 2})
+	if err != nil {
+		t.Fatal(err)
 	}
-	if !strings.EqualFold(string(consumer.message.Value), "value") {
-		t.Fatalf("expected value to be %s, got %s", "value", string(consumer.message.Value))
+
+	if metadata.ClusterID == nil || *metadata.ClusterID != kafkaContainer.ClusterID {
+		t.Fatalf("expected the broker to report the cluster id %s, got %v", kafkaContainer.ClusterID, metadata.ClusterID)
 	}
 }
 
@@ -98,275 +178,3816 @@ func TestKafka_invalidVersion(t *testing.T) {
 	if err == nil {
 		t.Fatal(err)
 	}
-}
 
-func TestKafka_networkConnectivity(t *testing.T) {
-	ctx := context.Background()
-	var err error
+	var versionErr *kafka.UnsupportedVersionError
+	if !errors.As(err, &versionErr) {
+		t.Fatalf("expected UnsupportedVersionError, got %v", err)
+	}
 
-	const (
-		topic_in  = "topic_in"
-		topic_out = "topic_out"
-	)
+	if versionErr.Tag != "6.3.3" {
+		t.Fatalf("expected tag 6.3.3, got %s", versionErr.Tag)
+	}
 
-	Network, err := network.New(ctx, network.WithCheckDuplicate())
-	if err != nil {
+	_, err = kafka.RunContainer(ctx, kafka.WithClusterID("kraftCluster"), testcontainers.WithImage("apache/kafka:3.6.0"))
+	if err == nil {
 		t.Fatal(err)
 	}
+}
 
-	// kafkaWithListener {
-	KafkaContainer, err := kafka.RunContainer(ctx,
-		kafka.WithClusterID("test-cluster"),
-		testcontainers.WithImage("confluentinc/confluent-local:7.6.1"),
-		network.WithNetwork([]string{"kafka"}, Network),
-		kafka.WithListener([]kafka.KafkaListener{
-			{
-				Name: "INTERNAL",
-				Ip:   "kafka",
-				Port: "9092",
-			},
+func TestKafka_saslPlain(t *testing.T) {
+	ctx := context.Background()
+
+	// kafkaWithSASLPlain {
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+		kafka.WithSASLPlain(map[string]string{
+			"alice": "alice-secret",
 		}),
 	)
 	// }
-
 	if err != nil {
-		t.Fatalf("failed to start container: %s", err)
+		t.Fatal(err)
 	}
 
-	brokers, err := KafkaContainer.Brokers(context.TODO())
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	// getSASLBrokers {
+	brokers, err := kafkaContainer.SASLBrokers(ctx)
+	// }
 	if err != nil {
-		t.Fatal("failed to get brokers", err)
+		t.Fatal(err)
 	}
 
-	createTopics(brokers, []string{topic_in, topic_out})
-
-	initKafkaTest(ctx, Network.Name, "kafka:9092", topic_in, topic_out)
-
-	// perform assertions
-
-	// set config to true because successfully delivered messages will be returned on the Successes channel
 	config := sarama.NewConfig()
+	config.Net.SASL.Enable = true
+	config.Net.SASL.Mechanism = sarama.SASLTypePlaintext
+	config.Net.SASL.User = "alice"
+	config.Net.SASL.Password = "alice-secret"
 	config.Producer.Return.Successes = true
 
 	producer, err := sarama.NewSyncProducer(brokers, config)
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	// Act
-	key := "wow"
-	text_msg := "test-input-external"
+	defer producer.Close()
 
 	if _, _, err := producer.SendMessage(&sarama.ProducerMessage{
-		Topic: topic_in,
-		Key:   sarama.StringEncoder(key),
-		Value: sarama.StringEncoder(text_msg),
+		Topic: "sasl-topic",
+		Key:   sarama.StringEncoder("key"),
+		Value: sarama.StringEncoder("value"),
 	}); err != nil {
 		t.Fatal(err)
 	}
 
-	client, err := sarama.NewConsumerGroup(brokers, "groupName", config)
-	if err != nil {
-		t.Fatal(err)
-	}
-
-	consumer, _, done, cancel := NewTestKafkaConsumer(t)
-	go func() {
-		if err := client.Consume(context.Background(), []string{topic_out}, consumer); err != nil {
-			cancel()
-		}
-	}()
-
-	// wait for the consumer to be ready
-	<-done
-
-	if consumer.message == nil {
-		t.Fatal("Empty message")
-	}
-
-	// Assert
-	if !strings.Contains(string(consumer.message.Value), text_msg) {
-		t.Error("got wrong string")
+	// wrong credentials must be rejected
+	config.Net.SASL.Password = "wrong-secret"
+	if _, err := sarama.NewSyncProducer(brokers, config); err == nil {
+		t.Fatal("expected to fail due to invalid credentials")
 	}
 }
 
-func TestKafka_listenersValidation(t *testing.T) {
+func TestKafka_saslPlainWithoutUsers(t *testing.T) {
 	ctx := context.Background()
-	var err error
 
-	_, err = kafka.RunContainer(ctx,
-		kafka.WithClusterID("test-cluster"),
-		testcontainers.WithImage("confluentinc/confluent-local:7.6.1"),
-		kafka.WithListener([]kafka.KafkaListener{
-			{
-				Name: "INTERNAL",
-				Ip:   "kafka",
-				Port: "9093",
-			},
-		}),
+	_, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+		kafka.WithSASLPlain(map[string]string{}),
 	)
-
 	if err == nil {
-		t.Fatalf("expected to fail due to reserved listener port duplication")
+		t.Fatal("expected to fail due to empty users")
 	}
+}
 
-	_, err = kafka.RunContainer(ctx,
-		kafka.WithClusterID("test-cluster"),
-		testcontainers.WithImage("confluentinc/confluent-local:7.6.1"),
-		kafka.WithListener([]kafka.KafkaListener{
-			{
-				Name: "INTERNAL",
-				Ip:   "kafka",
-				Port: "9094",
-			},
-		}),
-	)
+func TestKafka_withJAASConfigFile(t *testing.T) {
+	ctx := context.Background()
 
-	if err == nil {
-		t.Fatalf("expected to fail due to reserved listener port duplication")
+	// withJAASConfigFile {
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+		// the users are defined in the KafkaServer section of the JAAS file only
+		kafka.WithSASLPlain(nil),
+		kafka.WithJAASConfigFile("testdata/kafka_server_jaas.conf"),
+		kafka.WithEnv("KAFKA_OPTS", "-Dfoo=bar"),
+	)
+	// }
+	if err != nil {
+		t.Fatal(err)
 	}
 
-	_, err = kafka.RunContainer(ctx,
-		kafka.WithClusterID("test-cluster"),
-		testcontainers.WithImage("confluentinc/confluent-local:7.6.1"),
-		kafka.WithListener([]kafka.KafkaListener{
-			{
-				Name: "  cOnTrOller   ",
-				Ip:   "kafka",
-				Port: "9092",
-			},
-		}),
-	)
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
 
-	if err == nil {
-		t.Fatalf("expected to fail due to reserved listener name duplication")
+	inspect, err := kafkaContainer.Inspect(ctx)
+	if err != nil {
+		t.Fatal(err)
 	}
 
-	_, err = kafka.RunContainer(ctx,
-		kafka.WithClusterID("test-cluster"),
-		testcontainers.WithImage("confluentinc/confluent-local:7.6.1"),
-		kafka.WithListener([]kafka.KafkaListener{
-			{
-				Name: "external",
-				Ip:   "kafka",
-				Port: "9092",
-			},
-		}),
-	)
+	wantOpts := "KAFKA_OPTS=-Dfoo=bar -Djava.security.auth.login.config=/etc/kafka/secrets/testcontainers_jaas.conf"
+	if !slices.Contains(inspect.Config.Env, wantOpts) {
+		t.Fatalf("expected %s in the env, got %v", wantOpts, inspect.Config.Env)
+	}
 
-	if err == nil {
-		t.Fatalf("expected to fail due to reserved listener name duplication")
+	brokers, err := kafkaContainer.SASLBrokers(ctx)
+	if err != nil {
+		t.Fatal(err)
 	}
 
-	_, err = kafka.RunContainer(ctx,
-		kafka.WithClusterID("test-cluster"),
-		testcontainers.WithImage("confluentinc/confluent-local:7.6.1"),
-		kafka.WithListener([]kafka.KafkaListener{
-			{
-				Name: "test",
-				Ip:   "kafka",
-				Port: "9092",
-			},
-			{
-				Name: "test2",
-				Ip:   "kafka",
-				Port: "9092",
-			},
-		}),
-	)
+	config, err := kafkaContainer.SaramaConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.Net.SASL.User = "alice"
+	config.Net.SASL.Password = "alice-secret"
 
-	if err == nil {
-		t.Fatalf("expected to fail due to port duplication")
+	producer, err := sarama.NewSyncProducer(brokers, config)
+	if err != nil {
+		t.Fatal(err)
 	}
+	defer producer.Close()
 
-	_, err = kafka.RunContainer(ctx,
-		kafka.WithClusterID("test-cluster"),
-		testcontainers.WithImage("confluentinc/confluent-local:7.6.1"),
-		kafka.WithListener([]kafka.KafkaListener{
-			{
-				Name: "test",
-				Ip:   "kafka",
-				Port: "9092",
-			},
-			{
-				Name: "test",
-				Ip:   "kafka",
-				Port: "9095",
-			},
-		}),
-	)
+	if _, _, err := producer.SendMessage(&sarama.ProducerMessage{Topic: "jaas-topic", Value: sarama.StringEncoder("value")}); err != nil {
+		t.Fatal(err)
+	}
 
-	if err == nil {
-		t.Fatalf("expected to fail due to name duplication")
+	// wrong credentials must be rejected
+	config.Net.SASL.Password = "wrong-secret"
+	if _, err := sarama.NewSyncProducer(brokers, config); err == nil {
+		t.Fatal("expected to fail due to invalid credentials")
 	}
 }
 
-func initKafkaTest(ctx context.Context, network string, brokers string, input string, output string) (testcontainers.Container, error) {
-	req := testcontainers.ContainerRequest{
-		FromDockerfile: testcontainers.FromDockerfile{
-			Context:       "./testdata",
-			Dockerfile:    "Dockerfile",
-			PrintBuildLog: true,
-			KeepImage:     true,
+func TestKafka_scram(t *testing.T) {
+	tests := []struct {
+		mechanism string
+		saslType  sarama.SASLMechanism
+		generator func() sarama.SCRAMClient
+	}{
+		{
+			mechanism: "SCRAM-SHA-256",
+			saslType:  sarama.SASLTypeSCRAMSHA256,
+			generator: func() sarama.SCRAMClient { return &XDGSCRAMClient{HashGeneratorFcn: SHA256} },
 		},
-		WaitingFor: wait.ForLog("start consuming events"),
-		Env: map[string]string{
-			"KAFKA_BROKERS":   brokers,
-			"KAFKA_TOPIC_IN":  input,
-			"KAFKA_TOPIC_OUT": output,
+		{
+			mechanism: "SCRAM-SHA-512",
+			saslType:  sarama.SASLTypeSCRAMSHA512,
+			generator: func() sarama.SCRAMClient { return &XDGSCRAMClient{HashGeneratorFcn: SHA512} },
 		},
-		Networks: []string{network},
 	}
 
-	return testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
-		ContainerRequest: req,
-		Started:          true,
-	})
+	for _, test := range tests {
+		t.Run(test.mechanism, func(t *testing.T) {
+			ctx := context.Background()
+
+			kafkaContainer, err := kafka.RunContainer(ctx,
+				kafka.WithClusterID("kraftCluster"),
+				testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+				kafka.WithSCRAM(test.mechanism, map[string]string{
+					"alice": "alice-secret",
+				}),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			t.Cleanup(func() {
+				if err := kafkaContainer.Terminate(ctx); err != nil {
+					t.Fatalf("failed to terminate container: %s", err)
+				}
+			})
+
+			brokers, err := kafkaContainer.SASLBrokers(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			config := sarama.NewConfig()
+			config.Net.SASL.Enable = true
+			config.Net.SASL.Mechanism = test.saslType
+			config.Net.SASL.SCRAMClientGeneratorFunc = test.generator
+			config.Net.SASL.User = "alice"
+			config.Net.SASL.Password = "alice-secret"
+
+			client, err := sarama.NewConsumerGroup(brokers, "groupName", config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer client.Close()
+		})
+	}
 }
 
-func createTopics(brokers []string, topics []string) error {
-	t := &sarama.CreateTopicsRequest{}
-	t.TopicDetails = make(map[string]*sarama.TopicDetail, len(topics))
-	for _, elem := range topics {
-		t.TopicDetails[elem] = &sarama.TopicDetail{NumPartitions: 1}
+func TestKafka_scramInvalidMechanism(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+		kafka.WithSCRAM("SCRAM-SHA-1", map[string]string{
+			"alice": "alice-secret",
+		}),
+	)
+	if err == nil {
+		t.Fatal("expected to fail due to invalid mechanism")
 	}
+}
 
-	var err error
+func TestKafka_tls(t *testing.T) {
+	cert := tlscert.SelfSigned("localhost,127.0.0.1")
+	if cert == nil {
+		t.Fatal("failed to generate cert")
+	}
 
-	c, err := sarama.NewClient(brokers, sarama.NewConfig())
+	ctx := context.Background()
 
-	_, err = c.Brokers()[0].CreateTopics(t)
+	// kafkaWithTLS {
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+		kafka.WithTLS(cert.Bytes, cert.KeyBytes, nil),
+	)
+	// }
 	if err != nil {
-		return fmt.Errorf("failed to create topics: %w", err)
+		t.Fatal(err)
 	}
 
-	fmt.Println("succesfully created topics")
-
-	return nil
-}
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
 
-// assertAdvertisedListeners checks that the advertised listeners are set correctly:
-// - The INTERNAL:// protocol is using the hostname of the Kafka container
-func assertAdvertisedListeners(t *testing.T, container testcontainers.Container) {
-	hostname, err := container.Host(context.Background())
+	// getTLSBrokers {
+	brokers, err := kafkaContainer.TLSBrokers(ctx)
 	if err != nil {
 		t.Fatal(err)
 	}
-	code, r, err := container.Exec(context.Background(), []string{"cat", "/usr/sbin/testcontainers_start.sh"})
+
+	tlsConfig, err := kafkaContainer.TLSConfig()
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	if code != 0 {
-		t.Fatalf("expected exit code to be 0, got %d", code)
-	}
+	config := sarama.NewConfig()
+	config.Net.TLS.Enable = true
+	config.Net.TLS.Config = tlsConfig
+	// }
+	config.Producer.Return.Successes = true
 
-	bs, err := io.ReadAll(r)
+	producer, err := sarama.NewSyncProducer(brokers, config)
 	if err != nil {
 		t.Fatal(err)
 	}
+	defer producer.Close()
+
+	if _, _, err := producer.SendMessage(&sarama.ProducerMessage{
+		Topic: "tls-topic",
+		Key:   sarama.StringEncoder("key"),
+		Value: sarama.StringEncoder("value"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestKafka_tlsInvalidKeyPair(t *testing.T) {
+	ctx := context.Background()
 
-	if !strings.Contains(string(bs), "INTERNAL://"+hostname+":9092") {
-		t.Fatalf("expected advertised listeners to contain %s, got %s", "INTERNAL://"+hostname+":9092", string(bs))
+	_, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+		kafka.WithTLS([]byte("cert"), []byte("key"), nil),
+	)
+	if err == nil {
+		t.Fatal("expected to fail due to invalid keypair")
+	}
+}
+
+func TestKafka_createTopics(t *testing.T) {
+	ctx := context.Background()
+
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	err = kafkaContainer.CreateTopics(ctx, kafka.TopicSpec{
+		Name:       "partitioned-topic",
+		Partitions: 3,
+		Config:     map[string]string{"retention.ms": "60000"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	brokers, err := kafkaContainer.Brokers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := sarama.NewClient(brokers, sarama.NewConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	partitions, err := client.Partitions("partitioned-topic")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(partitions) != 3 {
+		t.Fatalf("expected 3 partitions, got %d", len(partitions))
+	}
+
+	// creating the same topic again must fail, naming the topic
+	err = kafkaContainer.CreateTopics(ctx, kafka.TopicSpec{Name: "partitioned-topic"})
+	if err == nil || !strings.Contains(err.Error(), "partitioned-topic") {
+		t.Fatalf("expected an error naming the topic, got %v", err)
+	}
+}
+
+func TestKafka_listAndDeleteTopics(t *testing.T) {
+	ctx := context.Background()
+
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	err = kafkaContainer.CreateTopics(ctx, kafka.TopicSpec{Name: "topic-a"}, kafka.TopicSpec{Name: "topic-b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// listTopics {
+	topics, err := kafkaContainer.ListTopics(ctx)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(topics) != 2 || topics[0] != "topic-a" || topics[1] != "topic-b" {
+		t.Fatalf("expected [topic-a topic-b], got %v", topics)
+	}
+
+	// deleteTopics {
+	err = kafkaContainer.DeleteTopics(ctx, "topic-a")
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	topics, err = kafkaContainer.ListTopics(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(topics) != 1 || topics[0] != "topic-b" {
+		t.Fatalf("expected [topic-b], got %v", topics)
+	}
+}
+
+func TestKafka_waitForTopic(t *testing.T) {
+	ctx := context.Background()
+
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	// the application creates the topic asynchronously
+	go func() {
+		time.Sleep(2 * time.Second)
+		if err := kafkaContainer.CreateTopics(ctx, kafka.TopicSpec{Name: "async-topic"}); err != nil {
+			t.Errorf("failed to create topic: %s", err)
+		}
+	}()
+
+	// waitForTopic {
+	waitCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	err = kafkaContainer.WaitForTopic(waitCtx, "async-topic")
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	absentCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	if err := kafkaContainer.WaitForTopic(absentCtx, "absent-topic"); !errors.Is(err, kafka.ErrTopicNotFound) {
+		t.Fatalf("expected topic not found, got %v", err)
+	}
+}
+
+func TestKafka_deleteTopicsDisabled(t *testing.T) {
+	ctx := context.Background()
+
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+		testcontainers.WithEnv(map[string]string{"KAFKA_DELETE_TOPIC_ENABLE": "false"}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	err = kafkaContainer.CreateTopics(ctx, kafka.TopicSpec{Name: "undeletable"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = kafkaContainer.DeleteTopics(ctx, "undeletable")
+	if !errors.Is(err, kafka.ErrTopicDeletionDisabled) {
+		t.Fatalf("expected ErrTopicDeletionDisabled, got %v", err)
+	}
+}
+
+func TestKafka_withConfig(t *testing.T) {
+	ctx := context.Background()
+
+	// withConfig {
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+		kafka.WithConfig(map[string]string{"message.max.bytes": "10485760"}),
+	)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	brokers, err := kafkaContainer.Brokers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := sarama.NewConfig()
+	config.Producer.Return.Successes = true
+	config.Producer.MaxMessageBytes = 10485760
+
+	producer, err := sarama.NewSyncProducer(brokers, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer producer.Close()
+
+	// a 2MB message exceeds the default limit of the broker
+	if _, _, err := producer.SendMessage(&sarama.ProducerMessage{
+		Topic: "large-messages",
+		Value: sarama.ByteEncoder(make([]byte, 2*1024*1024)),
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestKafka_withConfigReservedKey(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+		kafka.WithConfig(map[string]string{"advertised.listeners": "PLAINTEXT://localhost:9092"}),
+	)
+	if err == nil {
+		t.Fatal("expected error for a reserved config key, got nil")
+	}
+}
+
+func TestKafka_withServerPropertiesFile(t *testing.T) {
+	ctx := context.Background()
+
+	// withServerPropertiesFile {
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+		kafka.WithServerPropertiesFile(filepath.Join("testdata", "server.properties")),
+	)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	brokers, err := kafkaContainer.Brokers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	admin, err := sarama.NewClusterAdmin(brokers, sarama.NewConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer admin.Close()
+
+	entries, err := admin.DescribeConfig(sarama.ConfigResource{
+		Type:        sarama.BrokerResource,
+		Name:        "1",
+		ConfigNames: []string{"log.retention.ms"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 1 || entries[0].Value != "60000" {
+		t.Fatalf("expected log.retention.ms to be 60000, got %v", entries)
+	}
+}
+
+func TestKafka_withServerPropertiesFileReservedKey(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+		kafka.WithServerPropertiesFile(filepath.Join("testdata", "server-with-listeners.properties")),
+	)
+	if err == nil {
+		t.Fatal("expected an error for a file defining the advertised listeners, got nil")
+	}
+}
+
+func TestKafka_saramaConfig(t *testing.T) {
+	ctx := context.Background()
+
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.6.1"),
+		kafka.WithSCRAM("SCRAM-SHA-512", map[string]string{"alice": "alice-secret"}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	// saramaConfig {
+	config, err := kafkaContainer.SaramaConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	brokers, err := kafkaContainer.SASLBrokers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	producer, err := sarama.NewSyncProducer(brokers, config)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer producer.Close()
+
+	if config.Version != sarama.V3_6_0_0 {
+		t.Fatalf("expected version %s, got %s", sarama.V3_6_0_0, config.Version)
+	}
+
+	if _, _, err := producer.SendMessage(&sarama.ProducerMessage{
+		Topic: "sarama-config",
+		Value: sarama.StringEncoder("value"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestKafka_adminClient(t *testing.T) {
+	ctx := context.Background()
+
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.6.1"),
+		kafka.WithSCRAM("SCRAM-SHA-512", map[string]string{"alice": "alice-secret"}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	// adminClient {
+	admin, err := kafkaContainer.AdminClient(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer admin.Close()
+
+	err = admin.CreateTopic("admin-client", &sarama.TopicDetail{NumPartitions: 2, ReplicationFactor: 1}, false)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	retention := "60000"
+	if err := admin.AlterConfig(sarama.TopicResource, "admin-client", map[string]*string{"retention.ms": &retention}, false); err != nil {
+		t.Fatal(err)
+	}
+
+	metadata, err := admin.DescribeTopics([]string{"admin-client"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(metadata) != 1 || len(metadata[0].Partitions) != 2 {
+		t.Fatalf("expected the topic to have 2 partitions, got %v", metadata)
+	}
+}
+
+func TestKafka_restProxyService(t *testing.T) {
+	ctx := context.Background()
+
+	// withRestProxy {
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+		kafka.WithRestProxy(),
+	)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	err = kafkaContainer.CreateTopics(ctx, kafka.TopicSpec{Name: "rest-topic"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// getRestProxyURL {
+	restProxyURL, err := kafkaContainer.RestProxyURL(ctx)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := strings.NewReader(`{"records":[{"key":"key","value":"value"}]}`)
+	resp, err := http.Post(restProxyURL+"/topics/rest-topic", "application/vnd.kafka.json.v2+json", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	brokers, err := kafkaContainer.Brokers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	consumer, err := sarama.NewConsumer(brokers, sarama.NewConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer consumer.Close()
+
+	partitionConsumer, err := consumer.ConsumePartition("rest-topic", 0, sarama.OffsetOldest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer partitionConsumer.Close()
+
+	select {
+	case msg := <-partitionConsumer.Messages():
+		// the JSON embedded format encodes the value as a JSON string
+		if string(msg.Value) != `"value"` {
+			t.Fatalf("expected value to be %q, got %q", `"value"`, string(msg.Value))
+		}
+	case <-time.After(30 * time.Second):
+		t.Fatal("timeout consuming the record produced through the REST proxy")
+	}
+}
+
+func TestKafka_kafkaRestURL(t *testing.T) {
+	ctx := context.Background()
+
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	// without WithRestProxy, the REST proxy URL is not available
+	if _, err := kafkaContainer.RestProxyURL(ctx); !errors.Is(err, kafka.ErrRestProxyNotEnabled) {
+		t.Fatalf("expected ErrRestProxyNotEnabled, got %v", err)
+	}
+
+	// kafkaRestURL {
+	restURL, err := kafkaContainer.KafkaRestURL(ctx)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := http.Get(restURL + "/v3/clusters")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestKafka_kafkaRestURLApacheImage(t *testing.T) {
+	ctx := context.Background()
+
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("apache/kafka:3.7.0"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	if _, err := kafkaContainer.KafkaRestURL(ctx); !errors.Is(err, kafka.ErrNotSupported) {
+		t.Fatalf("expected ErrNotSupported, got %v", err)
+	}
+}
+
+func TestKafka_restProxyApacheImage(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("apache/kafka:3.7.0"),
+		kafka.WithRestProxy(),
+	)
+	if err == nil {
+		t.Fatal("expected an error enabling the REST proxy for the apache images, got nil")
+	}
+}
+
+func TestKafka_schemaRegistry(t *testing.T) {
+	ctx := context.Background()
+
+	// withSchemaRegistry {
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+		kafka.WithSchemaRegistry(),
+	)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	// getSchemaRegistryURL {
+	schemaRegistryURL, err := kafkaContainer.SchemaRegistryURL(ctx)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := strings.NewReader(`{"schema": "{\"type\": \"string\"}"}`)
+	resp, err := http.Post(schemaRegistryURL+"/subjects/test-value/versions", "application/vnd.schemaregistry.v1+json", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	resp, err = http.Get(schemaRegistryURL + "/subjects")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	subjects, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(subjects), "test-value") {
+		t.Fatalf("expected the subjects to contain test-value, got %s", string(subjects))
+	}
+}
+
+func TestKafka_withConnect(t *testing.T) {
+	ctx := context.Background()
+
+	// withConnect {
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+		kafka.WithConnect([]string{"confluentinc/kafka-connect-datagen:0.6.5"}),
+	)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	// getConnectURL {
+	connectURL, err := kafkaContainer.ConnectURL(ctx)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := strings.NewReader(`{
+		"name": "datagen-users",
+		"config": {
+			"connector.class": "io.confluent.kafka.connect.datagen.DatagenConnector",
+			"kafka.topic": "users",
+			"quickstart": "users",
+			"max.interval": "100",
+			"tasks.max": "1"
+		}
+	}`)
+	resp, err := http.Post(connectURL+"/connectors", "application/json", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status code %d, got %d", http.StatusCreated, resp.StatusCode)
+	}
+
+	// the connector produces to the users topic of the broker
+	brokers, err := kafkaContainer.Brokers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := kafkaContainer.SaramaConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	consumer, err := sarama.NewConsumer(brokers, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer consumer.Close()
+
+	// the topic is created with the first message of the connector
+	var partitionConsumer sarama.PartitionConsumer
+	deadline := time.Now().Add(time.Minute)
+	for partitionConsumer == nil {
+		partitionConsumer, err = consumer.ConsumePartition("users", 0, sarama.OffsetOldest)
+		if err != nil && time.Now().After(deadline) {
+			t.Fatalf("failed to consume the users topic: %s", err)
+		}
+
+		time.Sleep(time.Second)
+	}
+	defer partitionConsumer.Close()
+
+	select {
+	case <-partitionConsumer.Messages():
+	case <-time.After(time.Minute):
+		t.Fatal("expected a message produced by the connector")
+	}
+}
+
+func TestKafka_connectNotEnabled(t *testing.T) {
+	ctx := context.Background()
+
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	if _, err := kafkaContainer.ConnectURL(ctx); !errors.Is(err, kafka.ErrConnectNotEnabled) {
+		t.Fatalf("expected ErrConnectNotEnabled, got %v", err)
+	}
+}
+
+func TestKafka_logDir(t *testing.T) {
+	ctx := context.Background()
+
+	images := []string{
+		"confluentinc/confluent-local:7.5.0",
+		"apache/kafka:3.7.0",
+	}
+
+	for _, image := range images {
+		t.Run(image, func(t *testing.T) {
+			kafkaContainer, err := kafka.RunContainer(ctx,
+				kafka.WithClusterID("kraftCluster"),
+				testcontainers.WithImage(image),
+				kafka.WithInitialTopics(kafka.TopicSpec{Name: "orders", Partitions: 1}),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			t.Cleanup(func() {
+				if err := kafkaContainer.Terminate(ctx); err != nil {
+					t.Fatalf("failed to terminate container: %s", err)
+				}
+			})
+
+			// logDir {
+			r, err := kafkaContainer.CopyFileFromContainer(ctx, kafkaContainer.LogDir()+"/orders-0/00000000000000000000.log")
+			// }
+			if err != nil {
+				t.Fatal(err)
+			}
+			r.Close()
+
+			// the storage formatted by the module is in the same directory
+			r, err = kafkaContainer.CopyFileFromContainer(ctx, kafkaContainer.LogDir()+"/meta.properties")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer r.Close()
+
+			content, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !strings.Contains(string(content), "cluster.id="+kafkaContainer.ClusterID) {
+				t.Fatalf("expected the meta.properties of the cluster %s, got %s", kafkaContainer.ClusterID, content)
+			}
+		})
+	}
+}
+
+func TestKafka_withDataVolume(t *testing.T) {
+	ctx := context.Background()
+
+	volumeName := "kafka-data-" + uuid.NewString()
+
+	run := func() *kafka.KafkaContainer {
+		// withDataVolume {
+		kafkaContainer, err := kafka.RunContainer(ctx,
+			kafka.WithClusterID("kraftCluster"),
+			testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+			kafka.WithDataVolume(volumeName),
+		)
+		// }
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return kafkaContainer
+	}
+
+	kafkaContainer := run()
+
+	brokers, err := kafkaContainer.Brokers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := sarama.NewConfig()
+	config.Producer.Return.Successes = true
+
+	producer, err := sarama.NewSyncProducer(brokers, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := producer.SendMessage(&sarama.ProducerMessage{
+		Topic: "persistent-topic",
+		Value: sarama.StringEncoder("persisted"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := producer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := kafkaContainer.Terminate(ctx); err != nil {
+		t.Fatalf("failed to terminate container: %s", err)
+	}
+
+	// a new container, started against the same volume, recovers the messages
+	kafkaContainer = run()
+
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	brokers, err = kafkaContainer.Brokers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	consumer, err := sarama.NewConsumer(brokers, sarama.NewConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer consumer.Close()
+
+	partitionConsumer, err := consumer.ConsumePartition("persistent-topic", 0, sarama.OffsetOldest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer partitionConsumer.Close()
+
+	select {
+	case msg := <-partitionConsumer.Messages():
+		if string(msg.Value) != "persisted" {
+			t.Fatalf("expected value to be %s, got %s", "persisted", string(msg.Value))
+		}
+	case <-time.After(30 * time.Second):
+		t.Fatal("timeout consuming the message persisted in the volume")
+	}
+}
+
+func TestKafka_withEnv(t *testing.T) {
+	ctx := context.Background()
+
+	// withEnv {
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+		kafka.WithEnv("KAFKA_HEAP_OPTS", "-Xmx512m -Xms512m"),
+	)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	inspect, err := kafkaContainer.Inspect(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found := false
+	for _, env := range inspect.Config.Env {
+		if env == "KAFKA_HEAP_OPTS=-Xmx512m -Xms512m" {
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		t.Fatalf("expected KAFKA_HEAP_OPTS to be set, got %v", inspect.Config.Env)
+	}
+}
+
+func TestKafka_withEnvReservedKey(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+		kafka.WithEnv("KAFKA_ADVERTISED_LISTENERS", "PLAINTEXT://localhost:9092"),
+	)
+	if err == nil {
+		t.Fatal("expected an error for a reserved env, got nil")
+	}
+}
+
+func TestKafka_withJMX(t *testing.T) {
+	ctx := context.Background()
+
+	// withJMX {
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+		kafka.WithJMX(9999),
+	)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	// jmxEndpoint {
+	endpoint, err := kafkaContainer.JMXEndpoint(ctx)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn, err := net.DialTimeout("tcp", endpoint, 5*time.Second)
+	if err != nil {
+		t.Fatalf("failed to connect to the JMX endpoint %s: %s", endpoint, err)
+	}
+	defer conn.Close()
+
+	inspect, err := kafkaContainer.Inspect(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found := false
+	for _, env := range inspect.Config.Env {
+		if env == "KAFKA_JMX_HOSTNAME="+inspect.Config.Hostname {
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		t.Fatalf("expected KAFKA_JMX_HOSTNAME to be the container hostname %s, got %v", inspect.Config.Hostname, inspect.Config.Env)
+	}
+}
+
+func TestKafka_jmxNotEnabled(t *testing.T) {
+	ctx := context.Background()
+
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	if _, err := kafkaContainer.JMXEndpoint(ctx); !errors.Is(err, kafka.ErrJMXNotEnabled) {
+		t.Fatalf("expected ErrJMXNotEnabled, got %v", err)
+	}
+}
+
+func TestKafka_refreshBrokers(t *testing.T) {
+	ctx := context.Background()
+
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	if _, err := kafkaContainer.Brokers(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// the cached brokers are dropped when the container is restarted, as its ports are mapped again
+	timeout := 10 * time.Second
+	if err := kafkaContainer.Stop(ctx, &timeout); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := kafkaContainer.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	brokers, err := kafkaContainer.Brokers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	port, err := kafkaContainer.MappedPort(ctx, "9093/tcp")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasSuffix(brokers[0], ":"+port.Port()) {
+		t.Fatalf("expected the brokers of the mapped port %s, got %v", port.Port(), brokers)
+	}
+
+	// refreshBrokers {
+	brokers, err = kafkaContainer.RefreshBrokers(ctx)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasSuffix(brokers[0], ":"+port.Port()) {
+		t.Fatalf("expected the brokers of the mapped port %s, got %v", port.Port(), brokers)
+	}
+}
+
+func TestKafka_withJMXExporter(t *testing.T) {
+	ctx := context.Background()
+
+	// withJMXExporter {
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+		kafka.WithJMXExporter("lowercaseOutputName: true\nrules:\n- pattern: \".*\"\n"),
+	)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	// metricsURL {
+	metricsURL, err := kafkaContainer.MetricsURL(ctx)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := http.Get(metricsURL)
+	if err != nil {
+		t.Fatalf("failed to scrape the metrics endpoint %s: %s", metricsURL, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", resp.StatusCode, body)
+	}
+
+	if !strings.Contains(string(body), "kafka_server_brokertopicmetrics") {
+		t.Fatalf("expected the broker topic metrics to be exported, got %s", body)
+	}
+
+	// the command line tools run in the container do not start the agent, which would fail to bind its port
+	code, _, err := kafkaContainer.Exec(ctx, []string{"kafka-topics", "--bootstrap-server", "localhost:9092", "--list"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if code != 0 {
+		t.Fatalf("expected kafka-topics to succeed, got exit code %d", code)
+	}
+}
+
+func TestKafka_jmxExporterEmptyConfig(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+		kafka.WithJMXExporter(""),
+	)
+	if err == nil {
+		t.Fatal("expected an error for an empty JMX exporter config, got nil")
+	}
+}
+
+func TestKafka_withExposedController(t *testing.T) {
+	ctx := context.Background()
+
+	// withExposedController {
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("apache/kafka:3.7.0"),
+		kafka.WithExposedController(),
+	)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	// getControllerEndpoint {
+	endpoint, err := kafkaContainer.ControllerEndpoint(ctx)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, mappedPort, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	port, err := strconv.Atoi(mappedPort)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// describe the quorum from another container, reaching the exposed endpoint through the host
+	tool, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:      "apache/kafka:3.7.0",
+			Entrypoint: []string{"/opt/kafka/bin/kafka-metadata-quorum.sh"},
+			Cmd: []string{
+				"--bootstrap-controller", fmt.Sprintf("%s:%d", testcontainers.HostInternal, port),
+				"describe", "--status",
+			},
+			HostAccessPorts: []int{port},
+			WaitingFor:      wait.ForExit(),
+		},
+		Started: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := tool.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	logs, err := tool.Logs(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logs.Close()
+
+	output, err := io.ReadAll(logs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(output), "LeaderId:") {
+		t.Fatalf("expected the status of the quorum, got %s", output)
+	}
+}
+
+func TestKafka_controllerNotExposed(t *testing.T) {
+	ctx := context.Background()
+
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	if _, err := kafkaContainer.ControllerEndpoint(ctx); !errors.Is(err, kafka.ErrControllerNotExposed) {
+		t.Fatalf("expected ErrControllerNotExposed, got %v", err)
+	}
+
+	ports, err := kafkaContainer.Ports(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := ports["9094/tcp"]; ok {
+		t.Fatal("expected the controller port not to be published")
+	}
+}
+
+func TestKafka_withDefaultPartitions(t *testing.T) {
+	ctx := context.Background()
+
+	// withDefaultPartitions {
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+		kafka.WithDefaultPartitions(6),
+	)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	brokers, err := kafkaContainer.Brokers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := sarama.NewConfig()
+	config.Producer.Return.Successes = true
+
+	producer, err := sarama.NewSyncProducer(brokers, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer producer.Close()
+
+	// the topic is created automatically on the first produce
+	if _, _, err := producer.SendMessage(&sarama.ProducerMessage{
+		Topic: "auto-created",
+		Value: sarama.StringEncoder("value"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := sarama.NewClient(brokers, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	partitions, err := client.Partitions("auto-created")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(partitions) != 6 {
+		t.Fatalf("expected 6 partitions, got %d", len(partitions))
+	}
+}
+
+func TestKafka_withDefaultReplicationFactorTooHigh(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+		kafka.WithDefaultReplicationFactor(3),
+	)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestKafka_consumerGroupWithoutTopicSetup(t *testing.T) {
+	images := []string{
+		"confluentinc/confluent-local:7.5.0",
+		"apache/kafka:3.7.0",
+	}
+
+	for _, image := range images {
+		t.Run(image, func(t *testing.T) {
+			ctx := context.Background()
+
+			// withOffsetsTopicReplicationFactor {
+			kafkaContainer, err := kafka.RunContainer(ctx,
+				kafka.WithClusterID("kraftCluster"),
+				testcontainers.WithImage(image),
+				kafka.WithOffsetsTopicReplicationFactor(1),
+			)
+			// }
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			t.Cleanup(func() {
+				if err := kafkaContainer.Terminate(ctx); err != nil {
+					t.Fatalf("failed to terminate container: %s", err)
+				}
+			})
+
+			brokers, err := kafkaContainer.Brokers(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// the group joins right away, creating the offsets topic and the consumed topic
+			config := sarama.NewConfig()
+			group, err := sarama.NewConsumerGroup(brokers, "fresh-group", config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer group.Close()
+
+			consumer, ready, _, cancel := NewTestKafkaConsumer(t)
+			consumeCtx, stop := context.WithTimeout(ctx, time.Minute)
+			defer stop()
+
+			go func() {
+				if err := group.Consume(consumeCtx, []string{"fresh-topic"}, consumer); err != nil {
+					t.Errorf("failed to consume: %s", err)
+				}
+			}()
+
+			select {
+			case <-ready:
+			case <-consumeCtx.Done():
+				t.Fatal("the consumer group did not join in time")
+			}
+			cancel()
+		})
+	}
+}
+
+func TestKafka_withAutoCreateTopicsDisabled(t *testing.T) {
+	ctx := context.Background()
+
+	// withAutoCreateTopics {
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+		kafka.WithAutoCreateTopics(false),
+	)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	brokers, err := kafkaContainer.Brokers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := sarama.NewConfig()
+	config.Producer.Return.Successes = true
+	config.Producer.Retry.Max = 0
+	config.Metadata.Retry.Max = 0
+
+	producer, err := sarama.NewSyncProducer(brokers, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer producer.Close()
+
+	_, _, err = producer.SendMessage(&sarama.ProducerMessage{
+		Topic: "nonexistent",
+		Value: sarama.StringEncoder("value"),
+	})
+	if !errors.Is(err, sarama.ErrUnknownTopicOrPartition) {
+		t.Fatalf("expected ErrUnknownTopicOrPartition, got %v", err)
+	}
+}
+
+func TestKafka_withAuthorizer(t *testing.T) {
+	ctx := context.Background()
+
+	// withAuthorizer {
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+		kafka.WithSASLPlain(map[string]string{
+			"admin": "admin-secret",
+			"alice": "alice-secret",
+		}),
+		kafka.WithAuthorizer([]string{"User:admin"}),
+	)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	if err := kafkaContainer.CreateTopics(ctx, kafka.TopicSpec{Name: "allowed"}, kafka.TopicSpec{Name: "denied"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// createACL {
+	err = kafkaContainer.CreateACL(ctx, kafka.ACLBinding{
+		ResourceType: sarama.AclResourceTopic,
+		ResourceName: "allowed",
+		Principal:    "User:alice",
+		Operation:    sarama.AclOperationWrite,
+	})
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	brokers, err := kafkaContainer.SASLBrokers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := sarama.NewConfig()
+	config.Net.SASL.Enable = true
+	config.Net.SASL.Mechanism = sarama.SASLTypePlaintext
+	config.Net.SASL.User = "alice"
+	config.Net.SASL.Password = "alice-secret"
+	config.Producer.Return.Successes = true
+	config.Producer.Retry.Max = 0
+
+	producer, err := sarama.NewSyncProducer(brokers, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer producer.Close()
+
+	if _, _, err := producer.SendMessage(&sarama.ProducerMessage{
+		Topic: "allowed",
+		Value: sarama.StringEncoder("value"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err = producer.SendMessage(&sarama.ProducerMessage{
+		Topic: "denied",
+		Value: sarama.StringEncoder("value"),
+	})
+	if !errors.Is(err, sarama.ErrTopicAuthorizationFailed) {
+		t.Fatalf("expected ErrTopicAuthorizationFailed, got %v", err)
+	}
+}
+
+func TestKafka_withAdvertisedHost(t *testing.T) {
+	ctx := context.Background()
+
+	// withAdvertisedHost {
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+		kafka.WithAdvertisedHost("127.0.0.1"),
+	)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	brokers, err := kafkaContainer.Brokers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(brokers) != 1 || !strings.HasPrefix(brokers[0], "127.0.0.1:") {
+		t.Fatalf("expected the advertised host in the brokers, got %v", brokers)
+	}
+
+	config := sarama.NewConfig()
+	config.Producer.Return.Successes = true
+
+	producer, err := sarama.NewSyncProducer(brokers, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer producer.Close()
+
+	if _, _, err := producer.SendMessage(&sarama.ProducerMessage{
+		Topic: "advertised-host",
+		Value: sarama.StringEncoder("value"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// syncBuffer is a buffer safe for concurrent use, as the logs are written from a goroutine.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestKafka_followLogs(t *testing.T) {
+	ctx := context.Background()
+
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	// followLogs {
+	logsCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var logs syncBuffer
+	err = kafkaContainer.FollowLogs(logsCtx, &logs)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the logs are followed since the start of the container
+	deadline := time.Now().Add(10 * time.Second)
+	for !strings.Contains(logs.String(), "Transitioning from RECOVERY to RUNNING") {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the startup logs, got %s", logs.String())
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	cancel()
+	time.Sleep(time.Second)
+	followed := len(logs.String())
+
+	// the broker logs the creation of the topic, which must not be written after the cancellation
+	if err := kafkaContainer.CreateTopics(ctx, kafka.TopicSpec{Name: "after-cancel"}); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Second)
+
+	if len(logs.String()) != followed {
+		t.Fatalf("expected no logs after the cancellation, got %s", logs.String()[followed:])
+	}
+}
+
+func TestKafka_gracefulShutdown(t *testing.T) {
+	ctx := context.Background()
+
+	// withGracefulShutdownTimeout {
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+		kafka.WithGracefulShutdownTimeout(30*time.Second),
+	)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logsCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var logs syncBuffer
+	if err := kafkaContainer.FollowLogs(logsCtx, &logs); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := kafkaContainer.Terminate(ctx); err != nil {
+		t.Fatalf("failed to terminate container: %s", err)
+	}
+
+	// the broker completed its controlled shutdown before the container was removed
+	if !strings.Contains(logs.String(), "shut down completed") {
+		t.Fatalf("expected the controlled shutdown logs, got %s", logs.String())
+	}
+}
+
+func TestKafka_withReuse(t *testing.T) {
+	ctx := context.Background()
+
+	name := "testcontainers-kafka-" + uuid.NewString()
+
+	// withReuse {
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+		kafka.WithReuse(name),
+	)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	// another run, e.g. from another test binary, returns the running container
+	reused, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("otherCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+		kafka.WithReuse(name),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if reused.GetContainerID() != kafkaContainer.GetContainerID() {
+		t.Fatalf("expected container %s to be reused, got %s", kafkaContainer.GetContainerID(), reused.GetContainerID())
+	}
+
+	if reused.ClusterID != kafkaContainer.ClusterID {
+		t.Fatalf("expected cluster id %s, got %s", kafkaContainer.ClusterID, reused.ClusterID)
+	}
+
+	brokers, err := kafkaContainer.Brokers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reusedBrokers, err := reused.Brokers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(brokers, ",") != strings.Join(reusedBrokers, ",") {
+		t.Fatalf("expected brokers %v, got %v", brokers, reusedBrokers)
+	}
+
+	if err := reused.CreateTopics(ctx, kafka.TopicSpec{Name: "reused"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestKafka_withStartupScriptHook(t *testing.T) {
+	ctx := context.Background()
+
+	// withStartupScriptHook {
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+		kafka.WithStartupScriptHook([]string{
+			"echo 'running the startup script hook'",
+			"echo ready > /tmp/testcontainers_hook",
+		}),
+	)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	code, r, err := kafkaContainer.Exec(ctx, []string{"cat", "/tmp/testcontainers_hook"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if code != 0 {
+		t.Fatalf("expected the file written by the hook, got exit code %d", code)
+	}
+
+	output, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(output), "ready") {
+		t.Fatalf("expected the content written by the hook, got %s", output)
+	}
+}
+
+func TestKafka_withStartupScriptHookExec(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+		kafka.WithStartupScriptHook([]string{"exec sleep infinity"}),
+	)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestKafka_roundTrip(t *testing.T) {
+	ctx := context.Background()
+
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	roundTripCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	// roundTrip {
+	value, err := kafkaContainer.RoundTrip(roundTripCtx, "smoke", []byte("key"), []byte("hello"))
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(value) != "hello" {
+		t.Fatalf("expected hello, got %s", value)
+	}
+
+	// the topic already exists, and the message of the previous round trip is skipped
+	value, err = kafkaContainer.RoundTrip(roundTripCtx, "smoke", []byte("key"), []byte("again"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(value) != "again" {
+		t.Fatalf("expected again, got %s", value)
+	}
+
+	brokers, err := kafkaContainer.Brokers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	admin, err := sarama.NewClusterAdmin(brokers, sarama.NewConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer admin.Close()
+
+	groups, err := admin.ListConsumerGroups()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(groups) != 0 {
+		t.Fatalf("expected the consumer groups to be deleted, got %v", groups)
+	}
+}
+
+func TestKafka_withClientQuota(t *testing.T) {
+	ctx := context.Background()
+
+	// withClientQuota {
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+		kafka.WithClientQuota("<default>", 1024, 2048),
+		kafka.WithClientQuota("User:alice", 4096, 0),
+	)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	brokers, err := kafkaContainer.Brokers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := sarama.NewConfig()
+	config.Version = sarama.V3_5_0_0
+
+	admin, err := sarama.NewClusterAdmin(brokers, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer admin.Close()
+
+	entries, err := admin.DescribeClientQuotas([]sarama.QuotaFilterComponent{{EntityType: sarama.QuotaEntityUser, MatchType: sarama.QuotaMatchAny}}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	quotas := map[string]map[string]float64{}
+	for _, entry := range entries {
+		name := entry.Entity[0].Name
+		if entry.Entity[0].MatchType == sarama.QuotaMatchDefault {
+			name = "<default>"
+		}
+		quotas[name] = entry.Values
+	}
+
+	expected := map[string]map[string]float64{
+		"<default>": {"producer_byte_rate": 1024, "consumer_byte_rate": 2048},
+		"alice":     {"producer_byte_rate": 4096},
+	}
+	if !reflect.DeepEqual(quotas, expected) {
+		t.Fatalf("expected quotas %v, got %v", expected, quotas)
+	}
+}
+
+func TestKafka_withClientQuotaNegativeRate(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+		kafka.WithClientQuota("alice", -1, 1024),
+	)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestKafka_withCompression(t *testing.T) {
+	ctx := context.Background()
+
+	// withCompression {
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+		kafka.WithCompression("zstd"),
+	)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	// the uncompressed message is stored compressed, and decompressed by the consumer
+	value, err := kafkaContainer.RoundTrip(ctx, "compressed", nil, []byte(strings.Repeat("compressible ", 100)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(value) != strings.Repeat("compressible ", 100) {
+		t.Fatalf("expected the produced value, got %s", value)
+	}
+
+	brokers, err := kafkaContainer.Brokers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	admin, err := sarama.NewClusterAdmin(brokers, sarama.NewConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer admin.Close()
+
+	entries, err := admin.DescribeConfig(sarama.ConfigResource{
+		Type:        sarama.TopicResource,
+		Name:        "compressed",
+		ConfigNames: []string{"compression.type"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 1 || entries[0].Value != "zstd" {
+		t.Fatalf("expected the topic to be compressed with zstd, got %v", entries)
+	}
+}
+
+func TestKafka_withCompressionProducer(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+		kafka.WithCompression("producer"),
+	)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestKafka_configMap(t *testing.T) {
+	ctx := context.Background()
+
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.6.1"),
+		kafka.WithSASLPlain(map[string]string{"alice": "alice-secret"}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	// bootstrapServers {
+	servers, err := kafkaContainer.BootstrapServers(ctx)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	brokers, err := kafkaContainer.Brokers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if servers != strings.Join(brokers, ",") {
+		t.Fatalf("expected %v, got %s", brokers, servers)
+	}
+
+	// configMap {
+	config, err := kafkaContainer.ConfigMap(ctx)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	saslBrokers, err := kafkaContainer.SASLBrokers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if config["bootstrap.servers"] != strings.Join(saslBrokers, ",") {
+		t.Fatalf("expected the SASL brokers %v, got %s", saslBrokers, config["bootstrap.servers"])
+	}
+
+	if config["sasl.mechanisms"] != "PLAIN" || config["sasl.username"] != "alice" || config["sasl.password"] != "alice-secret" {
+		t.Fatalf("expected the credentials of alice, got %v", config)
+	}
+}
+
+func TestKafka_withLogLevel(t *testing.T) {
+	ctx := context.Background()
+
+	// withLogLevel {
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+		kafka.WithLogLevel("WARN"),
+	)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	logs, err := kafkaContainer.Logs(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logs.Close()
+
+	output, err := io.ReadAll(logs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the INFO lines of the other loggers, like the config of the broker, are not logged
+	if strings.Contains(string(output), "KafkaConfig values") {
+		t.Fatal("expected the INFO lines of the broker config not to be logged")
+	}
+}
+
+func TestKafka_withTransactions(t *testing.T) {
+	ctx := context.Background()
+
+	// withTransactions {
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+		kafka.WithTransactions(),
+	)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	brokers, err := kafkaContainer.Brokers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := kafkaContainer.SaramaConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.Producer.Idempotent = true
+	config.Producer.Transaction.ID = "testcontainers-txn"
+	config.Net.MaxOpenRequests = 1
+	config.Consumer.IsolationLevel = sarama.ReadCommitted
+
+	producer, err := sarama.NewSyncProducer(brokers, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer producer.Close()
+
+	if err := producer.BeginTxn(); err != nil {
+		t.Fatal(err)
+	}
+
+	_, offset, err := producer.SendMessage(&sarama.ProducerMessage{Topic: "transactions", Value: sarama.StringEncoder("committed")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := producer.CommitTxn(); err != nil {
+		t.Fatal(err)
+	}
+
+	consumer, err := sarama.NewConsumer(brokers, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer consumer.Close()
+
+	partitionConsumer, err := consumer.ConsumePartition("transactions", 0, offset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer partitionConsumer.Close()
+
+	select {
+	case msg := <-partitionConsumer.Messages():
+		if string(msg.Value) != "committed" {
+			t.Fatalf("expected the committed message, got %s", string(msg.Value))
+		}
+	case <-time.After(30 * time.Second):
+		t.Fatal("expected the committed message to be consumed with the read committed isolation level")
+	}
+}
+
+func TestKafka_withIdempotenceReady(t *testing.T) {
+	ctx := context.Background()
+
+	// withIdempotenceReady {
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("apache/kafka:3.7.0"),
+		kafka.WithIdempotenceReady(),
+	)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	brokers, err := kafkaContainer.Brokers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := kafkaContainer.SaramaConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.Producer.Idempotent = true
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Net.MaxOpenRequests = 1
+
+	producer, err := sarama.NewSyncProducer(brokers, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer producer.Close()
+
+	for i := 0; i < 10; i++ {
+		if _, _, err := producer.SendMessage(&sarama.ProducerMessage{Topic: "idempotent", Value: sarama.StringEncoder(strconv.Itoa(i))}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	admin, err := kafkaContainer.AdminClient(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer admin.Close()
+
+	entries, err := admin.DescribeConfig(sarama.ConfigResource{Type: sarama.BrokerResource, Name: "1", ConfigNames: []string{"min.insync.replicas"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 1 || entries[0].Value != "1" {
+		t.Fatalf("expected min.insync.replicas to be 1, got %v", entries)
+	}
+}
+
+func TestKafka_withIdempotenceReadyReservedConfig(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("apache/kafka:3.7.0"),
+		kafka.WithIdempotenceReady(),
+		kafka.WithConfig(map[string]string{"min.insync.replicas": "2"}),
+	)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestKafka_withFiles(t *testing.T) {
+	ctx := context.Background()
+
+	// withFiles {
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.6.1"),
+		kafka.WithFiles(testcontainers.ContainerFile{
+			Reader:            strings.NewReader("KafkaServer {};"),
+			ContainerFilePath: "/etc/kafka/secrets/custom_jaas.conf",
+			FileMode:          0o644,
+		}),
+		// the file is there before the broker is launched
+		kafka.WithStartupScriptHook([]string{"test -f /etc/kafka/secrets/custom_jaas.conf || exit 1"}),
+	)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	r, err := kafkaContainer.CopyFileFromContainer(ctx, "/etc/kafka/secrets/custom_jaas.conf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	content, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(content) != "KafkaServer {};" {
+		t.Fatalf("expected the copied file content, got %s", string(content))
+	}
+}
+
+func TestKafka_withFilesManagedPath(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.6.1"),
+		kafka.WithFiles(testcontainers.ContainerFile{
+			Reader:            strings.NewReader("#!/bin/bash"),
+			ContainerFilePath: "/usr/sbin/testcontainers_start.sh",
+			FileMode:          0o755,
+		}),
+	)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestKafka_apiVersions(t *testing.T) {
+	ctx := context.Background()
+
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	// apiVersions {
+	versions, err := kafkaContainer.APIVersions(ctx)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Kafka 3.5 supports the versions 0 to 9 of the Produce API
+	if produce := versions[0]; produce.Min > 0 || produce.Max < 9 {
+		t.Fatalf("expected the versions 0 to 9 of the Produce API to be supported, got %+v", produce)
+	}
+}
+
+func TestKafka_describeConfig(t *testing.T) {
+	ctx := context.Background()
+
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+		kafka.WithConfig(map[string]string{"message.max.bytes": "10485760"}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	// describeConfig {
+	config, err := kafkaContainer.DescribeConfig(ctx)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if config["message.max.bytes"] != "10485760" {
+		t.Fatalf("expected message.max.bytes to be 10485760, got %q", config["message.max.bytes"])
+	}
+
+	admin, err := kafkaContainer.AdminClient(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer admin.Close()
+
+	value := "120000"
+	if err := admin.AlterConfig(sarama.BrokerResource, "1", map[string]*string{"log.cleaner.backoff.ms": &value}, false); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := kafkaContainer.DescribeConfigEntries(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sources := make(map[string]sarama.ConfigSource, len(entries))
+	for _, entry := range entries {
+		sources[entry.Name] = entry.Source
+	}
+
+	if sources["message.max.bytes"] != sarama.SourceStaticBroker {
+		t.Fatalf("expected message.max.bytes to be a static entry, got %s", sources["message.max.bytes"])
+	}
+
+	if sources["log.cleaner.backoff.ms"] != sarama.SourceDynamicBroker {
+		t.Fatalf("expected log.cleaner.backoff.ms to be a dynamic entry, got %s", sources["log.cleaner.backoff.ms"])
+	}
+}
+
+func TestKafka_producerConsumerGroup(t *testing.T) {
+	ctx := context.Background()
+
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+		kafka.WithSASLPlain(map[string]string{"alice": "alice-secret"}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	// producerConsumerGroup {
+	producer, err := kafkaContainer.NewSyncProducer(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer producer.Close()
+
+	group, err := kafkaContainer.NewConsumerGroup(ctx, "orders-consumers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer group.Close()
+	// }
+
+	consumer, ready, done, cancel := NewTestKafkaConsumer(t)
+	consumeCtx, stop := context.WithTimeout(ctx, time.Minute)
+	defer stop()
+
+	go func() {
+		if err := group.Consume(consumeCtx, []string{"orders"}, consumer); err != nil {
+			t.Errorf("failed to consume: %s", err)
+		}
+	}()
+
+	select {
+	case <-ready:
+	case <-consumeCtx.Done():
+		t.Fatal("the consumer group did not join in time")
+	}
+	defer cancel()
+
+	if _, _, err := producer.SendMessage(&sarama.ProducerMessage{
+		Topic: "orders",
+		Value: sarama.StringEncoder("order-1"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-done:
+	case <-consumeCtx.Done():
+		t.Fatal("the message was not consumed in time")
+	}
+
+	if string(consumer.message.Value) != "order-1" {
+		t.Fatalf("expected value to be order-1, got %s", string(consumer.message.Value))
+	}
+}
+
+func TestKafka_withInitialTopics(t *testing.T) {
+	ctx := context.Background()
+
+	// withInitialTopics {
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+		kafka.WithInitialTopics(
+			kafka.TopicSpec{Name: "orders", Partitions: 3},
+			kafka.TopicSpec{Name: "payments", Config: map[string]string{"retention.ms": "60000"}},
+		),
+	)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	topics, err := kafkaContainer.ListTopics(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(topics, []string{"orders", "payments"}) {
+		t.Fatalf("expected the initial topics, got %v", topics)
+	}
+}
+
+func TestKafka_withInitialTopicsError(t *testing.T) {
+	ctx := context.Background()
+
+	// a single broker cannot replicate the topic
+	_, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+		kafka.WithInitialTopics(kafka.TopicSpec{Name: "orders", ReplicationFactor: 3}),
+	)
+	if err == nil {
+		t.Fatal("expected an error creating the initial topics, got nil")
+	}
+
+	if !strings.Contains(err.Error(), "broker logs:") {
+		t.Fatalf("expected the error to include the broker logs, got %s", err)
+	}
+}
+
+func TestKafka_withNodeIDAndBrokerPort(t *testing.T) {
+	ctx := context.Background()
+
+	// withNodeIDAndBrokerPort {
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+		kafka.WithNodeID(7),
+		kafka.WithBrokerPort("19092"),
+	)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	brokers, err := kafkaContainer.Brokers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := sarama.NewClient(brokers, sarama.NewConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	controller, err := client.Controller()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if controller.ID() != 7 {
+		t.Fatalf("expected node id 7, got %d", controller.ID())
+	}
+
+	inspect, err := kafkaContainer.Inspect(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found := false
+	for _, env := range inspect.Config.Env {
+		if env == "KAFKA_LISTENERS=EXTERNAL://0.0.0.0:9093,INTERNAL://0.0.0.0:19092,CONTROLLER://0.0.0.0:9094" {
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		t.Fatalf("expected the internal listener on port 19092, got %v", inspect.Config.Env)
+	}
+}
+
+func TestKafka_withBrokerPortReserved(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+		kafka.WithBrokerPort("9094"),
+	)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestKafka_networkConnectivity(t *testing.T) {
+	ctx := context.Background()
+	var err error
+
+	const (
+		topic_in  = "topic_in"
+		topic_out = "topic_out"
+	)
+
+	Network, err := network.New(ctx, network.WithCheckDuplicate())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// kafkaWithListener {
+	KafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("test-cluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.6.1"),
+		network.WithNetwork([]string{"kafka"}, Network),
+		kafka.WithListener([]kafka.KafkaListener{
+			{
+				Name: "INTERNAL",
+				Ip:   "kafka",
+				Port: "9092",
+			},
+		}),
+	)
+	// }
+
+	if err != nil {
+		t.Fatalf("failed to start container: %s", err)
+	}
+
+	// networkIP {
+	ip, err := KafkaContainer.NetworkIP(ctx, Network.Name)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if net.ParseIP(ip) == nil {
+		t.Fatalf("expected the IP address of the container in the network, got %q", ip)
+	}
+
+	brokers, err := KafkaContainer.Brokers(context.TODO())
+	if err != nil {
+		t.Fatal("failed to get brokers", err)
+	}
+
+	// createTopics {
+	err = KafkaContainer.CreateTopics(ctx,
+		kafka.TopicSpec{Name: topic_in},
+		kafka.TopicSpec{Name: topic_out},
+	)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	initKafkaTest(ctx, Network.Name, "kafka:9092", topic_in, topic_out)
+
+	// perform assertions
+
+	// set config to true because successfully delivered messages will be returned on the Successes channel
+	config := sarama.NewConfig()
+	config.Producer.Return.Successes = true
+
+	producer, err := sarama.NewSyncProducer(brokers, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	key := "wow"
+	text_msg := "test-input-external"
+
+	if _, _, err := producer.SendMessage(&sarama.ProducerMessage{
+		Topic: topic_in,
+		Key:   sarama.StringEncoder(key),
+		Value: sarama.StringEncoder(text_msg),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	messages, err := KafkaContainer.WaitForMessages(waitCtx, topic_out, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Assert
+	if !strings.Contains(string(messages[0].Value), text_msg) {
+		t.Error("got wrong string")
+	}
+}
+
+func TestKafka_waitForMessages(t *testing.T) {
+	ctx := context.Background()
+
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.6.1"),
+		kafka.WithInitialTopics(kafka.TopicSpec{Name: "messages", Partitions: 3}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	brokers, err := kafkaContainer.Brokers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := kafkaContainer.SaramaConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	producer, err := sarama.NewSyncProducer(brokers, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer producer.Close()
+
+	produce := func(values ...string) {
+		for _, value := range values {
+			if _, _, err := producer.SendMessage(&sarama.ProducerMessage{Topic: "messages", Value: sarama.StringEncoder(value)}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	produce("first", "second", "third")
+
+	waitCtx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	// waitForMessages {
+	messages, err := kafkaContainer.WaitForMessages(waitCtx, "messages", 3)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values := make([]string, 0, len(messages))
+	for _, msg := range messages {
+		values = append(values, string(msg.Value))
+	}
+	sort.Strings(values)
+
+	if !reflect.DeepEqual(values, []string{"first", "second", "third"}) {
+		t.Fatalf("expected the produced messages, got %v", values)
+	}
+
+	t.Run("from latest offset", func(t *testing.T) {
+		latest := make(chan []*sarama.ConsumerMessage, 1)
+		go func() {
+			// waitForMessagesFromLatest {
+			messages, err := kafkaContainer.WaitForMessages(waitCtx, "messages", 1, kafka.FromLatestOffset())
+			// }
+			if err != nil {
+				t.Errorf("failed to wait for messages: %s", err)
+			}
+			latest <- messages
+		}()
+
+		// the latest offsets are resolved when the call starts, so keep producing until it returns
+		ticker := time.NewTicker(500 * time.Millisecond)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case messages := <-latest:
+				if len(messages) != 1 || string(messages[0].Value) != "fourth" {
+					t.Fatalf("expected only the fourth message, got %v", messages)
+				}
+				return
+			case <-ticker.C:
+				produce("fourth")
+			}
+		}
+	})
+
+	t.Run("context done", func(t *testing.T) {
+		shortCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+		defer cancel()
+
+		messages, err := kafkaContainer.WaitForMessages(shortCtx, "messages", 100)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected deadline exceeded, got %v", err)
+		}
+
+		// the messages received so far are returned
+		if len(messages) < 3 {
+			t.Fatalf("expected at least the 3 produced messages, got %d", len(messages))
+		}
+	})
+}
+
+func TestKafka_multipleNetworks(t *testing.T) {
+	ctx := context.Background()
+
+	producersNetwork, err := network.New(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := producersNetwork.Remove(ctx); err != nil {
+			t.Fatalf("failed to remove network: %s", err)
+		}
+	})
+
+	consumersNetwork, err := network.New(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := consumersNetwork.Remove(ctx); err != nil {
+			t.Fatalf("failed to remove network: %s", err)
+		}
+	})
+
+	// withMultipleNetworks {
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+		network.WithNetwork([]string{"kafka-producers"}, producersNetwork),
+		network.WithNetwork([]string{"kafka-consumers"}, consumersNetwork),
+		kafka.WithListener([]kafka.KafkaListener{
+			{Name: "PRODUCERS", Ip: "kafka-producers", Port: "9092"},
+			{Name: "CONSUMERS", Ip: "kafka-consumers", Port: "9192"},
+		}),
+	)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	if err := kafkaContainer.CreateTopics(ctx, kafka.TopicSpec{Name: "networks"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// runTool runs the command in a container attached to the network only, returning its output
+	runTool := func(networkName string, cmd string) string {
+		tool, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+			ContainerRequest: testcontainers.ContainerRequest{
+				Image:      "confluentinc/confluent-local:7.5.0",
+				Entrypoint: []string{"bash", "-c"},
+				Cmd:        []string{cmd},
+				Networks:   []string{networkName},
+				WaitingFor: wait.ForExit().WithExitTimeout(time.Minute),
+			},
+			Started: true,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() {
+			if err := tool.Terminate(ctx); err != nil {
+				t.Fatalf("failed to terminate tool container: %s", err)
+			}
+		}()
+
+		logs, err := tool.Logs(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer logs.Close()
+
+		output, err := io.ReadAll(logs)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return string(output)
+	}
+
+	runTool(producersNetwork.Name, "echo from-producers | kafka-console-producer --bootstrap-server kafka-producers:9092 --topic networks")
+
+	output := runTool(consumersNetwork.Name, "kafka-console-consumer --bootstrap-server kafka-consumers:9192 --topic networks --from-beginning --max-messages 1 --timeout-ms 30000")
+	if !strings.Contains(output, "from-producers") {
+		t.Fatalf("expected the consumer network to receive the message of the producer network, got %s", output)
+	}
+}
+
+func TestKafka_listeners(t *testing.T) {
+	ctx := context.Background()
+
+	nw, err := network.New(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := nw.Remove(ctx); err != nil {
+			t.Fatalf("failed to remove network: %s", err)
+		}
+	})
+
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("test-cluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.6.1"),
+		network.WithNetwork([]string{"kafka"}, nw),
+		kafka.WithListener([]kafka.KafkaListener{
+			{Name: "BROKER", Ip: "kafka", Port: "9092"},
+		}),
+		kafka.WithSASLPlain(map[string]string{"admin": "admin-secret"}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	// getListeners {
+	listeners, err := kafkaContainer.Listeners(ctx)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(listeners) != 3 {
+		t.Fatalf("expected 3 listeners, got %v", listeners)
+	}
+
+	broker := listeners[0]
+	if broker.Name != "BROKER" || broker.Ip != "kafka" || broker.Port != "9092" || !broker.Internal || broker.HostPort != "" {
+		t.Fatalf("expected the internal BROKER listener, got %+v", broker)
+	}
+
+	brokers, err := kafkaContainer.Brokers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	external := listeners[1]
+	if external.Name != "EXTERNAL" || external.Internal || brokers[0] != external.Ip+":"+external.HostPort {
+		t.Fatalf("expected the external listener on %s, got %+v", brokers[0], external)
+	}
+
+	saslBrokers, err := kafkaContainer.SASLBrokers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sasl := listeners[2]
+	if sasl.Protocol != "SASL_PLAINTEXT" || saslBrokers[0] != sasl.Ip+":"+sasl.HostPort {
+		t.Fatalf("expected the SASL listener on %s, got %+v", saslBrokers[0], sasl)
+	}
+}
+
+func TestKafka_withListenerProtocol(t *testing.T) {
+	ctx := context.Background()
+
+	cert := tlscert.SelfSigned("kafka")
+	if cert == nil {
+		t.Fatal("failed to generate cert")
+	}
+
+	// the PEM keystores only support the PKCS #8 keys
+	block, _ := pem.Decode(cert.KeyBytes)
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pkcs8Key, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keystore := append(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8Key}), cert.Bytes...)
+
+	nw, err := network.New(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := nw.Remove(ctx); err != nil {
+			t.Fatalf("failed to remove network: %s", err)
+		}
+	})
+
+	// withListenerProtocol {
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("test-cluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.6.1"),
+		network.WithNetwork([]string{"kafka"}, nw),
+		kafka.WithListener([]kafka.KafkaListener{
+			{Name: "BROKER", Ip: "kafka", Port: "9092"},
+			{Name: "SECURE", Ip: "kafka", Port: "9095", Protocol: "SSL"},
+		}),
+		kafka.WithFiles(
+			testcontainers.ContainerFile{Reader: bytes.NewReader(keystore), ContainerFilePath: "/tmp/secure.keystore.pem", FileMode: 0o644},
+			testcontainers.ContainerFile{Reader: bytes.NewReader(cert.Bytes), ContainerFilePath: "/tmp/secure.truststore.pem", FileMode: 0o644},
+		),
+		kafka.WithConfig(map[string]string{
+			"listener.name.secure.ssl.keystore.type":     "PEM",
+			"listener.name.secure.ssl.keystore.location": "/tmp/secure.keystore.pem",
+		}),
+	)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	listeners, err := kafkaContainer.Listeners(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if secure := listeners[1]; secure.Name != "SECURE" || secure.Protocol != "SSL" {
+		t.Fatalf("expected the SSL listener SECURE, got %+v", secure)
+	}
+
+	// a client in the network of the broker connects to the SSL listener, trusting its certificate
+	clientConfig := "security.protocol=SSL\nssl.truststore.type=PEM\nssl.truststore.location=/tmp/secure.truststore.pem\n"
+	if err := kafkaContainer.CopyToContainer(ctx, []byte(clientConfig), "/tmp/secure-client.properties", 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	code, r, err := kafkaContainer.Exec(ctx, []string{
+		"kafka-broker-api-versions", "--bootstrap-server", "kafka:9095", "--command-config", "/tmp/secure-client.properties",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if code != 0 {
+		output, _ := io.ReadAll(r)
+		t.Fatalf("expected the client to connect to the SSL listener, got exit code %d: %s", code, output)
+	}
+}
+
+func TestKafka_withInterBrokerListener(t *testing.T) {
+	ctx := context.Background()
+
+	nw, err := network.New(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := nw.Remove(ctx); err != nil {
+			t.Fatalf("failed to remove network: %s", err)
+		}
+	})
+
+	// withInterBrokerListener {
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("test-cluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.6.1"),
+		network.WithNetwork([]string{"kafka"}, nw),
+		kafka.WithListener([]kafka.KafkaListener{
+			{Name: "CLIENTS", Ip: "kafka", Port: "9092"},
+			{Name: "REPLICATION", Ip: "kafka", Port: "9097"},
+		}),
+		kafka.WithSASLPlain(map[string]string{"admin": "admin-secret"}),
+		kafka.WithInterBrokerListener("REPLICATION"),
+	)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	inspect, err := kafkaContainer.Inspect(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found := false
+	for _, env := range inspect.Config.Env {
+		if env == "KAFKA_INTER_BROKER_LISTENER_NAME=REPLICATION" {
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		t.Fatalf("expected the REPLICATION inter-broker listener, got %v", inspect.Config.Env)
+	}
+
+	value, err := kafkaContainer.RoundTrip(ctx, "inter-broker", nil, []byte("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(value) != "hello" {
+		t.Fatalf("expected hello, got %s", value)
+	}
+}
+
+func TestKafka_withInterBrokerListenerUnknown(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("test-cluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.6.1"),
+		kafka.WithInterBrokerListener("BROKER"),
+	)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestKafka_listenersValidation(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		listeners []kafka.KafkaListener
+		index     int
+		field     string
+		reason    kafka.ListenerValidationReason
+	}{
+		{
+			name:      "Reserved external port",
+			listeners: []kafka.KafkaListener{{Name: "INTERNAL", Ip: "kafka", Port: "9093"}},
+			field:     "Port",
+			reason:    kafka.ReservedPort,
+		},
+		{
+			name:      "Reserved controller port",
+			listeners: []kafka.KafkaListener{{Name: "INTERNAL", Ip: "kafka", Port: "9094"}},
+			field:     "Port",
+			reason:    kafka.ReservedPort,
+		},
+		{
+			name:      "Reserved controller name",
+			listeners: []kafka.KafkaListener{{Name: "  cOnTrOller   ", Ip: "kafka", Port: "9092"}},
+			field:     "Name",
+			reason:    kafka.ReservedName,
+		},
+		{
+			name:      "Reserved external name",
+			listeners: []kafka.KafkaListener{{Name: "external", Ip: "kafka", Port: "9092"}},
+			field:     "Name",
+			reason:    kafka.ReservedName,
+		},
+		{
+			name: "Duplicate port",
+			listeners: []kafka.KafkaListener{
+				{Name: "test", Ip: "kafka", Port: "9092"},
+				{Name: "test2", Ip: "kafka", Port: "9092"},
+			},
+			index:  1,
+			field:  "Port",
+			reason: kafka.DuplicatePort,
+		},
+		{
+			name: "Duplicate name",
+			listeners: []kafka.KafkaListener{
+				{Name: "test", Ip: "kafka", Port: "9092"},
+				{Name: "test", Ip: "kafka", Port: "9095"},
+			},
+			index:  1,
+			field:  "Name",
+			reason: kafka.DuplicateName,
+		},
+		{
+			name:      "Invalid port",
+			listeners: []kafka.KafkaListener{{Name: "test", Ip: "kafka", Port: "0"}},
+			field:     "Port",
+			reason:    kafka.InvalidPort,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := kafka.RunContainer(ctx,
+				kafka.WithClusterID("test-cluster"),
+				testcontainers.WithImage("confluentinc/confluent-local:7.6.1"),
+				kafka.WithListener(test.listeners),
+			)
+
+			// listenerValidationError {
+			var validationErr *kafka.ListenerValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("expected a ListenerValidationError, got %v", err)
+			}
+			// }
+
+			if validationErr.Index != test.index || validationErr.Field != test.field || validationErr.Reason != test.reason {
+				t.Fatalf("expected the listener %d, the field %s and the reason %q, got the listener %d, the field %s and the reason %q",
+					test.index, test.field, test.reason, validationErr.Index, validationErr.Field, validationErr.Reason)
+			}
+		})
+	}
+}
+
+func initKafkaTest(ctx context.Context, network string, brokers string, input string, output string) (testcontainers.Container, error) {
+	req := testcontainers.ContainerRequest{
+		FromDockerfile: testcontainers.FromDockerfile{
+			Context:       "./testdata",
+			Dockerfile:    "Dockerfile",
+			PrintBuildLog: true,
+			KeepImage:     true,
+		},
+		WaitingFor: wait.ForLog("start consuming events"),
+		Env: map[string]string{
+			"KAFKA_BROKERS":   brokers,
+			"KAFKA_TOPIC_IN":  input,
+			"KAFKA_TOPIC_OUT": output,
+		},
+		Networks: []string{network},
+	}
+
+	return testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+	})
+}
+
+// assertAdvertisedListeners checks that the advertised listeners are set correctly:
+// - The INTERNAL:// protocol is using the hostname of the Kafka container
+func assertAdvertisedListeners(t *testing.T, container *kafka.KafkaContainer) {
+	hostname, err := container.Host(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// startupScript {
+	script := container.StartupScript()
+	// }
+
+	if !strings.Contains(script, "INTERNAL://"+hostname+":9092") {
+		t.Fatalf("expected advertised listeners to contain %s, got %s", "INTERNAL://"+hostname+":9092", script)
+	}
+
+	// the script is the one copied into the container
+	r, err := container.CopyFileFromContainer(context.Background(), "/usr/sbin/testcontainers_start.sh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	bs, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(bs) != script {
+		t.Fatalf("expected the script in the container to be %s, got %s", script, string(bs))
+	}
+}
+
+func TestKafka_request(t *testing.T) {
+	ctx := context.Background()
+
+	// kafkaRequest {
+	req, err := kafka.NewRequest("confluentinc/confluent-local:7.5.0").
+		WithClusterID("kraftCluster").
+		WithConfig(map[string]string{"auto.create.topics.enable": "true"}).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// tweak the request before starting the broker, e.g. mounting a tmpfs
+	req.Tmpfs = map[string]string{"/tmp/scratch": "rw"}
+
+	container, err := testcontainers.GenericContainer(ctx, req)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := container.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	code, _, err := container.Exec(ctx, []string{"sh", "-c", "grep -q ' /tmp/scratch tmpfs ' /proc/mounts"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if code != 0 {
+		t.Fatalf("expected /tmp/scratch to be a tmpfs mount, got exit code %d", code)
+	}
+
+	// the external listener is reachable on the port mapped to 9093/tcp
+	endpoint, err := container.PortEndpoint(ctx, "9093/tcp", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := sarama.NewConfig()
+	config.Producer.Return.Successes = true
+
+	producer, err := sarama.NewSyncProducer([]string{endpoint}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer producer.Close()
+
+	if _, _, err := producer.SendMessage(&sarama.ProducerMessage{Topic: "request-topic", Value: sarama.StringEncoder("value")}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestKafka_withFixedBrokerPort(t *testing.T) {
+	ctx := context.Background()
+
+	// pick a free port of the host
+	l, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hostPort := l.Addr().(*net.TCPAddr).Port
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// withFixedBrokerPort {
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+		kafka.WithFixedBrokerPort(hostPort),
+	)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	brokers, err := kafkaContainer.Brokers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasSuffix(brokers[0], ":"+strconv.Itoa(hostPort)) {
+		t.Fatalf("expected the broker on the port %d, got %s", hostPort, brokers[0])
+	}
+
+	assertAdvertisedListeners(t, kafkaContainer)
+
+	config, err := kafkaContainer.SaramaConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	producer, err := sarama.NewSyncProducer(brokers, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer producer.Close()
+
+	if _, _, err := producer.SendMessage(&sarama.ProducerMessage{Topic: "fixed-port", Value: sarama.StringEncoder("value")}); err != nil {
+		t.Fatal(err)
+	}
+
+	// a second broker cannot bind the same port
+	_, err = kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+		kafka.WithFixedBrokerPort(hostPort),
+	)
+	if err == nil || !strings.Contains(err.Error(), "already in use") {
+		t.Fatalf("expected the port to be already in use, got %v", err)
+	}
+}
+
+func TestKafka_resetConsumerGroupOffsets(t *testing.T) {
+	for _, image := range []string{"confluentinc/confluent-local:7.5.0", "apache/kafka:3.7.0"} {
+		t.Run(image, func(t *testing.T) {
+			ctx := context.Background()
+
+			kafkaContainer, err := kafka.RunContainer(ctx, kafka.WithClusterID("kraftCluster"), testcontainers.WithImage(image))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			t.Cleanup(func() {
+				if err := kafkaContainer.Terminate(ctx); err != nil {
+					t.Fatalf("failed to terminate container: %s", err)
+				}
+			})
+
+			brokers, err := kafkaContainer.Brokers(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			config, err := kafkaContainer.SaramaConfig()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			producer, err := sarama.NewSyncProducer(brokers, config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer producer.Close()
+
+			for i := 0; i < 3; i++ {
+				if _, _, err := producer.SendMessage(&sarama.ProducerMessage{Topic: "resettable", Value: sarama.StringEncoder(strconv.Itoa(i))}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// commit the offset of the consumed messages, as a previous run of the test would
+			client, err := sarama.NewClient(brokers, config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer client.Close()
+
+			offsetManager, err := sarama.NewOffsetManagerFromClient("reset-group", client)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			partitionManager, err := offsetManager.ManagePartition("resettable", 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			partitionManager.MarkOffset(3, "")
+			offsetManager.Commit()
+			partitionManager.AsyncClose()
+			if err := offsetManager.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			// the offsets of an active group cannot be reset
+			group, err := sarama.NewConsumerGroup(brokers, "reset-group", config)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			consumer, ready, _, cancel := NewTestKafkaConsumer(t)
+			go func() {
+				if err := group.Consume(ctx, []string{"resettable"}, consumer); err != nil {
+					cancel()
+				}
+			}()
+
+			// wait for the consumer to join the group
+			<-ready
+
+			err = kafkaContainer.ResetConsumerGroupOffsets(ctx, "reset-group", "resettable", true)
+			if !errors.Is(err, kafka.ErrConsumerGroupActive) {
+				t.Fatalf("expected the group to be active, got %v", err)
+			}
+
+			cancel()
+			if err := group.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			// resetConsumerGroupOffsets {
+			err = kafkaContainer.ResetConsumerGroupOffsets(ctx, "reset-group", "resettable", true)
+			// }
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			admin, err := kafkaContainer.AdminClient(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer admin.Close()
+
+			offsets, err := admin.ListConsumerGroupOffsets("reset-group", map[string][]int32{"resettable": {0}})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if block := offsets.GetBlock("resettable", 0); block == nil || block.Offset != 0 {
+				t.Fatalf("expected the offset to be reset to 0, got %v", block)
+			}
+		})
+	}
+}
+
+func TestKafka_withSeedData(t *testing.T) {
+	ctx := context.Background()
+
+	// withSeedData {
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+		kafka.WithInitialTopics(kafka.TopicSpec{Name: "fixtures", Partitions: 3}),
+		kafka.WithSeedData("fixtures", [][]byte{
+			[]byte("first"),
+			[]byte("second"),
+			[]byte("third"),
+		}),
+	)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	messages, err := kafkaContainer.WaitForMessages(ctx, "fixtures", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the messages are stored in order, in the first partition
+	for i, want := range []string{"first", "second", "third"} {
+		if string(messages[i].Value) != want || messages[i].Partition != 0 {
+			t.Fatalf("expected message %d to be %s in the partition 0, got %s in the partition %d", i, want, messages[i].Value, messages[i].Partition)
+		}
+	}
+}
+
+func TestKafka_withSeedDataFailure(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := kafka.RunContainer(ctx,
+		kafka.WithClusterID("kraftCluster"),
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+		kafka.WithSeedData("invalid topic!", [][]byte{[]byte("first")}),
+	)
+	if err == nil {
+		t.Fatal("expected to fail due to the invalid topic")
+	}
+
+	if !strings.Contains(err.Error(), "seed message 0 of invalid topic!") || !strings.Contains(err.Error(), "broker logs:") {
+		t.Fatalf("expected the seed error with the broker logs, got %s", err)
+	}
+}
+
+func TestKafka_withZookeeper(t *testing.T) {
+	ctx := context.Background()
+
+	// withZookeeper {
+	kafkaContainer, err := kafka.RunContainer(ctx,
+		testcontainers.WithImage("confluentinc/cp-kafka:7.5.0"),
+		kafka.WithZookeeper(),
+	)
+	// }
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	if kafkaContainer.Mode() != kafka.ModeZookeeper {
+		t.Fatalf("expected the %s mode, got %s", kafka.ModeZookeeper, kafkaContainer.Mode())
+	}
+
+	assertAdvertisedListeners(t, kafkaContainer)
+
+	brokers, err := kafkaContainer.Brokers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := kafkaContainer.SaramaConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	producer, err := sarama.NewSyncProducer(brokers, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer producer.Close()
+
+	if _, _, err := producer.SendMessage(&sarama.ProducerMessage{Topic: "zookeeper-topic", Value: sarama.StringEncoder("value")}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the broker registers itself in ZooKeeper, which KRaft brokers don't use
+	code, _, err := kafkaContainer.Exec(ctx, []string{"zookeeper-shell", "zookeeper:2181", "get", "/brokers/ids/1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if code != 0 {
+		t.Fatalf("expected the broker to be registered in ZooKeeper, got exit code %d", code)
+	}
+}
+
+func TestKafka_withZookeeperKRaftOnlyImage(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := kafka.RunContainer(ctx,
+		testcontainers.WithImage("confluentinc/confluent-local:7.5.0"),
+		kafka.WithZookeeper(),
+	)
+	if err == nil || !strings.Contains(err.Error(), "KRaft-only image") {
+		t.Fatalf("expected to fail due to the KRaft-only image, got %v", err)
 	}
 }
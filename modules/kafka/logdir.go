@@ -0,0 +1,84 @@
+package kafka
+
+import (
+	"os"
+	"strings"
+)
+
+const (
+	// defaultLogDir is the data directory of the confluent-local and apache/kafka brokers, if not configured
+	defaultLogDir = "/tmp/kraft-combined-logs"
+	// defaultCPKafkaLogDir is the data directory of the cp-kafka brokers, used in ZooKeeper mode, if not configured
+	defaultCPKafkaLogDir = "/var/lib/kafka/data"
+)
+
+// brokerLogDir returns the data directory of the broker, i.e. the first directory of the log.dirs broker
+// config, falling back to log.dir, as the broker does. The server.properties file, appended to the config
+// generated from the environment, takes precedence over the environment, which includes the entries of
+// WithConfig and WithEnv, and the data volume. The default directory of the image is returned if none is set.
+func brokerLogDir(env map[string]string, serverProperties []byte, image string) string {
+	dirs := env["KAFKA_LOG_DIRS"]
+	if dirs == "" {
+		dirs = env["KAFKA_LOG_DIR"]
+	}
+
+	if value, ok := propertiesValue(serverProperties, "log.dirs"); ok {
+		dirs = value
+	} else if value, ok := propertiesValue(serverProperties, "log.dir"); ok && dirs == "" {
+		dirs = value
+	}
+
+	if dir, _, _ := strings.Cut(dirs, ","); strings.TrimSpace(dir) != "" {
+		return strings.TrimSpace(dir)
+	}
+
+	repository, _ := splitImage(image)
+	if strings.HasSuffix(repository, "confluentinc/cp-kafka") {
+		return defaultCPKafkaLogDir
+	}
+
+	return defaultLogDir
+}
+
+// propertiesValue returns the value of the last definition of the key in the content of a Java properties file,
+// as the last one wins when the file is loaded. Keys are separated from the values by '=', ':' or whitespace.
+func propertiesValue(content []byte, key string) (string, bool) {
+	var value string
+	var found bool
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+			continue
+		}
+
+		idx := strings.IndexAny(line, "=: \t")
+		if idx < 0 || line[:idx] != key {
+			continue
+		}
+
+		value = strings.TrimLeft(strings.TrimSpace(line[idx:]), "=:")
+		value, found = strings.TrimSpace(value), true
+	}
+
+	return value, found
+}
+
+// serverPropertiesContent returns the content of the server.properties file, if any. The file was read by
+// validateServerProperties already, so an error reading it again is ignored, leaving the default directory.
+func serverPropertiesContent(settings options) []byte {
+	if settings.ServerPropertiesFile == "" {
+		return nil
+	}
+
+	content, _ := os.ReadFile(settings.ServerPropertiesFile)
+	return content
+}
+
+// LogDir returns the path, in the container, of the data directory of the broker, where the segment files of
+// the partitions are stored, e.g. to copy them with CopyFileFromContainer or to assert on the data layout.
+// It reflects the log.dirs config, set with WithConfig, WithEnv or WithServerPropertiesFile, and the data
+// directory of WithDataVolume, and it's the default directory of the image otherwise. If several directories
+// are configured, the first one is returned.
+func (kc *KafkaContainer) LogDir() string {
+	return kc.logDir
+}
@@ -0,0 +1,77 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// logWriter is the log consumer writing the logs of the broker into a writer, until its context is cancelled.
+type logWriter struct {
+	ctx context.Context
+	w   io.Writer
+}
+
+// Accept writes the content of the log into the writer, unless the context is cancelled.
+func (lw logWriter) Accept(l testcontainers.Log) {
+	if lw.ctx.Err() != nil {
+		return
+	}
+
+	_, _ = lw.w.Write(l.Content)
+}
+
+// FollowLogs tails the stdout and stderr of the broker into the writer, since the start of the
+// container, which is handy to surface why a broker failed to start up. It's built on the log
+// producer of the container, which it starts, so it cannot be combined with the log consumers
+// of the request. It returns once the logs are followed, writing them until the context is
+// cancelled, which stops the log producer, so the writer is not used after that.
+func (kc *KafkaContainer) FollowLogs(ctx context.Context, w io.Writer) error {
+	kc.FollowOutput(logWriter{ctx: ctx, w: w})
+
+	if err := kc.StartLogProducer(ctx); err != nil {
+		return fmt.Errorf("start log producer: %w", err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		_ = kc.StopLogProducer()
+	}()
+
+	return nil
+}
+
+// initialTopicsLogLines is the number of lines of the broker logs included in the error
+// returned when the initial topics cannot be created
+const initialTopicsLogLines = 50
+
+// brokerLogsTail returns the last lines of the logs of the container, prefixed with a header, to be
+// appended to the errors of the operations failing at startup. As it's only meant to explain another
+// error, it returns a note instead if the logs cannot be read.
+func brokerLogsTail(ctx context.Context, c testcontainers.Container, lines int) string {
+	rc, err := c.Logs(ctx)
+	if err != nil {
+		return fmt.Sprintf("broker logs unavailable: %s", err)
+	}
+	defer rc.Close()
+
+	content, err := io.ReadAll(rc)
+	if err != nil {
+		return fmt.Sprintf("broker logs unavailable: %s", err)
+	}
+
+	return "broker logs:\n" + lastLines(string(content), lines)
+}
+
+// lastLines returns the last n lines of the text, without the trailing new line.
+func lastLines(text string, n int) string {
+	all := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	if len(all) > n {
+		all = all[len(all)-n:]
+	}
+
+	return strings.Join(all, "\n")
+}
@@ -0,0 +1,109 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/IBM/sarama"
+)
+
+// WaitForMessagesOption is a type that can be used to configure the WaitForMessages call.
+type WaitForMessagesOption func(*waitForMessagesOptions)
+
+type waitForMessagesOptions struct {
+	FromLatest bool
+}
+
+// FromLatestOffset makes WaitForMessages skip the messages already in the topic, waiting only for the
+// messages produced after the call, instead of consuming the topic from the earliest offset.
+func FromLatestOffset() WaitForMessagesOption {
+	return func(o *waitForMessagesOptions) {
+		o.FromLatest = true
+	}
+}
+
+// WaitForMessages consumes all the partitions of the topic until count messages are received, or until
+// the context is done, returning the messages in the order they were received. The topic is consumed
+// from the earliest offset, unless the FromLatestOffset option is passed, without any consumer group,
+// so no offset is committed. The client is configured with SaramaConfig, and connects to the brokers
+// of the matching listener. If the context is done first, the messages received so far are returned
+// along with the error.
+func (kc *KafkaContainer) WaitForMessages(ctx context.Context, topic string, count int, opts ...WaitForMessagesOption) ([]*sarama.ConsumerMessage, error) {
+	settings := waitForMessagesOptions{}
+	for _, opt := range opts {
+		opt(&settings)
+	}
+
+	if count <= 0 {
+		return nil, fmt.Errorf("invalid message count: %d", count)
+	}
+
+	config, err := kc.SaramaConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	brokers, err := kc.clientBrokers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	consumer, err := sarama.NewConsumer(brokers, config)
+	if err != nil {
+		return nil, fmt.Errorf("new consumer: %w", err)
+	}
+	defer consumer.Close()
+
+	partitions, err := consumer.Partitions(topic)
+	if err != nil {
+		return nil, fmt.Errorf("partitions of %s: %w", topic, err)
+	}
+
+	offset := sarama.OffsetOldest
+	if settings.FromLatest {
+		offset = sarama.OffsetNewest
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	received := make(chan *sarama.ConsumerMessage)
+	for _, partition := range partitions {
+		partitionConsumer, err := consumer.ConsumePartition(topic, partition, offset)
+		if err != nil {
+			return nil, fmt.Errorf("consume partition %d of %s: %w", partition, topic, err)
+		}
+		defer partitionConsumer.Close()
+
+		go func() {
+			for {
+				select {
+				case msg, ok := <-partitionConsumer.Messages():
+					if !ok {
+						return
+					}
+
+					select {
+					case received <- msg:
+					case <-ctx.Done():
+						return
+					}
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	messages := make([]*sarama.ConsumerMessage, 0, count)
+	for len(messages) < count {
+		select {
+		case msg := <-received:
+			messages = append(messages, msg)
+		case <-ctx.Done():
+			return messages, fmt.Errorf("wait for %d messages on %s, received %d: %w", count, topic, len(messages), ctx.Err())
+		}
+	}
+
+	return messages, nil
+}
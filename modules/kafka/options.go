@@ -2,6 +2,12 @@ package kafka
 
 import (
 	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/docker/go-connections/nat"
 
 	"github.com/testcontainers/testcontainers-go"
 )
@@ -10,6 +16,157 @@ type options struct {
 	// Listeners is a list of custom listeners that can be provided to access the
 	// containers form within docker networks
 	Listeners []KafkaListener
+
+	// SASL is the SASL configuration for the dedicated SASL listener.
+	// It's nil if SASL authentication is not enabled.
+	SASL *saslConfig
+
+	// TLS is the PEM material for the dedicated SSL listener.
+	// It's nil if TLS is not enabled.
+	TLS *tlsConfig
+
+	// Config is a map of broker configuration properties, e.g. "message.max.bytes",
+	// that are injected as environment variables before the container starts.
+	Config map[string]string
+
+	// ServerPropertiesFile is the path to a server.properties file on the host,
+	// which is appended to the broker config generated by the module.
+	ServerPropertiesFile string
+
+	// JAASConfigFile is the path to a JAAS file on the host, passed to the JVM of the broker.
+	JAASConfigFile string
+
+	// Files are the files copied into the container before it starts, e.g. keytabs or truststores.
+	Files []testcontainers.ContainerFile
+
+	// RestProxy enables the REST proxy bundled in the confluent images.
+	RestProxy bool
+
+	// SchemaRegistry starts a Schema Registry container pointed at the broker.
+	SchemaRegistry bool
+
+	// Connect starts a Kafka Connect worker container pointed at the broker.
+	Connect bool
+
+	// ConnectPlugins are the Confluent Hub coordinates of the plugins installed in the Kafka Connect worker.
+	ConnectPlugins []string
+
+	// DataVolume is the name of the Docker volume mounted at the data directory of the broker.
+	DataVolume string
+
+	// Env is a map of environment variables set on top of the ones managed by the module.
+	Env map[string]string
+
+	// JMXPort is the port of the JMX agent of the broker. It's zero if JMX is not enabled.
+	JMXPort int
+
+	// JMXExporter is the config of the Prometheus JMX exporter of the broker.
+	// It's nil if the JMX exporter is not enabled.
+	JMXExporter *jmxExporterConfig
+
+	// BrokerCount is the number of brokers started by RunCluster.
+	BrokerCount int
+
+	// DefaultPartitions is the number of partitions of the auto-created topics. It's zero if not set.
+	DefaultPartitions int
+
+	// DefaultReplicationFactor is the replication factor of the auto-created topics,
+	// including the internal ones. It's zero if not set.
+	DefaultReplicationFactor int
+
+	// OffsetsTopicReplicationFactor is the replication factor of the internal consumer offsets topic,
+	// overriding the default replication factor for it. It's zero if not set.
+	OffsetsTopicReplicationFactor int
+
+	// AutoCreateTopics enables or disables the automatic creation of topics by the broker.
+	// It's nil if not set, keeping the default of the image, which is enabled.
+	AutoCreateTopics *bool
+
+	// Authorizer enables the standard KRaft ACL authorizer on the broker.
+	Authorizer bool
+
+	// SuperUsers are the principals allowed to perform any operation when the authorizer is enabled.
+	SuperUsers []string
+
+	// AdvertisedHost is the host advertised by the listeners mapped to the host, i.e. the external,
+	// SASL and TLS ones. It's empty if not set, advertising the host of the container.
+	AdvertisedHost string
+
+	// NodeID is the KRaft node id of the broker. It's zero if not set, using the node id 1.
+	NodeID int
+
+	// BrokerPort is the port of the default internal listener. It's empty if not set, using the port 9092.
+	BrokerPort string
+
+	// FixedBrokerPort is the host port the external listener is bound to. It's zero if not set,
+	// binding it to a random port.
+	FixedBrokerPort int
+
+	// Zookeeper runs the broker in ZooKeeper mode, next to a ZooKeeper container, instead of the KRaft mode.
+	Zookeeper bool
+
+	// ExposedController publishes the port of the controller listener.
+	ExposedController bool
+
+	// GracefulShutdownTimeout bounds the wait for the controlled shutdown of the broker
+	// on Terminate. It's zero if the graceful shutdown is disabled.
+	GracefulShutdownTimeout time.Duration
+
+	// ReuseName is the name of the container reused across test binaries. It's empty if the
+	// container is not reused.
+	ReuseName string
+
+	// StartupScriptHook are the shell lines run by the starter script before launching the broker.
+	StartupScriptHook []string
+
+	// InterBrokerListener is the name of the listener used for the inter-broker traffic. It's empty
+	// if not set, using the first custom listener, or the default internal one.
+	InterBrokerListener string
+
+	// ClientQuotas are the byte rate quotas of the clients, set once the broker is ready.
+	ClientQuotas []clientQuota
+
+	// InitialTopics are the topics created once the broker is ready, before the container is returned.
+	InitialTopics []TopicSpec
+
+	// SeedData are the messages produced once the initial topics are created, before the container is returned.
+	SeedData []seedData
+
+	// Transactions configures the transaction state log for the transactional producers.
+	Transactions bool
+
+	// IdempotenceReady configures the broker for the idempotent producers.
+	IdempotenceReady bool
+
+	// Compression is the compression codec of the topics. It's empty if not set, keeping the codec of the producers.
+	Compression string
+
+	// LogLevel is the level of the root logger of the broker. It's empty if not set, using the level of the image.
+	LogLevel string
+
+	// clusterSize is the number of brokers of the cluster the container belongs to,
+	// set by RunCluster. It's zero for a single broker.
+	clusterSize int
+
+	// podman is whether the container is created by the Podman provider, which changes the host
+	// advertised by the listeners mapped to the host when the tests run in a Podman container.
+	podman bool
+}
+
+// tlsConfig represents the PEM-encoded certificate, key and CA certificate
+// used by the SSL listener.
+type tlsConfig struct {
+	Cert []byte
+	Key  []byte
+	CA   []byte
+}
+
+// saslConfig represents the SASL mechanism and the users allowed to authenticate
+// against the SASL listener.
+type saslConfig struct {
+	Mechanism string
+	// Users is a map of username (key) to password (value).
+	Users map[string]string
 }
 
 func defaultOptions() options {
@@ -30,6 +187,10 @@ func (o Option) Customize(*testcontainers.GenericContainerRequest) error {
 	return nil
 }
 
+// WithClusterID sets the KRaft cluster id of the broker. KRaft requires a base64 encoded UUID,
+// like the ones returned by GenerateClusterID, which is used as is. Any other value is considered
+// a human label, from which a valid cluster id is deterministically derived when starting the container.
+// The ClusterID field of the container holds the actual cluster id, and the ClusterLabel field the label.
 func WithClusterID(clusterID string) testcontainers.CustomizeRequestOption {
 	return func(req *testcontainers.GenericContainerRequest) error {
 		req.Env["CLUSTER_ID"] = clusterID
@@ -37,18 +198,416 @@ func WithClusterID(clusterID string) testcontainers.CustomizeRequestOption {
 	}
 }
 
-// WithListener adds a custom listener to the Redpanda containers. Listener
+// WithSASLPlain enables SASL/PLAIN authentication for the Kafka container, adding
+// a SASL_PLAINTEXT listener on a dedicated port. The users map contains the username (key)
+// and password (value) of the users allowed to authenticate. At least one user must be
+// provided, if not an error will be thrown when starting the container.
+// Use the SASLBrokers method to get the connection strings of the SASL listener.
+func WithSASLPlain(users map[string]string) Option {
+	return func(o *options) {
+		o.SASL = &saslConfig{
+			Mechanism: saslMechanismPlain,
+			Users:     users,
+		}
+	}
+}
+
+// WithSCRAM enables SASL/SCRAM authentication for the Kafka container, adding
+// a SASL_PLAINTEXT listener on a dedicated port. The mechanism must be one of
+// SCRAM-SHA-256 or SCRAM-SHA-512, and the users map contains the username (key)
+// and password (value) of the users whose SCRAM credentials will be created when
+// formatting the storage. At least one user must be provided.
+// Use the SASLBrokers method to get the connection strings of the SASL listener.
+func WithSCRAM(mechanism string, users map[string]string) Option {
+	return func(o *options) {
+		o.SASL = &saslConfig{
+			Mechanism: strings.ToUpper(strings.TrimSpace(mechanism)),
+			Users:     users,
+		}
+	}
+}
+
+// WithTLS enables TLS for the Kafka container, adding an SSL listener on a dedicated port.
+// The cert and key must be a valid PEM-encoded keypair, used by the broker to identify itself,
+// and the CA is the PEM-encoded certificate of the authority that signed the cert. If the CA is
+// empty, the cert is considered self-signed and it will be trusted instead.
+// An invalid keypair will throw an error before the container is started.
+// Use the TLSBrokers method to get the connection strings of the SSL listener, and the TLSConfig
+// method to get a TLS configuration for the clients.
+func WithTLS(cert, key, ca []byte) Option {
+	return func(o *options) {
+		o.TLS = &tlsConfig{
+			Cert: cert,
+			Key:  key,
+			CA:   ca,
+		}
+	}
+}
+
+// WithConfig sets the given broker configuration properties, e.g. "log.retention.ms" or "message.max.bytes",
+// injecting each key as a KAFKA_ prefixed environment variable, as expected by the Kafka images.
+// It can be called multiple times, merging the entries. The properties managed by the module,
+// like the listeners or the cluster id, cannot be overridden: an error will be thrown when starting the container.
+func WithConfig(entries map[string]string) Option {
+	return func(o *options) {
+		if o.Config == nil {
+			o.Config = make(map[string]string, len(entries))
+		}
+
+		for key, value := range entries {
+			o.Config[key] = value
+		}
+	}
+}
+
+// WithServerPropertiesFile copies the server.properties file at the given path into the container,
+// using it verbatim for the broker config, on top of the config generated by the module, which still
+// manages the listeners. The file must not define any of the properties managed by the module,
+// like listeners or advertised.listeners: an error will be thrown when starting the container.
+func WithServerPropertiesFile(path string) Option {
+	return func(o *options) {
+		o.ServerPropertiesFile = path
+	}
+}
+
+// WithFiles copies the files into the container before it starts, so they are available to the broker
+// when the starter script generated by the module runs, e.g. a keytab, a truststore or a JAAS file.
+// The files must not overwrite the files managed by the module, like the starter script: an error will
+// be thrown when starting the container.
+func WithFiles(files ...testcontainers.ContainerFile) Option {
+	return func(o *options) {
+		o.Files = append(o.Files, files...)
+	}
+}
+
+// WithJAASConfigFile copies the JAAS file at the given path into the container, adding it to the KAFKA_OPTS
+// of the broker, after the flags set with WithEnv, if any. The SASL listener enabled with WithSASLPlain or
+// WithSCRAM then uses the KafkaServer section of the file instead of the JAAS config generated by the module,
+// so the PLAIN users can be defined in the file only, passing no users to WithSASLPlain.
+func WithJAASConfigFile(hostPath string) Option {
+	return func(o *options) {
+		o.JAASConfigFile = hostPath
+	}
+}
+
+// WithRestProxy exposes the Confluent REST proxy bundled in the confluent-local images,
+// waiting for it to serve requests before the container is returned.
+// Use the RestProxyURL method to get its URL. The apache/kafka images do not bundle it,
+// so an error will be thrown when starting the container with them.
+func WithRestProxy() Option {
+	return func(o *options) {
+		o.RestProxy = true
+	}
+}
+
+// WithSchemaRegistry starts a confluentinc/cp-schema-registry container in the same network as
+// the broker, pointed at it, waiting for its HTTP API to serve requests before the container is returned.
+// If the broker is not attached to any network, a new one is created. Use the SchemaRegistryURL method
+// to get its URL. Terminating the Kafka container also terminates the Schema Registry container.
+func WithSchemaRegistry() Option {
+	return func(o *options) {
+		o.SchemaRegistry = true
+	}
+}
+
+// WithConnect starts a confluentinc/cp-kafka-connect worker container in the same network as the broker,
+// pointed at it, installing the given plugins from Confluent Hub, e.g. confluentinc/kafka-connect-datagen:0.6.5.
+// The container is returned once its REST API serves the connectors. If the broker is not attached to any
+// network, a new one is created. Use the ConnectURL method to get its URL. Terminating the Kafka container
+// also terminates the Kafka Connect container.
+func WithConnect(plugins []string) Option {
+	return func(o *options) {
+		o.Connect = true
+		o.ConnectPlugins = append(o.ConnectPlugins, plugins...)
+	}
+}
+
+// WithDataVolume mounts the Docker volume with the given name at the data directory of the broker,
+// where both the topic data and the KRaft metadata log are stored. This way, a container can be
+// terminated and a new one started against the same volume, preserving the messages. The volume is
+// created if it does not exist, and it's removed by the Reaper at the end of the test session.
+func WithDataVolume(name string) Option {
+	return func(o *options) {
+		o.DataVolume = name
+	}
+}
+
+// WithJMX enables the remote JMX agent of the broker on the given port, exposing it, so the broker
+// metrics can be scraped during the tests. The JMX hostname advertised by the broker is the hostname
+// of the container. Use the JMXEndpoint method to get the mapped host and port.
+func WithJMX(port int) Option {
+	return func(o *options) {
+		o.JMXPort = port
+	}
+}
+
+// WithDefaultPartitions sets the number of partitions of the topics created automatically
+// by the broker, i.e. on the first produce to a nonexistent topic.
+func WithDefaultPartitions(n int) Option {
+	return func(o *options) {
+		o.DefaultPartitions = n
+	}
+}
+
+// WithDefaultReplicationFactor sets the replication factor of the topics created automatically
+// by the broker, and of the internal offsets and transaction state topics. It cannot be higher
+// than the number of brokers.
+func WithDefaultReplicationFactor(n int) Option {
+	return func(o *options) {
+		o.DefaultReplicationFactor = n
+	}
+}
+
+// WithOffsetsTopicReplicationFactor sets the replication factor of the internal consumer offsets topic,
+// created when the first consumer group is coordinated, overriding WithDefaultReplicationFactor for it.
+// It's 1 by default, or the number of brokers up to 3 for RunCluster, so that the consumer groups work
+// on a fresh broker whatever the image. It cannot be higher than the number of brokers.
+func WithOffsetsTopicReplicationFactor(n int) Option {
+	return func(o *options) {
+		o.OffsetsTopicReplicationFactor = n
+	}
+}
+
+// WithAutoCreateTopics enables or disables the automatic creation of topics by the broker on the
+// first produce to a nonexistent topic. When disabled, producing to a nonexistent topic fails,
+// which helps asserting that the application creates its own topics. It's enabled by default.
+func WithAutoCreateTopics(enabled bool) Option {
+	return func(o *options) {
+		o.AutoCreateTopics = &enabled
+	}
+}
+
+// WithAuthorizer enables the standard KRaft ACL authorizer on the broker, marking the given principals
+// as super users, e.g. "User:admin". A principal without a type is considered a user. Combined with
+// the SASL options, it allows testing that an unauthorized principal is denied access to a resource.
+// Use the CreateACL method to grant the access to the principals.
+func WithAuthorizer(superUsers []string) Option {
+	return func(o *options) {
+		o.Authorizer = true
+		o.SuperUsers = superUsers
+	}
+}
+
+// withClusterSize sets the number of brokers of the cluster the container belongs to.
+func withClusterSize(n int) Option {
+	return func(o *options) {
+		o.clusterSize = n
+	}
+}
+
+// WithBrokerCount sets the number of inter-connected brokers started by RunCluster,
+// sharing a KRaft quorum. RunContainer only supports one broker, throwing an error
+// if a greater number is passed.
+func WithBrokerCount(n int) Option {
+	return func(o *options) {
+		o.BrokerCount = n
+	}
+}
+
+// WithEnv sets the environment variable with the given value, e.g. KAFKA_OPTS for the JVM flags,
+// merging it with the environment variables computed by the module. It can be called multiple times.
+// The variables managed by the module, like the listeners or the cluster id, cannot be overridden:
+// an error will be thrown when starting the container.
+func WithEnv(key, value string) Option {
+	return func(o *options) {
+		if o.Env == nil {
+			o.Env = make(map[string]string)
+		}
+
+		o.Env[key] = value
+	}
+}
+
+// WithAdvertisedHost sets the host advertised by the listeners mapped to the host, i.e. the external,
+// SASL and TLS ones, instead of the host of the container, e.g. the host of the CI runner when the
+// tests connect to the broker through NAT. The internal and custom listeners are not affected.
+// The Brokers, SASLBrokers and TLSBrokers methods return the advertised host with the mapped port.
+func WithAdvertisedHost(host string) Option {
+	return func(o *options) {
+		o.AdvertisedHost = host
+	}
+}
+
+// brokerPort returns the port of the default internal listener.
+func (o options) brokerPort() string {
+	if o.BrokerPort != "" {
+		return o.BrokerPort
+	}
+
+	return "9092"
+}
+
+// WithNodeID sets the KRaft node id of the broker, which is also its broker id and its id in the
+// controller quorum. It must be positive, and it cannot be used with RunCluster, which assigns the
+// node ids of its brokers.
+func WithNodeID(id int) Option {
+	return func(o *options) {
+		o.NodeID = id
+	}
+}
+
+// WithBrokerPort sets the port of the default internal listener, instead of 9092, recomputing the
+// listeners and the advertised listeners of the broker. It cannot be one of the ports reserved by the
+// module, e.g. the external (9093) and controller (9094) ones, and it cannot be used with WithListener,
+// as the custom listeners replace the default internal listener.
+func WithBrokerPort(port string) Option {
+	return func(o *options) {
+		o.BrokerPort = port
+	}
+}
+
+// WithFixedBrokerPort binds the external listener to the given port of the host, instead of a random one,
+// e.g. to open a single port in the firewall of the CI. The advertised listeners and the Brokers method use it
+// as any mapped port. As two brokers cannot bind the same port, the tests using it cannot run in parallel,
+// and it cannot be used with RunCluster. If the port is already in use, an error will be thrown when starting the container.
+func WithFixedBrokerPort(hostPort int) Option {
+	return func(o *options) {
+		o.FixedBrokerPort = hostPort
+	}
+}
+
+// WithZookeeper runs the broker in ZooKeeper mode, starting a ZooKeeper container next to it in the same
+// network, for the tests of the legacy clients needing a ZooKeeper-backed broker. It requires a pre-KRaft
+// capable image, i.e. a confluentinc/cp-kafka image lower than 8.0.0, as the confluent-local and apache/kafka
+// images only run in KRaft mode: an error will be thrown when starting the container with any of them.
+// The ZooKeeper container is terminated with the broker.
+func WithZookeeper() Option {
+	return func(o *options) {
+		o.Zookeeper = true
+	}
+}
+
+// WithExposedController publishes the port of the controller listener, so the tools inspecting
+// the controller quorum can connect to it from the host, using the ControllerEndpoint method.
+func WithExposedController() Option {
+	return func(o *options) {
+		o.ExposedController = true
+	}
+}
+
+// WithGracefulShutdownTimeout enables the graceful shutdown of the broker, which is signalled before
+// the container is terminated, bounding the wait for its controlled shutdown. The graceful shutdown
+// is disabled by default, or with a zero or negative timeout, so the broker is killed right away.
+func WithGracefulShutdownTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.GracefulShutdownTimeout = d
+	}
+}
+
+// WithReuse reuses the running container with the given name, e.g. one started by another test
+// binary, starting it only if there is none, so the broker is shared by the test packages.
+// The options of the reused container are the ones it was started with.
+func WithReuse(name string) Option {
+	return func(o *options) {
+		o.ReuseName = name
+	}
+}
+
+// WithStartupScriptHook appends shell lines to the starter script generated by the module, which
+// are run after the broker is configured and right before it is launched, e.g. to prepare files
+// used by the broker. The lines cannot use exec, as it would replace the broker process.
+func WithStartupScriptHook(lines []string) Option {
+	return func(o *options) {
+		o.StartupScriptHook = append(o.StartupScriptHook, lines...)
+	}
+}
+
+// WithInterBrokerListener sets the listener used for the inter-broker traffic, which is the first
+// custom listener by default, or the default internal one if there are none. The listener must be
+// one of the plaintext listeners of the broker, e.g. to use SASL for the clients only.
+func WithInterBrokerListener(name string) Option {
+	return func(o *options) {
+		o.InterBrokerListener = name
+	}
+}
+
+// WithClientQuota sets the byte rate quotas, in bytes per second, of the clients authenticated as the
+// principal, e.g. "alice" or "User:alice", or of all the users without quotas for the "<default>" principal.
+// A zero rate leaves the rate unlimited. The quotas are set once the broker is ready.
+func WithClientQuota(principal string, producerByteRate, consumerByteRate int) Option {
+	return func(o *options) {
+		o.ClientQuotas = append(o.ClientQuotas, clientQuota{
+			principal:        strings.TrimSpace(principal),
+			producerByteRate: producerByteRate,
+			consumerByteRate: consumerByteRate,
+		})
+	}
+}
+
+// WithInitialTopics creates the topics defined by the specs once the broker is ready, before the
+// container is returned, so they exist before any client connects. The topics that already exist, e.g. in
+// a reused container, are skipped. If a topic cannot be created, the container fails to start with an error
+// including the last lines of the broker logs. The option can be used multiple times.
+func WithInitialTopics(specs ...TopicSpec) Option {
+	return func(o *options) {
+		o.InitialTopics = append(o.InitialTopics, specs...)
+	}
+}
+
+// WithSeedData produces the messages into the topic once the broker is ready, and the initial topics are
+// created, before the container is returned, so the tests start with their fixtures in place. The messages
+// are produced in order to the first partition of the topic, without key. If a message cannot be produced,
+// the container fails to start with an error including the last lines of the broker logs.
+// The option can be used multiple times, the topics are seeded in the same order.
+func WithSeedData(topic string, messages [][]byte) Option {
+	return func(o *options) {
+		o.SeedData = append(o.SeedData, seedData{topic: topic, messages: messages})
+	}
+}
+
+// WithTransactions configures the transaction state log of the broker for the transactional, exactly-once
+// producers: a single in-sync replica is enough to commit a transaction, and the log has a single partition,
+// so the first transaction commits quickly. Its replication factor follows the brokers, i.e. one for a single
+// container. The transaction state log settings cannot be set with WithConfig or WithEnv when using this option.
+func WithTransactions() Option {
+	return func(o *options) {
+		o.Transactions = true
+	}
+}
+
+// WithIdempotenceReady configures the broker for the idempotent producers, i.e. with enable.idempotence=true,
+// which require acks=all and at most five in-flight requests: a single in-sync replica is enough to acknowledge
+// a write, so a single broker, or a cluster with failed brokers, accepts them. It's not the default, as it weakens
+// the durability of the writes of the clients using acks=all on a cluster. The min.insync.replicas setting cannot
+// be set with WithConfig or WithEnv when using this option.
+func WithIdempotenceReady() Option {
+	return func(o *options) {
+		o.IdempotenceReady = true
+	}
+}
+
+// WithCompression sets the compression codec of the topics, i.e. gzip, snappy, lz4 or zstd, so the
+// broker stores the messages compressed with the codec, whatever the codec of the producers.
+func WithCompression(codec string) Option {
+	return func(o *options) {
+		o.Compression = codec
+	}
+}
+
+// WithLogLevel sets the level of the root logger of the broker, e.g. WARN, to keep the output of
+// the tests readable. It defaults to WARN if the level is empty. The lines the module waits for,
+// when the broker starts up and shuts down, are still logged.
+func WithLogLevel(level string) Option {
+	return func(o *options) {
+		o.LogLevel = level
+		if o.LogLevel == "" {
+			o.LogLevel = "WARN"
+		}
+	}
+}
+
+// WithListener adds custom listeners to the Kafka container. Listeners
 // will be aliases to all networks, so they can be accessed from within docker
-// networks. At leas one network must be attached to the container, if not an
-// error will be thrown when starting the container.
+// networks. The redpanda.WithListeners option of the Redpanda module receives
+// the same listeners, with the same validation.
 func WithListener(listeners []KafkaListener) Option {
 	return func(o *options) {
 		o.Listeners = append(o.Listeners, listeners...)
 	}
 }
 
-func externalListener(ctx context.Context, c testcontainers.Container) (KafkaListener, error) {
-	host, err := c.Host(ctx)
+func externalListener(ctx context.Context, c testcontainers.Container, settings options) (KafkaListener, error) {
+	host, err := listenerHost(ctx, c, settings)
 	if err != nil {
 		return KafkaListener{}, err
 	}
@@ -65,7 +624,54 @@ func externalListener(ctx context.Context, c testcontainers.Container) (KafkaLis
 	}, nil
 }
 
-func internalListener(ctx context.Context, c testcontainers.Container) (KafkaListener, error) {
+// mappedListener returns a listener with the given name, advertising the host and the
+// mapped port of the given container port.
+func mappedListener(ctx context.Context, c testcontainers.Container, name string, containerPort nat.Port, settings options) (KafkaListener, error) {
+	host, err := listenerHost(ctx, c, settings)
+	if err != nil {
+		return KafkaListener{}, err
+	}
+
+	port, err := c.MappedPort(ctx, containerPort)
+	if err != nil {
+		return KafkaListener{}, err
+	}
+
+	return KafkaListener{
+		Name: name,
+		Ip:   host,
+		Port: port.Port(),
+	}, nil
+}
+
+// listenerHost returns the host advertised by the listeners mapped to the host, which is the
+// advertised host if not empty, or the host of the container otherwise. The latter is the host
+// of the Docker daemon the ports are mapped on, e.g. the remote host of DOCKER_HOST=tcp://host:2376,
+// and localhost for a local daemon, or the host gateway of Podman if the tests run in a Podman container.
+func listenerHost(ctx context.Context, c testcontainers.Container, settings options) (string, error) {
+	if settings.AdvertisedHost != "" {
+		return settings.AdvertisedHost, nil
+	}
+
+	host, err := c.Host(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if settings.podman {
+		return podmanListenerHost(host), nil
+	}
+
+	return host, nil
+}
+
+// hostPort returns the host:port address of a listener, bracketing the IPv6 hosts, e.g. the
+// remote Docker host of DOCKER_HOST=tcp://[2001:db8::1]:2376, so the address can be parsed back.
+func hostPort(host string, port string) string {
+	return net.JoinHostPort(strings.Trim(host, "[]"), port)
+}
+
+func internalListener(ctx context.Context, c testcontainers.Container, port string) (KafkaListener, error) {
 	host, err := c.Host(ctx)
 	if err != nil {
 		return KafkaListener{}, err
@@ -74,6 +680,76 @@ func internalListener(ctx context.Context, c testcontainers.Container) (KafkaLis
 	return KafkaListener{
 		Name: "INTERNAL",
 		Ip:   host,
-		Port: "9092",
+		Port: port,
 	}, nil
 }
+
+// mappedListenerInfo describes a listener mapped to the host, whose advertised port is the mapped one.
+func mappedListenerInfo(listener KafkaListener, protocol string) ListenerInfo {
+	return ListenerInfo{
+		Name:     listener.Name,
+		Ip:       listener.Ip,
+		Port:     listener.Port,
+		Protocol: protocol,
+		HostPort: listener.Port,
+	}
+}
+
+// advertisedListeners returns the listeners advertised by the broker, in the order they are advertised:
+// the custom listeners, or the default internal one if there are none, followed by the external
+// listener, and by the SASL and TLS ones if they are enabled.
+func advertisedListeners(ctx context.Context, c testcontainers.Container, settings options) ([]ListenerInfo, error) {
+	var listeners []ListenerInfo
+	for _, item := range settings.Listeners {
+		listeners = append(listeners, ListenerInfo{
+			Name:           item.Name,
+			Ip:             item.Ip,
+			Port:           item.Port,
+			AdvertisedHost: item.AdvertisedHost,
+			Protocol:       item.protocol(),
+			Internal:       true,
+		})
+	}
+
+	if len(listeners) == 0 {
+		defaultInternal, err := internalListener(ctx, c, settings.brokerPort())
+		if err != nil {
+			return nil, fmt.Errorf("can't create default internal listener: %w", err)
+		}
+
+		listeners = append(listeners, ListenerInfo{
+			Name:     defaultInternal.Name,
+			Ip:       defaultInternal.Ip,
+			Port:     defaultInternal.Port,
+			Protocol: "PLAINTEXT",
+			Internal: true,
+		})
+	}
+
+	defaultExternal, err := externalListener(ctx, c, settings)
+	if err != nil {
+		return nil, fmt.Errorf("can't create default external listener: %w", err)
+	}
+
+	listeners = append(listeners, mappedListenerInfo(defaultExternal, "PLAINTEXT"))
+
+	if settings.SASL != nil {
+		sasl, err := mappedListener(ctx, c, saslListenerName, saslPort, settings)
+		if err != nil {
+			return nil, fmt.Errorf("can't create sasl listener: %w", err)
+		}
+
+		listeners = append(listeners, mappedListenerInfo(sasl, "SASL_PLAINTEXT"))
+	}
+
+	if settings.TLS != nil {
+		tlsListener, err := mappedListener(ctx, c, tlsListenerName, tlsPort, settings)
+		if err != nil {
+			return nil, fmt.Errorf("can't create tls listener: %w", err)
+		}
+
+		listeners = append(listeners, mappedListenerInfo(tlsListener, "SSL"))
+	}
+
+	return listeners, nil
+}
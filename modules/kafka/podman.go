@@ -0,0 +1,47 @@
+package kafka
+
+import (
+	"os"
+	"strings"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// podmanHostGateway is the name the Podman containers resolve to the host running them,
+// as host.docker.internal for Docker Desktop.
+const podmanHostGateway = "host.containers.internal"
+
+// podmanContainerEnv is the file Podman creates in its containers. Unlike Docker, it does
+// not create /.dockerenv, so the tests running in a Podman container are not detected as such
+// when resolving the host of the daemon. It's a variable so the tests can fake it.
+var podmanContainerEnv = "/run/.containerenv"
+
+// usesPodman returns whether the container is created by the Podman provider, which is the case
+// if it's requested with testcontainers.ProviderPodman, or if the default provider is auto-detected
+// as Podman from the DOCKER_HOST environment variable, as testcontainers.ProviderType.GetProvider does.
+func usesPodman(provider testcontainers.ProviderType) bool {
+	switch provider {
+	case testcontainers.ProviderPodman:
+		return true
+	case testcontainers.ProviderDefault:
+		return strings.Contains(os.Getenv("DOCKER_HOST"), "podman.sock")
+	default:
+		return false
+	}
+}
+
+// podmanListenerHost returns the host advertised by the listeners mapped to the host under Podman.
+// If the tests run in a Podman container, the ports mapped on the host are not reachable on localhost,
+// which is the container of the tests itself, so the host gateway of Podman is advertised instead.
+// The other hosts, e.g. the remote host of DOCKER_HOST=tcp://host:2376 or TC_HOST, are kept.
+func podmanListenerHost(host string) string {
+	if host != "localhost" && host != "127.0.0.1" && host != "::1" {
+		return host
+	}
+
+	if _, err := os.Stat(podmanContainerEnv); err != nil {
+		return host
+	}
+
+	return podmanHostGateway
+}
@@ -0,0 +1,89 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/IBM/sarama"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// defaultQuotaPrincipal is the principal of the default quotas, applied to the users without quotas
+const defaultQuotaPrincipal = "<default>"
+
+// clientQuota is the byte rate quota of the clients authenticated as the principal
+type clientQuota struct {
+	principal        string
+	producerByteRate int
+	consumerByteRate int
+}
+
+// entity returns the quota entity of the user of the principal, which is the default user
+// for the "<default>" principal. The "User:" type of the principal is optional.
+func (q clientQuota) entity() []sarama.QuotaEntityComponent {
+	if q.principal == defaultQuotaPrincipal {
+		return []sarama.QuotaEntityComponent{{EntityType: sarama.QuotaEntityUser, MatchType: sarama.QuotaMatchDefault}}
+	}
+
+	return []sarama.QuotaEntityComponent{{
+		EntityType: sarama.QuotaEntityUser,
+		MatchType:  sarama.QuotaMatchExact,
+		Name:       strings.TrimPrefix(q.principal, "User:"),
+	}}
+}
+
+// ops returns the operations setting the byte rates of the quota, skipping the unset ones.
+func (q clientQuota) ops() []sarama.ClientQuotasOp {
+	var ops []sarama.ClientQuotasOp
+	if q.producerByteRate > 0 {
+		ops = append(ops, sarama.ClientQuotasOp{Key: "producer_byte_rate", Value: float64(q.producerByteRate)})
+	}
+
+	if q.consumerByteRate > 0 {
+		ops = append(ops, sarama.ClientQuotasOp{Key: "consumer_byte_rate", Value: float64(q.consumerByteRate)})
+	}
+
+	return ops
+}
+
+// validateClientQuotas validates that each quota has a principal and at least one byte rate,
+// and that the byte rates are not negative.
+func validateClientQuotas(settings options) error {
+	for _, quota := range settings.ClientQuotas {
+		if strings.TrimPrefix(quota.principal, "User:") == "" {
+			return errors.New("empty principal")
+		}
+
+		if quota.producerByteRate < 0 || quota.consumerByteRate < 0 {
+			return fmt.Errorf("principal %s: negative byte rate", quota.principal)
+		}
+
+		if quota.producerByteRate == 0 && quota.consumerByteRate == 0 {
+			return fmt.Errorf("principal %s: no byte rate", quota.principal)
+		}
+	}
+
+	return nil
+}
+
+// configureClientQuotas sets the client quotas, connecting to the external listener of the container.
+func configureClientQuotas(ctx context.Context, c testcontainers.Container, settings options, image string) error {
+	admin, err := containerClusterAdmin(ctx, c, settings, image)
+	if err != nil {
+		return err
+	}
+	defer admin.Close()
+
+	for _, quota := range settings.ClientQuotas {
+		for _, op := range quota.ops() {
+			if err := admin.AlterClientQuotas(quota.entity(), op, false); err != nil {
+				return fmt.Errorf("alter %s quota of %s: %w", op.Key, quota.principal, err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package kafka
+
+import (
+	"errors"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// Request builds the request of a Kafka broker with fluent methods, for the tests needing to tweak
+// the request before the container is started, e.g. to add a mount. The options are validated when
+// the request is built, exactly as RunContainer does.
+type Request struct {
+	opts []testcontainers.ContainerCustomizer
+}
+
+// NewRequest returns a Request for a broker running the given image.
+func NewRequest(image string) *Request {
+	return &Request{opts: []testcontainers.ContainerCustomizer{testcontainers.WithImage(image)}}
+}
+
+// With adds the given options to the request, e.g. the ones without a dedicated method.
+func (r *Request) With(opts ...testcontainers.ContainerCustomizer) *Request {
+	r.opts = append(r.opts, opts...)
+	return r
+}
+
+// WithClusterID sets the cluster id of the broker, see the WithClusterID option.
+func (r *Request) WithClusterID(clusterID string) *Request {
+	return r.With(WithClusterID(clusterID))
+}
+
+// WithListener adds the custom listeners to the broker, see the WithListener option.
+func (r *Request) WithListener(listeners []KafkaListener) *Request {
+	return r.With(WithListener(listeners))
+}
+
+// WithEnv sets the environment variable of the broker, see the WithEnv option.
+func (r *Request) WithEnv(key, value string) *Request {
+	return r.With(WithEnv(key, value))
+}
+
+// WithConfig sets the broker config entries, see the WithConfig option.
+func (r *Request) WithConfig(entries map[string]string) *Request {
+	return r.With(WithConfig(entries))
+}
+
+// WithSASLPlain enables SASL/PLAIN authentication for the given users, see the WithSASLPlain option.
+func (r *Request) WithSASLPlain(users map[string]string) *Request {
+	return r.With(WithSASLPlain(users))
+}
+
+// WithTLS enables the TLS listener, see the WithTLS option.
+func (r *Request) WithTLS(cert, key, ca []byte) *Request {
+	return r.With(WithTLS(cert, key, ca))
+}
+
+// Build validates the options and returns the request, to be passed to testcontainers.GenericContainer
+// once tweaked. The started container is a plain testcontainers.Container, so the methods of KafkaContainer
+// are not available: the external listener is reachable on the port mapped to 9093/tcp.
+// The Schema Registry, Kafka Connect and ZooKeeper are not supported, as they are run by RunContainer next to the broker.
+func (r *Request) Build() (testcontainers.GenericContainerRequest, error) {
+	req, err := newContainerRequest(r.opts...)
+	if err != nil {
+		return testcontainers.GenericContainerRequest{}, err
+	}
+
+	if req.settings.SchemaRegistry || req.settings.Connect || req.settings.Zookeeper {
+		return testcontainers.GenericContainerRequest{}, errors.New("schema registry, kafka connect and zookeeper are only supported by RunContainer")
+	}
+
+	configureControllerQuorumVoters(&req.GenericContainerRequest)
+
+	return req.GenericContainerRequest, nil
+}
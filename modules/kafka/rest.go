@@ -0,0 +1,49 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// ErrNotSupported is returned when a feature is requested but the image of the broker does not support it
+var ErrNotSupported = errors.New("not supported")
+
+// bundlesKafkaRest returns whether the image runs the Kafka REST endpoint next to the broker by default,
+// which is the case of all the supported confluent-local images, while the apache/kafka and cp-kafka images
+// do not bundle it.
+func bundlesKafkaRest(image string) bool {
+	repository, _ := splitImage(image)
+	return strings.HasSuffix(repository, "confluentinc/confluent-local")
+}
+
+// KafkaRestURL returns the URL of the Kafka REST endpoint run by default in the confluent-local images,
+// waiting for it to serve requests, so the records can be produced or consumed over HTTP without WithRestProxy.
+// It returns ErrNotSupported if the image does not bundle the REST endpoint, e.g. the apache/kafka images.
+func (kc *KafkaContainer) KafkaRestURL(ctx context.Context) (string, error) {
+	if !bundlesKafkaRest(kc.image) {
+		return "", fmt.Errorf("kafka rest endpoint of %s: %w", kc.image, ErrNotSupported)
+	}
+
+	// the endpoint is started with the broker, but only waited for at startup with WithRestProxy
+	if !kc.opts.RestProxy {
+		if err := wait.ForHTTP("/v3/clusters").WithPort(restProxyPort).WaitUntilReady(ctx, kc); err != nil {
+			return "", fmt.Errorf("wait for the kafka rest endpoint: %w", err)
+		}
+	}
+
+	host, err := kc.Host(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	port, err := kc.MappedPort(ctx, restProxyPort)
+	if err != nil {
+		return "", err
+	}
+
+	return "http://" + hostPort(host, port.Port()), nil
+}
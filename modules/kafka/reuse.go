@@ -0,0 +1,89 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// containerNameRegex matches the names accepted by Docker for the containers
+var containerNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]+$`)
+
+// validateReuse validates that the name of the reused container is a valid container name, and that
+// the container does not need resources created for each run, like the Schema Registry, Kafka Connect and their network.
+func validateReuse(settings options) error {
+	if settings.ReuseName == "" {
+		return nil
+	}
+
+	if !containerNameRegex.MatchString(settings.ReuseName) {
+		return fmt.Errorf("invalid container name %q", settings.ReuseName)
+	}
+
+	if settings.SchemaRegistry {
+		return errors.New("the schema registry is not supported by a reused container")
+	}
+
+	if settings.Connect {
+		return errors.New("kafka connect is not supported by a reused container")
+	}
+
+	return nil
+}
+
+// validateName validates that the container name set with testcontainers.WithName, if any, is not given
+// to all the brokers of a cluster, and does not differ from the name of the reused container.
+func validateName(name string, settings options) error {
+	if name == "" {
+		return nil
+	}
+
+	if settings.clusterSize > 0 {
+		return fmt.Errorf("the brokers of a cluster cannot share the container name %s", name)
+	}
+
+	if settings.ReuseName != "" && strings.TrimPrefix(name, "/") != settings.ReuseName {
+		return fmt.Errorf("the container name %s differs from the name of the reused container %s", name, settings.ReuseName)
+	}
+
+	return nil
+}
+
+// containerClusterID returns the cluster id the container was started with, which differs from the
+// requested one if the container was reused and started by another test binary with other options.
+func containerClusterID(ctx context.Context, c testcontainers.Container) (string, error) {
+	inspect, err := c.Inspect(ctx)
+	if err != nil {
+		return "", fmt.Errorf("inspect container: %w", err)
+	}
+
+	for _, env := range inspect.Config.Env {
+		if value, ok := strings.CutPrefix(env, "CLUSTER_ID="); ok {
+			return value, nil
+		}
+	}
+
+	return "", errors.New("cluster id not found in the container environment")
+}
+
+// containerStartupScript returns the starter script of the container, which was generated when the
+// container was started, possibly by another test binary if the container was reused.
+func containerStartupScript(ctx context.Context, c testcontainers.Container) (string, error) {
+	r, err := c.CopyFileFromContainer(ctx, starterScript)
+	if err != nil {
+		return "", fmt.Errorf("copy starter script: %w", err)
+	}
+	defer r.Close()
+
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("read starter script: %w", err)
+	}
+
+	return string(content), nil
+}
@@ -0,0 +1,144 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/IBM/sarama"
+	"github.com/google/uuid"
+)
+
+// RoundTrip produces a message with the given key and value to the topic, creating the topic if it
+// does not exist, and consumes it back, returning its value. It's meant for smoke tests proving the
+// broker works. The message is consumed with an ephemeral consumer group, which is deleted afterwards,
+// and the clients connect to the brokers returned by Brokers.
+func (kc *KafkaContainer) RoundTrip(ctx context.Context, topic string, key, value []byte) ([]byte, error) {
+	brokers, err := kc.Brokers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	config := sarama.NewConfig()
+	config.Version = saramaVersion(kc.image)
+	config.Producer.Return.Successes = true
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Consumer.Offsets.Initial = sarama.OffsetOldest
+
+	admin, err := sarama.NewClusterAdmin(brokers, config)
+	if err != nil {
+		return nil, fmt.Errorf("new cluster admin: %w", err)
+	}
+	defer admin.Close()
+
+	err = admin.CreateTopic(topic, TopicSpec{Name: topic}.topicDetail(), false)
+	if err != nil && !errors.Is(err, sarama.ErrTopicAlreadyExists) {
+		return nil, fmt.Errorf("create topic %s: %w", topic, err)
+	}
+
+	partition, offset, err := produceMessage(brokers, config, &sarama.ProducerMessage{
+		Topic: topic,
+		Key:   sarama.ByteEncoder(key),
+		Value: sarama.ByteEncoder(value),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	groupID := "testcontainers-roundtrip-" + uuid.NewString()
+
+	consumed, err := consumeMessage(ctx, brokers, config, groupID, topic, partition, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := admin.DeleteConsumerGroup(groupID); err != nil && !errors.Is(err, sarama.ErrGroupIDNotFound) {
+		return nil, fmt.Errorf("delete consumer group %s: %w", groupID, err)
+	}
+
+	return consumed, nil
+}
+
+// produceMessage sends the message with a new sync producer, returning its partition and offset.
+func produceMessage(brokers []string, config *sarama.Config, msg *sarama.ProducerMessage) (int32, int64, error) {
+	producer, err := sarama.NewSyncProducer(brokers, config)
+	if err != nil {
+		return 0, 0, fmt.Errorf("new producer: %w", err)
+	}
+	defer producer.Close()
+
+	partition, offset, err := producer.SendMessage(msg)
+	if err != nil {
+		return 0, 0, fmt.Errorf("produce to %s: %w", msg.Topic, err)
+	}
+
+	return partition, offset, nil
+}
+
+// consumeMessage consumes the topic with the given consumer group until the message at the partition
+// and offset is received, returning its value, or until the context is done.
+func consumeMessage(ctx context.Context, brokers []string, config *sarama.Config, groupID string, topic string, partition int32, offset int64) ([]byte, error) {
+	group, err := sarama.NewConsumerGroup(brokers, groupID, config)
+	if err != nil {
+		return nil, fmt.Errorf("new consumer group: %w", err)
+	}
+	defer group.Close()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	handler := &roundTripHandler{partition: partition, offset: offset, values: make(chan []byte, 1)}
+
+	errs := make(chan error, 1)
+	go func() {
+		// Consume returns on each rebalance, so it must be called again until the context is done
+		for ctx.Err() == nil {
+			if err := group.Consume(ctx, []string{topic}, handler); err != nil {
+				errs <- err
+				return
+			}
+		}
+	}()
+
+	select {
+	case value := <-handler.values:
+		return value, nil
+	case err := <-errs:
+		return nil, fmt.Errorf("consume from %s: %w", topic, err)
+	case <-ctx.Done():
+		return nil, fmt.Errorf("consume from %s: %w", topic, ctx.Err())
+	}
+}
+
+// roundTripHandler is a consumer group handler sending the value of the message at the
+// partition and offset to the values channel.
+type roundTripHandler struct {
+	partition int32
+	offset    int64
+	values    chan []byte
+}
+
+func (h *roundTripHandler) Setup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+func (h *roundTripHandler) Cleanup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+func (h *roundTripHandler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for msg := range claim.Messages() {
+		if msg.Partition != h.partition || msg.Offset != h.offset {
+			continue
+		}
+
+		select {
+		case h.values <- msg.Value:
+		default:
+		}
+
+		return nil
+	}
+
+	return nil
+}
@@ -0,0 +1,188 @@
+package kafka
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/IBM/sarama"
+	"github.com/xdg-go/scram"
+	"golang.org/x/mod/semver"
+)
+
+// SaramaConfig returns a sarama config for the container, with the Kafka version derived from the
+// image tag and sensible defaults for the tests: the producers wait for all the replicas and return
+// the successes, and the consumers start from the oldest offset.
+// If SASL was enabled, the config authenticates with the first user in alphabetical order, if any, and
+// if TLS was enabled, it trusts the CA of the broker. In both cases, the config must be used with
+// the brokers returned by SASLBrokers or TLSBrokers, respectively. As those are different listeners,
+// an error is returned if both SASL and TLS are enabled.
+func (kc *KafkaContainer) SaramaConfig() (*sarama.Config, error) {
+	if kc.opts.SASL != nil && kc.opts.TLS != nil {
+		return nil, errors.New("both sasl and tls are enabled, on different listeners")
+	}
+
+	config := sarama.NewConfig()
+	config.Version = saramaVersion(kc.image)
+	config.Producer.Return.Successes = true
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Consumer.Offsets.Initial = sarama.OffsetOldest
+
+	if kc.opts.SASL != nil {
+		config.Net.SASL.Enable = true
+
+		// the users may be defined in the JAAS file only, then the credentials are left to the caller
+		if usernames := sortedUsernames(kc.opts.SASL.Users); len(usernames) > 0 {
+			config.Net.SASL.User = usernames[0]
+			config.Net.SASL.Password = kc.opts.SASL.Users[usernames[0]]
+		}
+
+		switch kc.opts.SASL.Mechanism {
+		case saslMechanismScram256:
+			config.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA256
+			config.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient {
+				return &scramClient{HashGeneratorFcn: sha256.New}
+			}
+		case saslMechanismScram512:
+			config.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA512
+			config.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient {
+				return &scramClient{HashGeneratorFcn: sha512.New}
+			}
+		default:
+			config.Net.SASL.Mechanism = sarama.SASLTypePlaintext
+		}
+	}
+
+	if kc.opts.TLS != nil {
+		tlsConfig, err := kc.TLSConfig()
+		if err != nil {
+			return nil, err
+		}
+
+		config.Net.TLS.Enable = true
+		config.Net.TLS.Config = tlsConfig
+	}
+
+	return config, nil
+}
+
+// AdminClient returns a sarama cluster admin configured with the config returned by SaramaConfig,
+// connected to the brokers of the matching listener: the SASL listener if SASL was enabled, or the SSL
+// listener if TLS was enabled. It gives full access to the admin API, e.g. to alter the configs or to
+// manage the ACLs. The caller owns the admin client, and is responsible for calling its Close method.
+func (kc *KafkaContainer) AdminClient(ctx context.Context) (sarama.ClusterAdmin, error) {
+	config, err := kc.SaramaConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	brokers, err := kc.clientBrokers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	admin, err := sarama.NewClusterAdmin(brokers, config)
+	if err != nil {
+		return nil, fmt.Errorf("new cluster admin: %w", err)
+	}
+
+	return admin, nil
+}
+
+// NewSyncProducer returns a sarama sync producer configured with the config returned by SaramaConfig,
+// connected to the brokers of the matching listener, like AdminClient. As required by the sync producers,
+// the config returns the successes. The caller owns the producer, and is responsible for calling its Close method.
+func (kc *KafkaContainer) NewSyncProducer(ctx context.Context) (sarama.SyncProducer, error) {
+	config, err := kc.SaramaConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	brokers, err := kc.clientBrokers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	producer, err := sarama.NewSyncProducer(brokers, config)
+	if err != nil {
+		return nil, fmt.Errorf("new sync producer: %w", err)
+	}
+
+	return producer, nil
+}
+
+// NewConsumerGroup returns a sarama consumer group with the given id, configured with the config returned
+// by SaramaConfig, connected to the brokers of the matching listener, like AdminClient. The group starts
+// consuming from the oldest offset when it has no committed offset. The caller owns the consumer group,
+// and is responsible for calling its Close method.
+func (kc *KafkaContainer) NewConsumerGroup(ctx context.Context, group string) (sarama.ConsumerGroup, error) {
+	if group == "" {
+		return nil, errors.New("empty group")
+	}
+
+	config, err := kc.SaramaConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	brokers, err := kc.clientBrokers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	consumerGroup, err := sarama.NewConsumerGroup(brokers, group, config)
+	if err != nil {
+		return nil, fmt.Errorf("new consumer group %s: %w", group, err)
+	}
+
+	return consumerGroup, nil
+}
+
+// saramaVersion returns the Kafka version of the official images, capped to the maximum version
+// supported by sarama, e.g. confluent-local:7.6.1 ships Kafka 3.6. It returns sarama's default
+// version if the version cannot be derived from the image.
+func saramaVersion(fqName string) sarama.KafkaVersion {
+	kafkaVersion, err := parseKafkaVersion(fqName)
+	if err != nil || kafkaVersion == "" {
+		return sarama.DefaultVersion
+	}
+
+	version, err := sarama.ParseKafkaVersion(strings.TrimPrefix(semver.MajorMinor(kafkaVersion), "v") + ".0")
+	if err != nil {
+		return sarama.DefaultVersion
+	}
+
+	if version.IsAtLeast(sarama.MaxVersion) {
+		return sarama.MaxVersion
+	}
+
+	return version
+}
+
+// scramClient is a SCRAM client for sarama, as sarama does not provide one
+type scramClient struct {
+	*scram.Client
+	*scram.ClientConversation
+	scram.HashGeneratorFcn
+}
+
+func (x *scramClient) Begin(userName, password, authzID string) (err error) {
+	x.Client, err = x.HashGeneratorFcn.NewClient(userName, password, authzID)
+	if err != nil {
+		return err
+	}
+	x.ClientConversation = x.Client.NewConversation()
+	return nil
+}
+
+func (x *scramClient) Step(challenge string) (response string, err error) {
+	response, err = x.ClientConversation.Step(challenge)
+	return
+}
+
+func (x *scramClient) Done() bool {
+	return x.ClientConversation.Done()
+}
@@ -0,0 +1,137 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/docker/go-connections/nat"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/network"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const (
+	// schemaRegistryPort is the port of the Schema Registry HTTP API
+	schemaRegistryPort = nat.Port("8081/tcp")
+
+	// defaultSchemaRegistryImage is the Schema Registry image used when the version
+	// of the broker image cannot be matched
+	defaultSchemaRegistryImage = "confluentinc/cp-schema-registry:7.5.0"
+
+	// brokerNetworkAlias is the alias of the broker in the network created for the Schema Registry and Kafka Connect
+	brokerNetworkAlias = "kafka"
+)
+
+// ErrSchemaRegistryNotEnabled is returned when the Schema Registry URL is requested but the Schema Registry was not enabled
+var ErrSchemaRegistryNotEnabled = errors.New("schema registry not enabled")
+
+// withSchemaRegistryListener makes sure the broker is reachable by the Schema Registry and Kafka Connect containers,
+// advertising an internal listener with the alias of the broker in its first network, if there
+// are no custom listeners. If the broker is not attached to any network, the alias is the one
+// used for the network created by attachSchemaRegistryNetwork.
+func withSchemaRegistryListener(req *testcontainers.GenericContainerRequest, settings *options) {
+	if len(settings.Listeners) > 0 {
+		// the first listener is expected to be reachable from the network
+		return
+	}
+
+	alias := brokerNetworkAlias
+	if len(req.Networks) > 0 {
+		firstNetwork := req.Networks[0]
+		if req.NetworkAliases == nil {
+			req.NetworkAliases = make(map[string][]string)
+		}
+
+		if len(req.NetworkAliases[firstNetwork]) == 0 {
+			req.NetworkAliases[firstNetwork] = []string{brokerNetworkAlias}
+		}
+
+		alias = req.NetworkAliases[firstNetwork][0]
+	}
+
+	settings.Listeners = append(settings.Listeners, KafkaListener{
+		Name: "INTERNAL",
+		Ip:   alias,
+		Port: settings.brokerPort(),
+	})
+}
+
+// attachSchemaRegistryNetwork attaches the broker to a new network if it's not attached to
+// any, so the Schema Registry and Kafka Connect containers can reach it. It returns the network created, if any,
+// so it can be removed when the container is terminated.
+func attachSchemaRegistryNetwork(ctx context.Context, req *testcontainers.GenericContainerRequest) (*testcontainers.DockerNetwork, error) {
+	if len(req.Networks) > 0 {
+		return nil, nil
+	}
+
+	nw, err := network.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("new network: %w", err)
+	}
+
+	if err := network.WithNetwork([]string{brokerNetworkAlias}, nw).Customize(req); err != nil {
+		return nil, errors.Join(err, nw.Remove(ctx))
+	}
+
+	return nw, nil
+}
+
+// schemaRegistryImage returns the Schema Registry image matching the version of the
+// confluent-local broker image, or the default one for the rest of the images.
+func schemaRegistryImage(brokerImage string) string {
+	repository, tag := splitImage(brokerImage)
+	if tag == "" || !strings.HasSuffix(repository, "confluentinc/confluent-local") {
+		return defaultSchemaRegistryImage
+	}
+
+	return "confluentinc/cp-schema-registry:" + tag
+}
+
+// runSchemaRegistry starts the Schema Registry container in the given network, pointed at the
+// broker through the given listener, and waits for its HTTP API to serve the subjects.
+func runSchemaRegistry(ctx context.Context, image string, networkName string, listener KafkaListener) (testcontainers.Container, error) {
+	req := testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        image,
+			ExposedPorts: []string{string(schemaRegistryPort)},
+			Networks:     []string{networkName},
+			Env: map[string]string{
+				"SCHEMA_REGISTRY_HOST_NAME":                    "schema-registry",
+				"SCHEMA_REGISTRY_LISTENERS":                    "http://0.0.0.0:" + schemaRegistryPort.Port(),
+				"SCHEMA_REGISTRY_KAFKASTORE_BOOTSTRAP_SERVERS": fmt.Sprintf("PLAINTEXT://%s:%s", listener.Ip, listener.Port),
+			},
+			WaitingFor: wait.ForHTTP("/subjects").WithPort(schemaRegistryPort),
+		},
+		Started: true,
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, req)
+	if err != nil {
+		return container, fmt.Errorf("schema registry: %w", err)
+	}
+
+	return container, nil
+}
+
+// SchemaRegistryURL returns the URL of the Schema Registry, defined by the exposed Schema Registry port.
+// It returns ErrSchemaRegistryNotEnabled if the Schema Registry was not enabled for the container.
+func (kc *KafkaContainer) SchemaRegistryURL(ctx context.Context) (string, error) {
+	if kc.schemaRegistry == nil {
+		return "", ErrSchemaRegistryNotEnabled
+	}
+
+	host, err := kc.schemaRegistry.Host(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	port, err := kc.schemaRegistry.MappedPort(ctx, schemaRegistryPort)
+	if err != nil {
+		return "", err
+	}
+
+	return "http://" + hostPort(host, port.Port()), nil
+}
@@ -0,0 +1,111 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/IBM/sarama"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// seedData are the messages produced into the topic once the broker is ready
+type seedData struct {
+	topic    string
+	messages [][]byte
+}
+
+// validateSeedData validates that the seed data have a topic and at least one message, and that they
+// are not used with RunCluster, which leaves the initial topics they may be produced into to the cluster.
+func validateSeedData(settings options) error {
+	if len(settings.SeedData) > 0 && settings.clusterSize > 0 {
+		return errors.New("not supported by RunCluster")
+	}
+
+	for i, seed := range settings.SeedData {
+		if seed.topic == "" {
+			return fmt.Errorf("seed data at index %d: empty topic", i)
+		}
+
+		if len(seed.messages) == 0 {
+			return fmt.Errorf("seed data of %s: no messages", seed.topic)
+		}
+	}
+
+	return nil
+}
+
+// produceSeedData produces the seed data, connecting to the external listener of the container. The messages
+// of a topic are sent one at a time to its first partition, so they are stored in order. If a message cannot
+// be produced, the error includes the last lines of the broker logs. The hook producing them runs again each
+// time the container is started, e.g. after a Stop, so a topic whose first partition already holds messages
+// is not seeded again, which also keeps a reused container from being seeded twice. All the batches of a topic
+// are produced, or none of them.
+func produceSeedData(ctx context.Context, c testcontainers.Container, settings options, image string) error {
+	brokers, err := containerExternalBrokers(ctx, c, settings)
+	if err != nil {
+		return err
+	}
+
+	config := sarama.NewConfig()
+	config.Version = saramaVersion(image)
+	config.Producer.Partitioner = sarama.NewManualPartitioner
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Producer.Return.Successes = true
+
+	client, err := sarama.NewClient(brokers, config)
+	if err != nil {
+		return fmt.Errorf("new client: %w", err)
+	}
+	defer client.Close()
+
+	producer, err := sarama.NewSyncProducerFromClient(client)
+	if err != nil {
+		return fmt.Errorf("new producer: %w", err)
+	}
+	defer producer.Close()
+
+	// the topics are checked before producing anything, so the later batches of a topic seeded
+	// by an earlier batch are not mistaken for an already seeded topic
+	seeded := make(map[string]bool, len(settings.SeedData))
+	for _, seed := range settings.SeedData {
+		if _, ok := seeded[seed.topic]; ok {
+			continue
+		}
+
+		seeded[seed.topic], err = seededTopic(client, seed.topic)
+		if err != nil {
+			return fmt.Errorf("seed data of %s: %w\n%s", seed.topic, err, brokerLogsTail(ctx, c, initialTopicsLogLines))
+		}
+	}
+
+	for _, seed := range settings.SeedData {
+		if seeded[seed.topic] {
+			continue
+		}
+
+		for i, message := range seed.messages {
+			if _, _, err := producer.SendMessage(&sarama.ProducerMessage{Topic: seed.topic, Partition: 0, Value: sarama.ByteEncoder(message)}); err != nil {
+				return fmt.Errorf("seed message %d of %s: %w\n%s", i, seed.topic, err, brokerLogsTail(ctx, c, initialTopicsLogLines))
+			}
+		}
+	}
+
+	return nil
+}
+
+// seededTopic returns whether the first partition of the topic, where the seed data are produced, already
+// holds messages. A topic that does not exist yet, which is created by the first seed message, holds none.
+func seededTopic(client sarama.Client, topic string) (bool, error) {
+	offset, err := client.GetOffset(topic, 0, sarama.OffsetNewest)
+	if errors.Is(err, sarama.ErrUnknownTopicOrPartition) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("end offset: %w", err)
+	}
+
+	return offset > 0, nil
+}
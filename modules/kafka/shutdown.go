@@ -0,0 +1,102 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// the lines logged by the broker once its controlled shutdown is completed, by the BrokerServer in KRaft mode
+// and by the KafkaServer in ZooKeeper mode, as the line waited for at startup
+var (
+	kraftShutdownLog     = regexp.MustCompile(`\[BrokerServer id=\d+\] shut down completed`)
+	zookeeperShutdownLog = regexp.MustCompile(`\[KafkaServer id=\d+\] shut down completed`)
+)
+
+// controlledShutdownLog returns the regexp matching the line logged by the broker once its controlled
+// shutdown is completed, in the mode of the broker.
+func controlledShutdownLog(settings options) *regexp.Regexp {
+	if settings.Zookeeper {
+		return zookeeperShutdownLog
+	}
+
+	return kraftShutdownLog
+}
+
+// gracefulShutdown returns the hook sending SIGTERM to the broker and waiting, up to the timeout, for
+// the broker to complete its controlled shutdown, i.e. to log it or to exit, so the in-flight requests
+// are acknowledged before the container is removed. It does nothing if the container is not running,
+// and never fails once the signal is sent, as the container is force-killed afterwards anyway.
+func gracefulShutdown(settings options) testcontainers.ContainerHook {
+	shutdownLog := controlledShutdownLog(settings)
+
+	return func(ctx context.Context, c testcontainers.Container) error {
+		state, err := c.State(ctx)
+		if err != nil {
+			return fmt.Errorf("container state: %w", err)
+		}
+
+		if !state.Running {
+			return nil
+		}
+
+		// only the shutdowns logged after the signal are considered, so the shutdown
+		// of a previous run of the container, e.g. before a restart, is ignored
+		previous := shutdownsCompleted(ctx, c, shutdownLog)
+
+		// the broker runs as the process 1 of the container, which is signaled as docker stop does
+		code, _, err := c.Exec(ctx, []string{"sh", "-c", "kill -TERM 1"})
+		if err != nil {
+			return fmt.Errorf("send SIGTERM: %w", err)
+		}
+
+		if code != 0 {
+			return fmt.Errorf("send SIGTERM: exit code %d", code)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, settings.GracefulShutdownTimeout)
+		defer cancel()
+
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+				state, err := c.State(ctx)
+				if err != nil || !state.Running {
+					return nil
+				}
+
+				if shutdownsCompleted(ctx, c, shutdownLog) > previous {
+					return nil
+				}
+			}
+		}
+	}
+}
+
+// shutdownsCompleted returns the number of controlled shutdowns logged by the container, reading its logs
+// with the Logs method, which are empty if they cannot be read.
+func shutdownsCompleted(ctx context.Context, c testcontainers.Container, shutdownLog *regexp.Regexp) int {
+	rc, err := c.Logs(ctx)
+	if err != nil {
+		return 0
+	}
+	defer rc.Close()
+
+	return countShutdowns(rc, shutdownLog)
+}
+
+// countShutdowns returns the number of controlled shutdown lines in the logs.
+func countShutdowns(r io.Reader, shutdownLog *regexp.Regexp) int {
+	logs, _ := io.ReadAll(r)
+
+	return len(shutdownLog.FindAllIndex(logs, -1))
+}
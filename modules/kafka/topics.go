@@ -0,0 +1,320 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/IBM/sarama"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// ErrTopicDeletionDisabled is returned by DeleteTopics when the broker has delete.topic.enable set to false.
+var ErrTopicDeletionDisabled = errors.New("topic deletion disabled on the broker")
+
+// ErrTopicNotFound is returned by WaitForTopic when the broker reports that the topic does not exist.
+var ErrTopicNotFound = errors.New("topic not found")
+
+// ErrBrokerUnreachable is returned by WaitForTopic when the broker cannot be queried for the topic.
+var ErrBrokerUnreachable = errors.New("broker unreachable")
+
+// topicPollInterval is the interval between the metadata requests of WaitForTopic
+const topicPollInterval = 250 * time.Millisecond
+
+// TopicSpec represents the specification of a topic to be created in the Kafka container.
+type TopicSpec struct {
+	// Name is the name of the topic.
+	Name string
+	// Partitions is the number of partitions of the topic. Defaults to 1.
+	Partitions int32
+	// ReplicationFactor is the replication factor of the topic. Defaults to 1.
+	ReplicationFactor int16
+	// Config is a map of topic level configuration overrides, e.g. "retention.ms".
+	Config map[string]string
+}
+
+// topicDetail converts the spec into sarama's topic detail, applying the defaults.
+func (ts TopicSpec) topicDetail() *sarama.TopicDetail {
+	detail := &sarama.TopicDetail{
+		NumPartitions:     ts.Partitions,
+		ReplicationFactor: ts.ReplicationFactor,
+	}
+
+	if detail.NumPartitions <= 0 {
+		detail.NumPartitions = 1
+	}
+
+	if detail.ReplicationFactor <= 0 {
+		detail.ReplicationFactor = 1
+	}
+
+	if len(ts.Config) > 0 {
+		detail.ConfigEntries = make(map[string]*string, len(ts.Config))
+		for key, value := range ts.Config {
+			value := value
+			detail.ConfigEntries[key] = &value
+		}
+	}
+
+	return detail
+}
+
+// CreateTopics creates the topics defined by the specs, connecting to the brokers of the container.
+// It stops at the first topic that cannot be created, returning an error naming it.
+func (kc *KafkaContainer) CreateTopics(ctx context.Context, specs ...TopicSpec) error {
+	admin, err := kc.clusterAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	defer admin.Close()
+
+	for _, spec := range specs {
+		if err := admin.CreateTopic(spec.Name, spec.topicDetail(), false); err != nil {
+			return fmt.Errorf("create topic %s: %w", spec.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// validateInitialTopics validates that the initial topics have a name, and that the names are unique.
+func validateInitialTopics(settings options) error {
+	names := make(map[string]bool, len(settings.InitialTopics))
+	for i, spec := range settings.InitialTopics {
+		if spec.Name == "" {
+			return fmt.Errorf("topic at index %d: empty name", i)
+		}
+
+		if names[spec.Name] {
+			return fmt.Errorf("duplicate of topic name: %s", spec.Name)
+		}
+		names[spec.Name] = true
+	}
+
+	return nil
+}
+
+// createInitialTopics creates the initial topics, connecting to the external listener of the container.
+// The topics that already exist, e.g. in a reused container, are skipped. If a topic cannot be created,
+// the error includes the last lines of the broker logs.
+func createInitialTopics(ctx context.Context, c testcontainers.Container, settings options, image string) error {
+	admin, err := containerClusterAdmin(ctx, c, settings, image)
+	if err != nil {
+		return err
+	}
+	defer admin.Close()
+
+	for _, spec := range settings.InitialTopics {
+		err := admin.CreateTopic(spec.Name, spec.topicDetail(), false)
+		if err == nil || errors.Is(err, sarama.ErrTopicAlreadyExists) {
+			continue
+		}
+
+		return fmt.Errorf("create topic %s: %w\n%s", spec.Name, err, brokerLogsTail(ctx, c, initialTopicsLogLines))
+	}
+
+	return nil
+}
+
+// ListTopicsOption is a type that can be used to configure the ListTopics call.
+type ListTopicsOption func(*listTopicsOptions)
+
+type listTopicsOptions struct {
+	IncludeInternal bool
+}
+
+// IncludeInternal makes ListTopics return the internal topics of the broker, like __consumer_offsets.
+func IncludeInternal() ListTopicsOption {
+	return func(o *listTopicsOptions) {
+		o.IncludeInternal = true
+	}
+}
+
+// ListTopics returns the sorted names of the topics in the container.
+// Internal topics are filtered out, unless the IncludeInternal option is passed.
+func (kc *KafkaContainer) ListTopics(ctx context.Context, opts ...ListTopicsOption) ([]string, error) {
+	settings := listTopicsOptions{}
+	for _, opt := range opts {
+		opt(&settings)
+	}
+
+	admin, err := kc.clusterAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer admin.Close()
+
+	topics, err := admin.ListTopics()
+	if err != nil {
+		return nil, fmt.Errorf("list topics: %w", err)
+	}
+
+	names := make([]string, 0, len(topics))
+	for name := range topics {
+		names = append(names, name)
+	}
+
+	metadata, err := admin.DescribeTopics(names)
+	if err != nil {
+		return nil, fmt.Errorf("describe topics: %w", err)
+	}
+
+	return topicNames(metadata, settings.IncludeInternal), nil
+}
+
+// DeleteTopics deletes the topics with the given names, connecting to the brokers of the container.
+// It stops at the first topic that cannot be deleted, returning an error naming it.
+// If topic deletion is disabled on the broker, the error wraps ErrTopicDeletionDisabled.
+func (kc *KafkaContainer) DeleteTopics(ctx context.Context, names ...string) error {
+	admin, err := kc.clusterAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	defer admin.Close()
+
+	for _, name := range names {
+		if err := admin.DeleteTopic(name); err != nil {
+			if errors.Is(err, sarama.ErrTopicDeletionDisabled) {
+				return fmt.Errorf("delete topic %s: %w: %w", name, ErrTopicDeletionDisabled, err)
+			}
+			return fmt.Errorf("delete topic %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// WaitForTopic polls the metadata of the broker until the topic exists, e.g. when the application under
+// test creates it asynchronously, or until the context is done. In that case, the error wraps the error of
+// the context, and either ErrTopicNotFound if the broker reported that the topic does not exist, or
+// ErrBrokerUnreachable if the broker could not be queried, on the last attempt.
+func (kc *KafkaContainer) WaitForTopic(ctx context.Context, name string) error {
+	var admin sarama.ClusterAdmin
+	defer func() {
+		if admin != nil {
+			admin.Close()
+		}
+	}()
+
+	ticker := time.NewTicker(topicPollInterval)
+	defer ticker.Stop()
+
+	for {
+		var err error
+		if admin == nil {
+			admin, err = kc.clusterAdmin(ctx)
+			if err != nil {
+				err = fmt.Errorf("%w: %w", ErrBrokerUnreachable, err)
+			}
+		}
+
+		if admin != nil {
+			err = describeTopic(admin, name)
+			if errors.Is(err, ErrBrokerUnreachable) {
+				// the connection is re-established on the next attempt
+				admin.Close()
+				admin = nil
+			}
+		}
+
+		if err == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("wait for topic %s: %w: %w", name, err, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// describeTopic returns nil if the topic exists, ErrTopicNotFound if the broker reports that it does not,
+// and an error wrapping ErrBrokerUnreachable if the metadata of the broker cannot be requested.
+func describeTopic(admin sarama.ClusterAdmin, name string) error {
+	metadata, err := admin.DescribeTopics([]string{name})
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrBrokerUnreachable, err)
+	}
+
+	if len(metadata) == 0 || errors.Is(metadata[0].Err, sarama.ErrUnknownTopicOrPartition) {
+		return ErrTopicNotFound
+	}
+
+	if !errors.Is(metadata[0].Err, sarama.ErrNoError) {
+		return fmt.Errorf("describe topic %s: %w", name, metadata[0].Err)
+	}
+
+	return nil
+}
+
+// topicNames returns the sorted names of the topics in the metadata,
+// skipping the internal ones unless includeInternal is true.
+func topicNames(metadata []*sarama.TopicMetadata, includeInternal bool) []string {
+	names := make([]string, 0, len(metadata))
+	for _, md := range metadata {
+		if md.IsInternal && !includeInternal {
+			continue
+		}
+		names = append(names, md.Name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// containerClusterAdmin returns a new sarama cluster admin connected to the external listener of the
+// container, for the lifecycle hooks running before the KafkaContainer is returned.
+// The caller is responsible for closing it.
+func containerClusterAdmin(ctx context.Context, c testcontainers.Container, settings options, image string) (sarama.ClusterAdmin, error) {
+	brokers, err := containerExternalBrokers(ctx, c, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	config := sarama.NewConfig()
+	config.Version = saramaVersion(image)
+
+	admin, err := sarama.NewClusterAdmin(brokers, config)
+	if err != nil {
+		return nil, fmt.Errorf("new cluster admin: %w", err)
+	}
+
+	return admin, nil
+}
+
+// containerExternalBrokers returns the brokers of the external listener of the container, for the
+// lifecycle hooks running before the KafkaContainer is returned.
+func containerExternalBrokers(ctx context.Context, c testcontainers.Container, settings options) ([]string, error) {
+	host, err := listenerHost(ctx, c, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	port, err := c.MappedPort(ctx, publicPort)
+	if err != nil {
+		return nil, err
+	}
+
+	return []string{hostPort(host, port.Port())}, nil
+}
+
+// clusterAdmin returns a new sarama cluster admin connected to the brokers of the container.
+// The caller is responsible for closing it.
+func (kc *KafkaContainer) clusterAdmin(ctx context.Context) (sarama.ClusterAdmin, error) {
+	brokers, err := kc.Brokers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	admin, err := sarama.NewClusterAdmin(brokers, sarama.NewConfig())
+	if err != nil {
+		return nil, fmt.Errorf("new cluster admin: %w", err)
+	}
+
+	return admin, nil
+}
@@ -0,0 +1,75 @@
+package kafka
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// UnsupportedVersionError is returned when the tag of an official image is lower than the
+// minimum version supporting KRaft mode.
+type UnsupportedVersionError struct {
+	// Image is the repository of the image, e.g. "confluentinc/confluent-local".
+	Image string
+	// Tag is the offending tag of the image.
+	Tag string
+	// MinVersion is the minimum version of the image supporting KRaft mode.
+	MinVersion string
+}
+
+// Error implements the error interface.
+func (e *UnsupportedVersionError) Error() string {
+	return fmt.Sprintf("unsupported version %s of %s: KRaft mode is only available since version %s", e.Tag, e.Image, e.MinVersion)
+}
+
+// parseKafkaVersion extracts the tag of the image and returns the Kafka version it ships, in its
+// semver form, e.g. "v3.5.0". The confluent images follow the Confluent Platform versioning, where
+// 7.x ships Kafka 3.x and 8.x ships Kafka 4.x, keeping the minor version, while the apache/kafka
+// images follow the Kafka one. It returns an UnsupportedVersionError if the tag is lower than the
+// minimum version supporting KRaft mode, i.e. 7.4.0 for the confluent images and 3.7.0 for the
+// apache ones, which are published since that version.
+// It returns an empty version if the image is not an official one, or its tag is not a version,
+// e.g. "latest", not raising an error and letting the image to start.
+func parseKafkaVersion(fqName string) (string, error) {
+	if fqName == "" {
+		return "", errors.New("image cannot be empty")
+	}
+
+	repository, tag := splitImage(fqName)
+
+	var minVersion string
+	switch {
+	case strings.HasSuffix(repository, "confluentinc/confluent-local"):
+		minVersion = "7.4.0"
+	case strings.HasSuffix(repository, "apache/kafka"):
+		minVersion = "3.7.0"
+	default:
+		return "", nil
+	}
+
+	// semver requires the version to start with a "v"
+	version := "v" + strings.TrimPrefix(tag, "v")
+	if !semver.IsValid(version) {
+		return "", nil
+	}
+
+	if semver.Compare(version, "v"+minVersion) < 0 {
+		return "", &UnsupportedVersionError{Image: repository, Tag: tag, MinVersion: minVersion}
+	}
+
+	if strings.HasSuffix(repository, "apache/kafka") {
+		return semver.Canonical(version), nil
+	}
+
+	// the patch version of the confluent platform does not match the Kafka one
+	segments := strings.SplitN(strings.TrimPrefix(semver.MajorMinor(version), "v"), ".", 2)
+	major, err := strconv.Atoi(segments[0])
+	if err != nil {
+		return "", nil
+	}
+
+	return fmt.Sprintf("v%d.%s.0", major-4, segments[1]), nil
+}
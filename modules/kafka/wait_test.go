@@ -0,0 +1,87 @@
+package kafka
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/go-connections/nat"
+
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// mockBrokerTarget is a strategy target exposing the address of a sarama mock broker.
+type mockBrokerTarget struct {
+	wait.StrategyTarget
+	broker *sarama.MockBroker
+}
+
+func (t mockBrokerTarget) Host(context.Context) (string, error) {
+	host, _, err := net.SplitHostPort(t.broker.Addr())
+	return host, err
+}
+
+func (t mockBrokerTarget) MappedPort(context.Context, nat.Port) (nat.Port, error) {
+	_, port, err := net.SplitHostPort(t.broker.Addr())
+	if err != nil {
+		return "", err
+	}
+
+	return nat.NewPort("tcp", port)
+}
+
+func (t mockBrokerTarget) State(context.Context) (*types.ContainerState, error) {
+	return &types.ContainerState{Running: true}, nil
+}
+
+// mockAPIVersions are the versions of the APIs used by the Kafka wait strategy.
+var mockAPIVersions = []sarama.ApiVersionsResponseKey{
+	{ApiKey: 3, MinVersion: 0, MaxVersion: 12},
+	{ApiKey: 10, MinVersion: 0, MaxVersion: 4},
+	{ApiKey: 18, MinVersion: 0, MaxVersion: 3},
+}
+
+// TestQuorumStrategy checks that the Kafka wait strategy used by the module
+// speaks the protocol implemented by sarama's mock broker.
+func TestQuorumStrategy(t *testing.T) {
+	t.Run("ready", func(t *testing.T) {
+		broker := sarama.NewMockBroker(t, 1)
+		defer broker.Close()
+
+		broker.SetHandlerByMap(map[string]sarama.MockResponse{
+			"ApiVersionsRequest": sarama.NewMockApiVersionsResponse(t).SetApiKeys(mockAPIVersions),
+			"MetadataRequest": sarama.NewMockMetadataResponse(t).
+				SetController(broker.BrokerID()).
+				SetBroker(broker.Addr(), broker.BrokerID()),
+			"FindCoordinatorRequest": sarama.NewMockFindCoordinatorResponse(t).
+				SetCoordinator(sarama.CoordinatorGroup, "testcontainers-quorum-check", broker),
+		})
+
+		err := wait.ForKafka().WithQuorum().WithStartupTimeout(10*time.Second).WaitUntilReady(context.Background(), mockBrokerTarget{broker: broker})
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("coordinator not available", func(t *testing.T) {
+		broker := sarama.NewMockBroker(t, 1)
+		defer broker.Close()
+
+		broker.SetHandlerByMap(map[string]sarama.MockResponse{
+			"ApiVersionsRequest": sarama.NewMockApiVersionsResponse(t).SetApiKeys(mockAPIVersions),
+			"MetadataRequest": sarama.NewMockMetadataResponse(t).
+				SetController(broker.BrokerID()).
+				SetBroker(broker.Addr(), broker.BrokerID()),
+			"FindCoordinatorRequest": sarama.NewMockFindCoordinatorResponse(t).
+				SetError(sarama.CoordinatorGroup, "testcontainers-quorum-check", sarama.ErrConsumerCoordinatorNotAvailable),
+		})
+
+		err := wait.ForKafka().WithQuorum().WithStartupTimeout(2*time.Second).WaitUntilReady(context.Background(), mockBrokerTarget{broker: broker})
+		if err == nil {
+			t.Fatal("expected an error when the coordinator is not available, got nil")
+		}
+	})
+}
@@ -0,0 +1,159 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/mod/semver"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// The modes returned by the Mode method of the container.
+const (
+	// ModeKRaft is the mode of the brokers managing their metadata with a KRaft controller quorum
+	ModeKRaft = "kraft"
+	// ModeZookeeper is the mode of the brokers started with WithZookeeper, storing their metadata in ZooKeeper
+	ModeZookeeper = "zookeeper"
+)
+
+const (
+	// defaultZookeeperImage is the ZooKeeper image used when the version of the broker image cannot be matched
+	defaultZookeeperImage = "confluentinc/cp-zookeeper:7.5.0"
+
+	// zookeeperNetworkAlias is the alias of the ZooKeeper container in the network of the broker
+	zookeeperNetworkAlias = "zookeeper"
+	zookeeperClientPort   = "2181"
+
+	// zookeeperStarterScript {
+	zookeeperStarterScriptContent = `#!/bin/bash
+source /etc/confluent/docker/bash-config
+export KAFKA_ADVERTISED_LISTENERS=%s
+echo Starting Kafka ZooKeeper mode
+echo 'if [ -f /etc/kafka/testcontainers.server.properties ]; then cat /etc/kafka/testcontainers.server.properties >> /etc/kafka/kafka.properties; fi' >> /etc/confluent/docker/configure
+echo '' > /etc/confluent/docker/ensure
+/etc/confluent/docker/configure
+exec /etc/confluent/docker/launch`
+	// }
+)
+
+// kraftEnvs are the environment variables of the KRaft mode, removed from the broker in ZooKeeper mode
+var kraftEnvs = []string{
+	"KAFKA_PROCESS_ROLES",
+	"KAFKA_NODE_ID",
+	"KAFKA_CONTROLLER_LISTENER_NAMES",
+	"KAFKA_CONTROLLER_QUORUM_VOTERS",
+	"CLUSTER_ID",
+}
+
+// validateZookeeper validates that the ZooKeeper mode, if enabled, is used with an image still supporting it,
+// i.e. a confluentinc/cp-kafka image lower than 8.0.0, and without the options relying on the KRaft mode.
+func validateZookeeper(settings options, image string) error {
+	if !settings.Zookeeper {
+		return nil
+	}
+
+	repository, tag := splitImage(image)
+	switch {
+	case strings.HasSuffix(repository, "confluentinc/confluent-local"), strings.HasSuffix(repository, "apache/kafka"):
+		return fmt.Errorf("%s is a KRaft-only image, use a confluentinc/cp-kafka image instead", repository)
+	case strings.HasSuffix(repository, "confluentinc/cp-kafka"):
+		// semver requires the version to start with a "v"
+		version := "v" + strings.TrimPrefix(tag, "v")
+		if semver.IsValid(version) && semver.Compare(version, "v8.0.0") >= 0 {
+			return fmt.Errorf("%s:%s is a KRaft-only image, as ZooKeeper was removed in Kafka 4.0", repository, tag)
+		}
+	}
+
+	switch {
+	case settings.clusterSize > 0:
+		return errors.New("not supported by RunCluster")
+	case settings.ReuseName != "":
+		return errors.New("the ZooKeeper container cannot be reused")
+	case settings.SASL != nil && settings.SASL.Mechanism != saslMechanismPlain:
+		return errors.New("the SCRAM credentials are created when formatting the KRaft storage, use WithSASLPlain instead")
+	case settings.ExposedController:
+		return errors.New("there is no controller listener in ZooKeeper mode")
+	case settings.RestProxy:
+		return errors.New("the rest proxy is not bundled in the cp-kafka images")
+	}
+
+	return nil
+}
+
+// zookeeperEnvs returns the environment variables needed to run the broker in ZooKeeper mode, removing
+// the controller listener from the current listeners, and connecting the broker to the ZooKeeper container.
+func zookeeperEnvs(current map[string]string) map[string]string {
+	withoutController := func(value string) string {
+		var entries []string
+		for _, entry := range strings.Split(value, ",") {
+			entry = strings.TrimSpace(entry)
+			if entry != "" && !strings.HasPrefix(entry, "CONTROLLER:") {
+				entries = append(entries, entry)
+			}
+		}
+
+		return strings.Join(entries, ",")
+	}
+
+	return map[string]string{
+		"KAFKA_LISTENERS":                      withoutController(current["KAFKA_LISTENERS"]),
+		"KAFKA_REST_BOOTSTRAP_SERVERS":         withoutController(current["KAFKA_REST_BOOTSTRAP_SERVERS"]),
+		"KAFKA_LISTENER_SECURITY_PROTOCOL_MAP": withoutController(current["KAFKA_LISTENER_SECURITY_PROTOCOL_MAP"]),
+		"KAFKA_ZOOKEEPER_CONNECT":              zookeeperNetworkAlias + ":" + zookeeperClientPort,
+	}
+}
+
+// zookeeperStarterScript returns the content of the starter script of a broker in ZooKeeper mode, advertising
+// the given listeners. As the metadata are stored in ZooKeeper, the storage is not formatted.
+func zookeeperStarterScript(advertisedListeners string, hook []string) string {
+	return withStartupScriptHook(fmt.Sprintf(zookeeperStarterScriptContent, advertisedListeners), hook)
+}
+
+// readyLog returns the regexp of the line logged by the broker once it's ready for the mode it runs in.
+func readyLog(settings options) string {
+	if settings.Zookeeper {
+		return `.*\[KafkaServer id=\d+\] started.*`
+	}
+
+	return ".*Transitioning from RECOVERY to RUNNING.*"
+}
+
+// zookeeperImage returns the ZooKeeper image matching the version of the cp-kafka broker image,
+// or the default one for the rest of the images.
+func zookeeperImage(brokerImage string) string {
+	repository, tag := splitImage(brokerImage)
+	if tag == "" || !strings.HasSuffix(repository, "confluentinc/cp-kafka") {
+		return defaultZookeeperImage
+	}
+
+	return "confluentinc/cp-zookeeper:" + tag
+}
+
+// runZookeeper starts the ZooKeeper container in the given network, with the alias the broker connects to,
+// and waits for it to accept the client connections.
+func runZookeeper(ctx context.Context, image string, networkName string) (testcontainers.Container, error) {
+	req := testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:          image,
+			Networks:       []string{networkName},
+			NetworkAliases: map[string][]string{networkName: {zookeeperNetworkAlias}},
+			Env: map[string]string{
+				"ZOOKEEPER_CLIENT_PORT": zookeeperClientPort,
+				"ZOOKEEPER_TICK_TIME":   "2000",
+			},
+			WaitingFor: wait.ForLog(".*binding to port.*" + zookeeperClientPort + ".*").AsRegexp(),
+		},
+		Started: true,
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, req)
+	if err != nil {
+		return container, fmt.Errorf("zookeeper: %w", err)
+	}
+
+	return container, nil
+}
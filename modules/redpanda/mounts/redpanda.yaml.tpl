@@ -21,7 +21,7 @@ redpanda:
 
   {{ range .KafkaAPI.Listeners }}
     - address: 0.0.0.0
-      name: {{ .Address }}
+      name: {{ .Name }}
       port: {{ .Port }}
       authentication_method: {{ .AuthenticationMethod }}
   {{ end }}
@@ -35,7 +35,7 @@ redpanda:
       port: 9093
  {{ range .KafkaAPI.Listeners }} 
     - address: {{ .Address }}
-      name: {{ .Address }}
+      name: {{ .Name }}
       port: {{ .Port }}
   {{ end }} 
   
@@ -43,6 +43,10 @@ type options struct {
 	// Listeners is a list of custom listeners that can be provided to access the
 	// containers form within docker networks
 	Listeners []listener
+
+	// namedListeners is the list of custom listeners added by WithListeners, validated
+	// and appended to Listeners when the container is started.
+	namedListeners []Listener
 }
 
 func defaultOptions() options {
@@ -149,9 +153,30 @@ func WithListener(lis string) Option {
 
 	return func(o *options) {
 		o.Listeners = append(o.Listeners, listener{
+			Name:                 host,
 			Address:              host,
 			Port:                 portInt,
 			AuthenticationMethod: o.KafkaAuthenticationMethod,
 		})
 	}
 }
+
+// Listener is a custom Kafka API listener of the Redpanda container. It mirrors kafka.KafkaListener,
+// so that the same listeners can be registered on both the kafka and the redpanda modules.
+type Listener struct {
+	Name string
+	Ip   string
+	Port string
+}
+
+// WithListeners adds custom listeners to the Redpanda container, with the same validation as
+// the kafka.WithListener option: the names, which are case insensitive and uppercased, and the
+// ports must be unique, and must not collide with the default listeners, external and internal,
+// or with the ports of the other APIs. As for WithListener, the hosts are aliases to all networks,
+// so at least one network must be attached to the container. The listeners are validated when
+// starting the container.
+func WithListeners(listeners []Listener) Option {
+	return func(o *options) {
+		o.namedListeners = append(o.namedListeners, listeners...)
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"golang.org/x/mod/semver"
 
 	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/internal/kafkalistener"
 	"github.com/testcontainers/testcontainers-go/wait"
 )
 
@@ -35,6 +36,7 @@ var (
 
 const (
 	defaultKafkaAPIPort       = "9092/tcp"
+	defaultInternalKafkaPort  = "9093/tcp"
 	defaultAdminAPIPort       = "9644/tcp"
 	defaultSchemaRegistryPort = "8081/tcp"
 	defaultDockerKafkaApiPort = "29092"
@@ -113,6 +115,12 @@ func RunContainer(ctx context.Context, opts ...testcontainers.ContainerCustomize
 
 	// 4. Register extra kafka listeners if provided, network aliases will be
 	// set
+	namedListeners, err := trimValidateListeners(settings.namedListeners, settings.Listeners, settings.KafkaAuthenticationMethod)
+	if err != nil {
+		return nil, fmt.Errorf("invalid listeners: %w", err)
+	}
+	settings.Listeners = append(settings.Listeners, namedListeners...)
+
 	if err := registerListeners(ctx, settings, req); err != nil {
 		return nil, fmt.Errorf("failed to register listeners: %w", err)
 	}
@@ -313,6 +321,46 @@ func registerListeners(ctx context.Context, settings options, req testcontainers
 	return nil
 }
 
+// trimValidateListeners trims the listeners added by WithListeners, uppercasing their names, and validates
+// them as the kafka module does: their names and ports must be unique, not colliding with the default
+// listeners, the other APIs or the listeners added by WithListener, and their hosts and ports must be valid.
+// It returns them converted to the listeners rendered in the node config, using the authentication method
+// of the Kafka API.
+func trimValidateListeners(listeners []Listener, registered []listener, authenticationMethod string) ([]listener, error) {
+	// default listeners of the Kafka API, and the other APIs, as configured in redpanda.yaml
+	names := []string{"external", "internal"}
+	var ports []int
+	for _, port := range []nat.Port{defaultKafkaAPIPort, defaultInternalKafkaPort, defaultAdminAPIPort, defaultSchemaRegistryPort} {
+		ports = append(ports, port.Int())
+	}
+
+	for _, item := range registered {
+		names = append(names, item.Name)
+		ports = append(ports, item.Port)
+	}
+
+	validator := kafkalistener.NewValidator(names, ports)
+
+	result := make([]listener, 0, len(listeners))
+	for i, item := range listeners {
+		l := kafkalistener.Listener{Name: item.Name, Ip: item.Ip, Port: item.Port}.Trim()
+
+		port, err := validator.Validate(i, l)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, listener{
+			Name:                 l.Name,
+			Address:              l.Ip,
+			Port:                 port,
+			AuthenticationMethod: authenticationMethod,
+		})
+	}
+
+	return result, nil
+}
+
 // renderNodeConfig renders the redpanda.yaml node config and returns it as
 // byte array.
 func renderNodeConfig(settings options, hostIP string, advertisedKafkaPort int) ([]byte, error) {
@@ -371,6 +419,7 @@ type redpandaConfigTplParamsSchemaRegistry struct {
 }
 
 type listener struct {
+	Name                 string
 	Address              string
 	Port                 int
 	AuthenticationMethod string
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_isAtLeastVersion(t *testing.T) {
@@ -54,3 +55,118 @@ func Test_isAtLeastVersion(t *testing.T) {
 		})
 	}
 }
+
+func Test_trimValidateListeners(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		listeners, err := trimValidateListeners([]Listener{
+			{Name: " Broker ", Ip: " redpanda ", Port: " 29092 "},
+		}, nil, "sasl")
+		require.NoError(t, err)
+
+		assert.Equal(t, []listener{
+			{Name: "BROKER", Address: "redpanda", Port: 29092, AuthenticationMethod: "sasl"},
+		}, listeners)
+	})
+
+	// the same cases as the ones of the listeners of the kafka module
+	tests := []struct {
+		name       string
+		listeners  []Listener
+		registered []listener
+		wantErr    string
+	}{
+		{
+			name:      "empty name",
+			listeners: []Listener{{Name: " ", Ip: "redpanda", Port: "29092"}},
+			wantErr:   "listener at index 0: empty name",
+		},
+		{
+			name:      "empty host",
+			listeners: []Listener{{Name: "broker", Port: "29092"}},
+			wantErr:   "listener BROKER: empty host",
+		},
+		{
+			name:      "wildcard host",
+			listeners: []Listener{{Name: "broker", Ip: "0.0.0.0", Port: "29092"}},
+			wantErr:   "listener BROKER: host 0.0.0.0 collides with the default listeners",
+		},
+		{
+			name:      "loopback host",
+			listeners: []Listener{{Name: "broker", Ip: "LocalHost", Port: "29092"}},
+			wantErr:   "listener BROKER: host LocalHost collides with the default listeners",
+		},
+		{
+			name:      "ipv4 loopback host",
+			listeners: []Listener{{Name: "broker", Ip: "127.0.0.1", Port: "29092"}},
+			wantErr:   "listener BROKER: host 127.0.0.1 collides with the default listeners",
+		},
+		{
+			name:      "ipv6 loopback host",
+			listeners: []Listener{{Name: "broker", Ip: "[::1]", Port: "29092"}},
+			wantErr:   "listener BROKER: host [::1] collides with the default listeners",
+		},
+		{
+			name:      "non-numeric port",
+			listeners: []Listener{{Name: "broker", Ip: "redpanda", Port: "abc"}},
+			wantErr:   `listener BROKER: invalid port "abc"`,
+		},
+		{
+			name:      "out of range port",
+			listeners: []Listener{{Name: "broker", Ip: "redpanda", Port: "99092"}},
+			wantErr:   `listener BROKER: invalid port "99092"`,
+		},
+		{
+			name:      "reserved name",
+			listeners: []Listener{{Name: "\tExTeRnAl\n", Ip: "redpanda", Port: "29092"}},
+			wantErr:   "listener EXTERNAL: name EXTERNAL is reserved",
+		},
+		{
+			name:      "reserved kafka port",
+			listeners: []Listener{{Name: "broker", Ip: "redpanda", Port: "9092"}},
+			wantErr:   "listener BROKER: port 9092 is reserved",
+		},
+		{
+			name:      "reserved internal port",
+			listeners: []Listener{{Name: "broker", Ip: "redpanda", Port: "9093"}},
+			wantErr:   "listener BROKER: port 9093 is reserved",
+		},
+		{
+			name:      "reserved admin port",
+			listeners: []Listener{{Name: "broker", Ip: "redpanda", Port: "9644"}},
+			wantErr:   "listener BROKER: port 9644 is reserved",
+		},
+		{
+			name:      "reserved schema registry port",
+			listeners: []Listener{{Name: "broker", Ip: "redpanda", Port: "8081"}},
+			wantErr:   "listener BROKER: port 8081 is reserved",
+		},
+		{
+			name:      "duplicate name",
+			listeners: []Listener{{Name: "broker", Ip: "redpanda", Port: "29092"}, {Name: "BROKER", Ip: "redpanda", Port: "29093"}},
+			wantErr:   "duplicate of listener name: BROKER",
+		},
+		{
+			name:      "duplicate port",
+			listeners: []Listener{{Name: "broker", Ip: "redpanda", Port: "29092"}, {Name: "broker2", Ip: "redpanda", Port: "29092"}},
+			wantErr:   "duplicate of listener port: 29092",
+		},
+		{
+			name:       "registered name",
+			listeners:  []Listener{{Name: "redpanda", Ip: "redpanda", Port: "29093"}},
+			registered: []listener{{Name: "redpanda", Address: "redpanda", Port: 29092}},
+			wantErr:    "listener REDPANDA: name REDPANDA is reserved",
+		},
+		{
+			name:       "registered port",
+			listeners:  []Listener{{Name: "broker", Ip: "redpanda", Port: "29092"}},
+			registered: []listener{{Name: "redpanda", Address: "redpanda", Port: 29092}},
+			wantErr:    "listener BROKER: port 29092 is reserved",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := trimValidateListeners(tt.listeners, tt.registered, "none")
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
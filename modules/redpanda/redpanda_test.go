@@ -533,6 +533,83 @@ func TestRedpandaListener_Simple(t *testing.T) {
 	})
 }
 
+func TestRedpandaListeners(t *testing.T) {
+	ctx := context.Background()
+
+	rpNetwork, err := network.New(ctx, network.WithCheckDuplicate())
+	require.NoError(t, err)
+
+	// withListenersRP {
+	container, err := redpanda.RunContainer(ctx,
+		testcontainers.WithImage("redpandadata/redpanda:v23.2.18"),
+		network.WithNetwork([]string{"redpanda-host"}, rpNetwork),
+		redpanda.WithListeners([]redpanda.Listener{
+			{
+				Name: "BROKER",
+				Ip:   "redpanda",
+				Port: "29092",
+			},
+		}),
+	)
+	// }
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		if err := container.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate redpanda container: %s", err)
+		}
+
+		if err := rpNetwork.Remove(ctx); err != nil {
+			t.Fatalf("failed to remove network: %s", err)
+		}
+	})
+
+	// the listener is reachable through the network alias of the container
+	code, _, err := container.Exec(ctx, []string{"rpk", "cluster", "info", "--brokers", "redpanda:29092"})
+	require.NoError(t, err)
+	require.Zero(t, code)
+}
+
+func TestRedpanda_listenersValidation(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		listeners []redpanda.Listener
+	}{
+		{
+			name:      "reserved listener port duplication",
+			listeners: []redpanda.Listener{{Name: "broker", Ip: "redpanda", Port: "9092"}},
+		},
+		{
+			name:      "reserved api port duplication",
+			listeners: []redpanda.Listener{{Name: "broker", Ip: "redpanda", Port: "9644"}},
+		},
+		{
+			name:      "reserved listener name duplication",
+			listeners: []redpanda.Listener{{Name: "  InTeRnAl   ", Ip: "redpanda", Port: "29092"}},
+		},
+		{
+			name:      "port duplication",
+			listeners: []redpanda.Listener{{Name: "test", Ip: "redpanda", Port: "29092"}, {Name: "test2", Ip: "redpanda", Port: "29092"}},
+		},
+		{
+			name:      "name duplication",
+			listeners: []redpanda.Listener{{Name: "test", Ip: "redpanda", Port: "29092"}, {Name: "TEST", Ip: "redpanda", Port: "29093"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := redpanda.RunContainer(ctx,
+				testcontainers.WithImage("redpandadata/redpanda:v23.2.18"),
+				redpanda.WithListeners(tt.listeners),
+			)
+			require.ErrorContains(t, err, "invalid listeners")
+		})
+	}
+}
+
 func TestRedpandaListener_InvalidPort(t *testing.T) {
 	ctx := context.Background()
 
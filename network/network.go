@@ -3,6 +3,7 @@ package network
 import (
 	"context"
 	"fmt"
+	"slices"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/network"
@@ -107,6 +108,33 @@ func WithEnableIPv6() CustomizeNetworkOption {
 	}
 }
 
+// WithIPv6 allows to set the network as IPv6 enabled, adding a random IPv6 subnet from the
+// unique local addresses range (fd00::/8) to its IPAM configuration, so the network is dual-stack.
+// Please use this option if and only if IPv6 is enabled on the Docker daemon.
+func WithIPv6() CustomizeNetworkOption {
+	return func(original *types.NetworkCreate) error {
+		original.EnableIPv6 = true
+
+		if original.IPAM == nil {
+			original.IPAM = &network.IPAM{Driver: "default"}
+		}
+
+		original.IPAM.Config = append(original.IPAM.Config, network.IPAMConfig{
+			Subnet: randomIPv6Subnet(),
+		})
+
+		return nil
+	}
+}
+
+// randomIPv6Subnet returns a random /64 subnet from the unique local addresses range,
+// to avoid collisions between the networks created by concurrent tests.
+func randomIPv6Subnet() string {
+	id := uuid.New()
+
+	return fmt.Sprintf("fd%02x:%02x%02x:%02x%02x::/64", id[0], id[1], id[2], id[3], id[4])
+}
+
 // WithInternal allows to set the network as internal.
 func WithInternal() CustomizeNetworkOption {
 	return func(original *types.NetworkCreate) error {
@@ -155,6 +183,25 @@ func WithNetwork(aliases []string, nw *testcontainers.DockerNetwork) testcontain
 	}
 }
 
+// WithAliases attaches the container to an already existing network, if it's not attached yet,
+// adding the given aliases to the ones of the container on that network.
+func WithAliases(nw *testcontainers.DockerNetwork, aliases ...string) testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) error {
+		networkName := nw.Name
+
+		if !slices.Contains(req.Networks, networkName) {
+			req.Networks = append(req.Networks, networkName)
+		}
+
+		if req.NetworkAliases == nil {
+			req.NetworkAliases = make(map[string][]string)
+		}
+		req.NetworkAliases[networkName] = append(req.NetworkAliases[networkName], aliases...)
+
+		return nil
+	}
+}
+
 // WithNewNetwork creates a new network with random name and customizers, and attaches the container to it.
 // Finally it sets the network alias on that network to the given alias.
 func WithNewNetwork(ctx context.Context, aliases []string, opts ...NetworkCustomizer) testcontainers.CustomizeRequestOption {
@@ -3,7 +3,9 @@ package network_test
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
+	"net/netip"
 	"testing"
 	"time"
 
@@ -14,6 +16,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/testcontainers/testcontainers-go"
+	tcexec "github.com/testcontainers/testcontainers-go/exec"
 	"github.com/testcontainers/testcontainers-go/internal/core"
 	"github.com/testcontainers/testcontainers-go/network"
 	"github.com/testcontainers/testcontainers-go/wait"
@@ -406,6 +409,78 @@ func TestNew_withOptions(t *testing.T) {
 	assert.Equal(t, ipamConfig, foundNetwork.IPAM)
 }
 
+func TestWithIPv6(t *testing.T) {
+	t.Run("new ipam", func(t *testing.T) {
+		nc := types.NetworkCreate{}
+		require.NoError(t, network.WithIPv6()(&nc))
+
+		assert.True(t, nc.EnableIPv6)
+		require.NotNil(t, nc.IPAM)
+		assert.Equal(t, "default", nc.IPAM.Driver)
+		require.Len(t, nc.IPAM.Config, 1)
+
+		prefix, err := netip.ParsePrefix(nc.IPAM.Config[0].Subnet)
+		require.NoError(t, err)
+		assert.True(t, prefix.Addr().Is6())
+		assert.True(t, prefix.Addr().IsPrivate())
+		assert.Equal(t, 64, prefix.Bits())
+	})
+
+	t.Run("existing ipam", func(t *testing.T) {
+		nc := types.NetworkCreate{}
+		require.NoError(t, network.WithIPAM(&dockernetwork.IPAM{
+			Driver: "default",
+			Config: []dockernetwork.IPAMConfig{{Subnet: "10.1.1.0/24"}},
+		})(&nc))
+		require.NoError(t, network.WithIPv6()(&nc))
+
+		require.Len(t, nc.IPAM.Config, 2)
+		assert.Equal(t, "10.1.1.0/24", nc.IPAM.Config[0].Subnet)
+	})
+}
+
+func TestNew_withIPv6(t *testing.T) {
+	ctx := context.Background()
+
+	// newNetworkWithIPv6 {
+	nw, err := network.New(ctx, network.WithIPv6())
+	// }
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, nw.Remove(ctx))
+	}()
+
+	newAlpine := func() testcontainers.Container {
+		c, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+			ContainerRequest: testcontainers.ContainerRequest{
+				Image:      "alpine",
+				Entrypoint: []string{"tail", "-f", "/dev/null"},
+				Networks:   []string{nw.Name},
+			},
+			Started: true,
+		})
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			require.NoError(t, c.Terminate(ctx))
+		})
+
+		return c
+	}
+
+	target := newAlpine()
+	peer := newAlpine()
+
+	inspect, err := target.Inspect(ctx)
+	require.NoError(t, err)
+
+	ipv6 := inspect.NetworkSettings.Networks[nw.Name].GlobalIPv6Address
+	require.NotEmpty(t, ipv6)
+
+	code, _, err := peer.Exec(ctx, []string{"ping", "-6", "-c", "1", "-W", "5", ipv6})
+	require.NoError(t, err)
+	assert.Zero(t, code)
+}
+
 func TestWithNetwork(t *testing.T) {
 	// first create the network to be reused
 	nw, err := network.New(context.Background(), network.WithCheckDuplicate(), network.WithLabels(map[string]string{"network-type": "unique"}))
@@ -456,6 +531,87 @@ func TestWithNetwork(t *testing.T) {
 	assert.Equal(t, expectedLabels, newNetwork.Labels)
 }
 
+func TestWithAliases(t *testing.T) {
+	nw := &testcontainers.DockerNetwork{
+		Name: "test-network",
+	}
+
+	req := testcontainers.GenericContainerRequest{}
+
+	require.NoError(t, network.WithNetwork([]string{"kafka"}, nw)(&req))
+	require.NoError(t, network.WithAliases(nw, "broker", "kafka-1")(&req))
+
+	assert.Equal(t, []string{"test-network"}, req.Networks)
+	assert.Equal(t, map[string][]string{"test-network": {"kafka", "broker", "kafka-1"}}, req.NetworkAliases)
+}
+
+func TestDockerNetwork_Aliases(t *testing.T) {
+	ctx := context.Background()
+
+	nw, err := network.New(ctx)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, nw.Remove(ctx))
+	}()
+
+	broker := testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        nginxAlpineImage,
+			ExposedPorts: []string{nginxDefaultPort},
+			WaitingFor:   wait.ForListeningPort(nginxDefaultPort),
+		},
+		Started: true,
+	}
+	require.NoError(t, network.WithAliases(nw, "kafka", "broker")(&broker))
+
+	brokerContainer, err := testcontainers.GenericContainer(ctx, broker)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, brokerContainer.Terminate(ctx))
+	}()
+
+	// networkAliases {
+	aliases, err := nw.Aliases(ctx, brokerContainer.GetContainerID())
+	// }
+	require.NoError(t, err)
+	assert.Subset(t, aliases, []string{"kafka", "broker"})
+
+	// a second container in the network reaches the first one by its alias
+	client := testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:      "alpine",
+			Entrypoint: []string{"tail", "-f", "/dev/null"},
+		},
+		Started: true,
+	}
+	require.NoError(t, network.WithAliases(nw)(&client))
+
+	clientContainer, err := testcontainers.GenericContainer(ctx, client)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, clientContainer.Terminate(ctx))
+	}()
+
+	code, reader, err := clientContainer.Exec(ctx, []string{"wget", "-q", "-O-", "http://broker"}, tcexec.Multiplexed())
+	require.NoError(t, err)
+	require.Zero(t, code)
+
+	body, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), "Welcome to nginx")
+
+	t.Run("not attached", func(t *testing.T) {
+		other, err := network.New(ctx)
+		require.NoError(t, err)
+		defer func() {
+			require.NoError(t, other.Remove(ctx))
+		}()
+
+		_, err = other.Aliases(ctx, brokerContainer.GetContainerID())
+		require.ErrorContains(t, err, "is not attached to network "+other.Name)
+	})
+}
+
 func TestWithSyntheticNetwork(t *testing.T) {
 	nw := &testcontainers.DockerNetwork{
 		Name: "synthetic-network",
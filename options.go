@@ -3,11 +3,15 @@ package testcontainers
 import (
 	"context"
 	"fmt"
+	"regexp"
+	"slices"
+	"strings"
 	"time"
 
 	"dario.cat/mergo"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/registry"
 
 	tcexec "github.com/testcontainers/testcontainers-go/exec"
 	"github.com/testcontainers/testcontainers-go/internal/core"
@@ -58,6 +62,16 @@ func WithEndpointSettingsModifier(modifier func(settings map[string]*network.End
 	}
 }
 
+// WithEntrypoint sets the entrypoint for a container, replacing the one of the image.
+// The command of the container is not modified, so it's passed as arguments to the entrypoint.
+func WithEntrypoint(entrypoint ...string) CustomizeRequestOption {
+	return func(req *GenericContainerRequest) error {
+		req.Entrypoint = entrypoint
+
+		return nil
+	}
+}
+
 // WithEnv sets the environment variables for a container.
 // If the environment variable already exists, it will be overridden.
 func WithEnv(envs map[string]string) CustomizeRequestOption {
@@ -74,6 +88,62 @@ func WithEnv(envs map[string]string) CustomizeRequestOption {
 	}
 }
 
+// HostGateway is the special IP of WithExtraHost resolved by the Docker daemon to the IP of the host,
+// so the container can reach the services running on the host.
+const HostGateway = "host-gateway"
+
+// WithExtraHost adds an entry mapping the host to the IP in the /etc/hosts file of the container,
+// like --add-host. The IP can be HostGateway to map the host to the IP of the Docker host.
+// It does not override the HostConfigModifier of the request, which is run before.
+func WithExtraHost(host string, ip string) CustomizeRequestOption {
+	return func(req *GenericContainerRequest) error {
+		if host == "" || ip == "" {
+			return fmt.Errorf("extra host and ip must not be empty: %q:%q", host, ip)
+		}
+
+		appendHostConfigModifier(req, func(hostConfig *container.HostConfig) {
+			hostConfig.ExtraHosts = append(hostConfig.ExtraHosts, host+":"+ip)
+		})
+
+		return nil
+	}
+}
+
+// WithHealthCheck sets the healthcheck of the container, replacing the HEALTHCHECK of the image, so
+// wait.ForHealthCheck can be used with the images without one. The test follows the format of Docker,
+// e.g. []string{"CMD-SHELL", "nc -z localhost 9092"}. It runs every interval, failing if it takes longer
+// than the timeout, and the container is unhealthy after retries consecutive failures. A zero interval,
+// timeout or retries uses the default of Docker.
+// It does not override the ConfigModifier of the request, which is run before.
+func WithHealthCheck(test []string, interval time.Duration, timeout time.Duration, retries int) CustomizeRequestOption {
+	return func(req *GenericContainerRequest) error {
+		if len(test) == 0 || !slices.Contains([]string{"CMD", "CMD-SHELL", "NONE"}, test[0]) {
+			return fmt.Errorf("healthcheck test must start with CMD, CMD-SHELL or NONE: %q", test)
+		}
+
+		if interval < 0 || timeout < 0 || retries < 0 {
+			return fmt.Errorf("healthcheck interval, timeout and retries must not be negative: %s, %s, %d", interval, timeout, retries)
+		}
+
+		previous := req.ConfigModifier
+		if previous == nil {
+			previous = func(config *container.Config) {}
+		}
+
+		req.ConfigModifier = func(config *container.Config) {
+			previous(config)
+			config.Healthcheck = &container.HealthConfig{
+				Test:     test,
+				Interval: interval,
+				Timeout:  timeout,
+				Retries:  retries,
+			}
+		}
+
+		return nil
+	}
+}
+
 // WithHostConfigModifier allows to override the default host config
 func WithHostConfigModifier(modifier func(hostConfig *container.HostConfig)) CustomizeRequestOption {
 	return func(req *GenericContainerRequest) error {
@@ -168,6 +238,29 @@ func WithImageSubstitutors(fn ...ImageSubstitutor) CustomizeRequestOption {
 	}
 }
 
+// WithLabels sets the labels for a container. If the label already exists, it will be overridden.
+// The labels of Testcontainers, prefixed with "org.testcontainers", are reserved, as the reaper
+// relies on them, so an error is returned for them.
+func WithLabels(labels map[string]string) CustomizeRequestOption {
+	return func(req *GenericContainerRequest) error {
+		for key := range labels {
+			if key == core.LabelBase || strings.HasPrefix(key, core.LabelBase+".") {
+				return fmt.Errorf("label %s is reserved by testcontainers", key)
+			}
+		}
+
+		if req.Labels == nil {
+			req.Labels = map[string]string{}
+		}
+
+		for key, val := range labels {
+			req.Labels[key] = val
+		}
+
+		return nil
+	}
+}
+
 // WithLogConsumers sets the log consumers for a container
 func WithLogConsumers(consumer ...LogConsumer) CustomizeRequestOption {
 	return func(req *GenericContainerRequest) error {
@@ -180,6 +273,156 @@ func WithLogConsumers(consumer ...LogConsumer) CustomizeRequestOption {
 	}
 }
 
+// WithRegistryAuth sets the credentials to pull the image from the given registry, e.g. "myregistry.local:5000",
+// taking precedence over the credentials of the docker config for that registry.
+func WithRegistryAuth(registryHost string, username string, password string) CustomizeRequestOption {
+	return func(req *GenericContainerRequest) error {
+		if req.RegistryAuthConfigs == nil {
+			req.RegistryAuthConfigs = map[string]registry.AuthConfig{}
+		}
+
+		req.RegistryAuthConfigs[registryHost] = registry.AuthConfig{
+			Username:      username,
+			Password:      password,
+			ServerAddress: registryHost,
+		}
+
+		return nil
+	}
+}
+
+// containerNameRegexp matches the names accepted by the Docker daemon for the containers
+var containerNameRegexp = regexp.MustCompile(`^/?[a-zA-Z0-9][a-zA-Z0-9_.-]+$`)
+
+// WithName sets a stable name for the container, e.g. to find it when debugging, or to reuse it with the
+// Reuse field of the request. The name must be a valid container name, and it cannot start with the prefix
+// of the reaper containers. If a container with the same name already exists and the reuse is not enabled,
+// GenericContainer fails with an error.
+func WithName(name string) CustomizeRequestOption {
+	return func(req *GenericContainerRequest) error {
+		if !containerNameRegexp.MatchString(name) {
+			return fmt.Errorf("invalid container name: %q", name)
+		}
+
+		if strings.HasPrefix(strings.TrimPrefix(name, "/"), reaperContainerNamePrefix) {
+			return fmt.Errorf("container name %s: the %s prefix is reserved for the reaper", name, reaperContainerNamePrefix)
+		}
+
+		req.Name = name
+
+		return nil
+	}
+}
+
+// WithTerminateTimeout gives the container the timeout to stop when it's terminated, before it's removed:
+// Terminate sends SIGTERM to the container, and kills it only if it's still running once the timeout is over,
+// e.g. so a database or a broker can flush its data. Without it, the container is killed right away.
+// It can be overridden for a single call with the StopTimeout option of Terminate.
+func WithTerminateTimeout(timeout time.Duration) CustomizeRequestOption {
+	return func(req *GenericContainerRequest) error {
+		if timeout < 0 {
+			return fmt.Errorf("invalid terminate timeout: %s", timeout)
+		}
+
+		req.TerminateTimeout = timeout
+
+		return nil
+	}
+}
+
+// WithReaperDisabled keeps the reaper from terminating the container at the end of the test session,
+// e.g. for long-lived containers shared by several test runs. The user owns the cleanup of the container,
+// which must be terminated explicitly, e.g. with CleanupContainer.
+func WithReaperDisabled() CustomizeRequestOption {
+	return func(req *GenericContainerRequest) error {
+		req.ReaperDisabled = true
+
+		return nil
+	}
+}
+
+// WithReadOnlyRootFilesystem mounts the root filesystem of the container as read-only.
+// Use WithTmpfs to keep the directories the container writes to writable.
+// It does not override the HostConfigModifier of the request, which is run before.
+func WithReadOnlyRootFilesystem() CustomizeRequestOption {
+	return func(req *GenericContainerRequest) error {
+		appendHostConfigModifier(req, func(hostConfig *container.HostConfig) {
+			hostConfig.ReadonlyRootfs = true
+		})
+
+		return nil
+	}
+}
+
+// WithMemoryLimit limits the memory of the container to the given bytes, including the swap,
+// so the container is killed when it runs out of memory instead of swapping.
+// It does not override the HostConfigModifier of the request, which is run before.
+func WithMemoryLimit(bytes int64) CustomizeRequestOption {
+	return func(req *GenericContainerRequest) error {
+		if bytes <= 0 {
+			return fmt.Errorf("memory limit must be positive: %d", bytes)
+		}
+
+		appendHostConfigModifier(req, func(hostConfig *container.HostConfig) {
+			hostConfig.Memory = bytes
+			hostConfig.MemorySwap = bytes
+		})
+
+		return nil
+	}
+}
+
+// WithCPUQuota limits the CPU of the container to quota microseconds per period microseconds,
+// e.g. a quota of 50000 and a period of 100000 limit the container to half a CPU.
+// It does not override the HostConfigModifier of the request, which is run before.
+func WithCPUQuota(quota int64, period int64) CustomizeRequestOption {
+	return func(req *GenericContainerRequest) error {
+		if quota <= 0 || period <= 0 {
+			return fmt.Errorf("cpu quota and period must be positive: %d/%d", quota, period)
+		}
+
+		appendHostConfigModifier(req, func(hostConfig *container.HostConfig) {
+			hostConfig.CPUQuota = quota
+			hostConfig.CPUPeriod = period
+		})
+
+		return nil
+	}
+}
+
+// appendHostConfigModifier sets a HostConfigModifier in the request running the existing one, or the
+// default one setting the deprecated fields of the request if none, and then the given one.
+func appendHostConfigModifier(req *GenericContainerRequest, modifier func(hostConfig *container.HostConfig)) {
+	previous := req.HostConfigModifier
+	if previous == nil {
+		// the deprecated fields are read when the container is created, as they can be set later
+		previous = func(hostConfig *container.HostConfig) {
+			defaultHostConfigModifier(req.ContainerRequest)(hostConfig)
+		}
+	}
+
+	req.HostConfigModifier = func(hostConfig *container.HostConfig) {
+		previous(hostConfig)
+		modifier(hostConfig)
+	}
+}
+
+// WithTmpfs mounts tmpfs filesystems in the container, where the keys are the paths in the container
+// and the values are the mount options, like "rw,size=64m". If the path already exists, it will be overridden.
+func WithTmpfs(tmpfs map[string]string) CustomizeRequestOption {
+	return func(req *GenericContainerRequest) error {
+		if req.Tmpfs == nil {
+			req.Tmpfs = map[string]string{}
+		}
+
+		for path, opts := range tmpfs {
+			req.Tmpfs[path] = opts
+		}
+
+		return nil
+	}
+}
+
 // Executable represents an executable command to be sent to a container, including options,
 // as part of the different lifecycle hooks.
 type Executable interface {
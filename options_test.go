@@ -3,8 +3,13 @@ package testcontainers_test
 import (
 	"context"
 	"io"
+	"strconv"
 	"testing"
+	"time"
 
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/registry"
+	"github.com/docker/docker/errdefs"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -167,6 +172,20 @@ func TestWithAfterReadyCommand(t *testing.T) {
 	assert.Equal(t, "/tmp/.testcontainers\n", string(content))
 }
 
+func TestWithEntrypoint(t *testing.T) {
+	req := &testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Entrypoint: []string{"tail", "-f", "/dev/null"},
+			Cmd:        []string{"-c", "log_statement=all"},
+		},
+	}
+
+	opt := testcontainers.WithEntrypoint("sleep", "infinity")
+	require.NoError(t, opt.Customize(req))
+	require.Equal(t, []string{"sleep", "infinity"}, req.Entrypoint)
+	require.Equal(t, []string{"-c", "log_statement=all"}, req.Cmd)
+}
+
 func TestWithEnv(t *testing.T) {
 	tests := map[string]struct {
 		req    *testcontainers.GenericContainerRequest
@@ -215,6 +234,43 @@ func TestWithEnv(t *testing.T) {
 	}
 }
 
+func TestWithLabels(t *testing.T) {
+	t.Run("add", func(t *testing.T) {
+		req := &testcontainers.GenericContainerRequest{
+			ContainerRequest: testcontainers.ContainerRequest{
+				Labels: map[string]string{"KEY1": "VAL1", "KEY2": "VAL2"},
+			},
+		}
+
+		require.NoError(t, testcontainers.WithLabels(map[string]string{"KEY2": "VAL3", "KEY3": "VAL3"}).Customize(req))
+		require.Equal(t, map[string]string{"KEY1": "VAL1", "KEY2": "VAL3", "KEY3": "VAL3"}, req.Labels)
+	})
+
+	t.Run("add-nil", func(t *testing.T) {
+		req := &testcontainers.GenericContainerRequest{}
+
+		require.NoError(t, testcontainers.WithLabels(map[string]string{"KEY1": "VAL1"}).Customize(req))
+		require.Equal(t, map[string]string{"KEY1": "VAL1"}, req.Labels)
+	})
+
+	t.Run("reserved", func(t *testing.T) {
+		req := &testcontainers.GenericContainerRequest{}
+
+		err := testcontainers.WithLabels(map[string]string{
+			"KEY1":                         "VAL1",
+			"org.testcontainers.sessionId": "session",
+		}).Customize(req)
+		require.ErrorContains(t, err, "label org.testcontainers.sessionId is reserved")
+		require.Nil(t, req.Labels)
+	})
+
+	t.Run("not-reserved", func(t *testing.T) {
+		req := &testcontainers.GenericContainerRequest{}
+
+		require.NoError(t, testcontainers.WithLabels(map[string]string{"org.testcontainersx": "VAL1"}).Customize(req))
+	})
+}
+
 func TestWithHostPortAccess(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -247,3 +303,397 @@ func TestWithHostPortAccess(t *testing.T) {
 		})
 	}
 }
+
+func TestWithRegistryAuth(t *testing.T) {
+	req := &testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			RegistryAuthConfigs: map[string]registry.AuthConfig{
+				"other.local": {Username: "other"},
+			},
+		},
+	}
+
+	opt := testcontainers.WithRegistryAuth("myregistry.local", "gopher", "secret")
+	require.NoError(t, opt.Customize(req))
+
+	require.Equal(t, map[string]registry.AuthConfig{
+		"other.local":      {Username: "other"},
+		"myregistry.local": {Username: "gopher", Password: "secret", ServerAddress: "myregistry.local"},
+	}, req.RegistryAuthConfigs)
+}
+
+func TestWithReadOnlyRootFilesystem(t *testing.T) {
+	ctx := context.Background()
+
+	req := testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:      "alpine",
+			Entrypoint: []string{"tail", "-f", "/dev/null"},
+			HostConfigModifier: func(hostConfig *container.HostConfig) {
+				hostConfig.ExtraHosts = []string{"testcontainers.local:127.0.0.1"}
+			},
+		},
+		Started: true,
+	}
+
+	// withReadOnlyRootFilesystem {
+	opts := []testcontainers.ContainerCustomizer{
+		testcontainers.WithReadOnlyRootFilesystem(),
+		testcontainers.WithTmpfs(map[string]string{"/tmp": "rw"}),
+	}
+	// }
+	for _, opt := range opts {
+		require.NoError(t, opt.Customize(&req))
+	}
+
+	c, err := testcontainers.GenericContainer(ctx, req)
+	require.NoError(t, err)
+	defer func() {
+		err = c.Terminate(ctx)
+		require.NoError(t, err)
+	}()
+
+	inspect, err := c.Inspect(ctx)
+	require.NoError(t, err)
+
+	assert.True(t, inspect.HostConfig.ReadonlyRootfs)
+	assert.Equal(t, map[string]string{"/tmp": "rw"}, inspect.HostConfig.Tmpfs)
+	// the previous host config modifier is kept
+	assert.Equal(t, []string{"testcontainers.local:127.0.0.1"}, inspect.HostConfig.ExtraHosts)
+
+	code, _, err := c.Exec(ctx, []string{"touch", "/tmp/.testcontainers"})
+	require.NoError(t, err)
+	assert.Zero(t, code)
+
+	code, _, err = c.Exec(ctx, []string{"touch", "/.testcontainers"})
+	require.NoError(t, err)
+	assert.NotZero(t, code)
+}
+
+func TestWithTmpfs(t *testing.T) {
+	tests := map[string]struct {
+		req    *testcontainers.GenericContainerRequest
+		tmpfs  map[string]string
+		expect map[string]string
+	}{
+		"add": {
+			req: &testcontainers.GenericContainerRequest{
+				ContainerRequest: testcontainers.ContainerRequest{
+					Tmpfs: map[string]string{"/tmp": "rw"},
+				},
+			},
+			tmpfs: map[string]string{"/var/lib/kafka/data": "rw,size=64m"},
+			expect: map[string]string{
+				"/tmp":                "rw",
+				"/var/lib/kafka/data": "rw,size=64m",
+			},
+		},
+		"add-nil": {
+			req:    &testcontainers.GenericContainerRequest{},
+			tmpfs:  map[string]string{"/tmp": "rw"},
+			expect: map[string]string{"/tmp": "rw"},
+		},
+		"override": {
+			req: &testcontainers.GenericContainerRequest{
+				ContainerRequest: testcontainers.ContainerRequest{
+					Tmpfs: map[string]string{"/tmp": "rw"},
+				},
+			},
+			tmpfs:  map[string]string{"/tmp": "rw,noexec"},
+			expect: map[string]string{"/tmp": "rw,noexec"},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			opt := testcontainers.WithTmpfs(tc.tmpfs)
+			require.NoError(t, opt.Customize(tc.req))
+			require.Equal(t, tc.expect, tc.req.Tmpfs)
+		})
+	}
+}
+
+func TestWithName(t *testing.T) {
+	t.Run("valid name", func(t *testing.T) {
+		req := &testcontainers.GenericContainerRequest{}
+
+		require.NoError(t, testcontainers.WithName("kafka-debug_1.0").Customize(req))
+		require.Equal(t, "kafka-debug_1.0", req.Name)
+	})
+
+	t.Run("invalid name", func(t *testing.T) {
+		req := &testcontainers.GenericContainerRequest{}
+
+		require.ErrorContains(t, testcontainers.WithName("").Customize(req), "invalid container name")
+		require.ErrorContains(t, testcontainers.WithName("kafka debug").Customize(req), "invalid container name")
+		require.Empty(t, req.Name)
+	})
+
+	t.Run("reaper name", func(t *testing.T) {
+		req := &testcontainers.GenericContainerRequest{}
+
+		require.ErrorContains(t, testcontainers.WithName("reaper_1234").Customize(req), "reserved for the reaper")
+		require.Empty(t, req.Name)
+	})
+
+	t.Run("name in use", func(t *testing.T) {
+		ctx := context.Background()
+
+		name := "tc-with-name-" + strconv.FormatInt(time.Now().UnixNano(), 36)
+
+		// withName {
+		req := testcontainers.GenericContainerRequest{
+			ContainerRequest: testcontainers.ContainerRequest{Image: nginxAlpineImage},
+			Started:          true,
+		}
+		require.NoError(t, testcontainers.WithName(name).Customize(&req))
+		// }
+
+		first, err := testcontainers.GenericContainer(ctx, req)
+		require.NoError(t, err)
+		terminateContainerOnEnd(t, ctx, first)
+
+		second, err := testcontainers.GenericContainer(ctx, req)
+		terminateContainerOnEnd(t, ctx, second)
+		require.True(t, errdefs.IsConflict(err), "expected a conflict, got %v", err)
+		require.ErrorContains(t, err, "container name "+name+" already in use, enable the reuse")
+
+		// the reuse returns the existing container
+		req.Reuse = true
+		reused, err := testcontainers.GenericContainer(ctx, req)
+		require.NoError(t, err)
+		require.Equal(t, first.GetContainerID(), reused.GetContainerID())
+	})
+}
+
+func TestWithTerminateTimeout(t *testing.T) {
+	t.Run("invalid timeout", func(t *testing.T) {
+		req := &testcontainers.GenericContainerRequest{}
+
+		require.Error(t, testcontainers.WithTerminateTimeout(-time.Second).Customize(req))
+		require.Zero(t, req.TerminateTimeout)
+	})
+
+	// slowStopping starts a container taking 2 seconds to stop once it receives SIGTERM
+	slowStopping := func(t *testing.T, opts ...testcontainers.ContainerCustomizer) testcontainers.Container {
+		t.Helper()
+
+		req := testcontainers.GenericContainerRequest{
+			ContainerRequest: testcontainers.ContainerRequest{
+				Image:      nginxAlpineImage,
+				Entrypoint: []string{"sh", "-c", "trap 'sleep 2; exit 0' TERM; while true; do sleep 0.1; done"},
+			},
+			Started: true,
+		}
+
+		for _, opt := range opts {
+			require.NoError(t, opt.Customize(&req))
+		}
+
+		c, err := testcontainers.GenericContainer(context.Background(), req)
+		require.NoError(t, err)
+
+		return c
+	}
+
+	t.Run("stops within the timeout", func(t *testing.T) {
+		// withTerminateTimeout {
+		c := slowStopping(t, testcontainers.WithTerminateTimeout(10*time.Second))
+		// }
+
+		start := time.Now()
+		require.NoError(t, c.Terminate(context.Background()))
+
+		// the container is not killed before the end of its shutdown, nor kept until the timeout
+		elapsed := time.Since(start)
+		require.GreaterOrEqual(t, elapsed, 2*time.Second)
+		require.Less(t, elapsed, 10*time.Second)
+	})
+
+	t.Run("stop timeout overrides", func(t *testing.T) {
+		c := slowStopping(t, testcontainers.WithTerminateTimeout(10*time.Second))
+
+		start := time.Now()
+		// stopTimeout {
+		err := c.Terminate(context.Background(), testcontainers.StopTimeout(0))
+		// }
+		require.NoError(t, err)
+
+		// a zero timeout kills the container right away
+		require.Less(t, time.Since(start), 2*time.Second)
+	})
+
+	t.Run("killed by default", func(t *testing.T) {
+		c := slowStopping(t)
+
+		start := time.Now()
+		require.NoError(t, c.Terminate(context.Background()))
+		require.Less(t, time.Since(start), 2*time.Second)
+	})
+}
+
+func TestWithResourceLimits(t *testing.T) {
+	t.Run("invalid limits", func(t *testing.T) {
+		req := &testcontainers.GenericContainerRequest{}
+
+		require.Error(t, testcontainers.WithMemoryLimit(0).Customize(req))
+		require.Error(t, testcontainers.WithCPUQuota(-1, 100000).Customize(req))
+		require.Error(t, testcontainers.WithCPUQuota(50000, 0).Customize(req))
+		require.Nil(t, req.HostConfigModifier)
+	})
+
+	t.Run("deprecated fields", func(t *testing.T) {
+		req := &testcontainers.GenericContainerRequest{}
+		require.NoError(t, testcontainers.WithMemoryLimit(64*1024*1024).Customize(req))
+
+		// the deprecated fields set after the customizer are kept
+		req.CapAdd = []string{"NET_ADMIN"}
+
+		hostConfig := &container.HostConfig{}
+		req.HostConfigModifier(hostConfig)
+
+		assert.Equal(t, []string{"NET_ADMIN"}, []string(hostConfig.CapAdd))
+		assert.Equal(t, int64(64*1024*1024), hostConfig.Memory)
+	})
+
+	t.Run("host config", func(t *testing.T) {
+		ctx := context.Background()
+
+		req := testcontainers.GenericContainerRequest{
+			ContainerRequest: testcontainers.ContainerRequest{
+				Image:      "alpine",
+				Entrypoint: []string{"tail", "-f", "/dev/null"},
+			},
+			Started: true,
+		}
+
+		// withResourceLimits {
+		opts := []testcontainers.ContainerCustomizer{
+			testcontainers.WithMemoryLimit(64 * 1024 * 1024),
+			testcontainers.WithCPUQuota(50000, 100000),
+		}
+		// }
+		for _, opt := range opts {
+			require.NoError(t, opt.Customize(&req))
+		}
+
+		c, err := testcontainers.GenericContainer(ctx, req)
+		require.NoError(t, err)
+		defer func() {
+			err = c.Terminate(ctx)
+			require.NoError(t, err)
+		}()
+
+		inspect, err := c.Inspect(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(64*1024*1024), inspect.HostConfig.Memory)
+		assert.Equal(t, int64(64*1024*1024), inspect.HostConfig.MemorySwap)
+		assert.Equal(t, int64(50000), inspect.HostConfig.CPUQuota)
+		assert.Equal(t, int64(100000), inspect.HostConfig.CPUPeriod)
+	})
+}
+
+func TestWithExtraHost(t *testing.T) {
+	t.Run("invalid host", func(t *testing.T) {
+		req := &testcontainers.GenericContainerRequest{}
+
+		require.Error(t, testcontainers.WithExtraHost("", "127.0.0.1").Customize(req))
+		require.Error(t, testcontainers.WithExtraHost("testcontainers.local", "").Customize(req))
+		require.Nil(t, req.HostConfigModifier)
+	})
+
+	t.Run("etc hosts", func(t *testing.T) {
+		ctx := context.Background()
+
+		req := testcontainers.GenericContainerRequest{
+			ContainerRequest: testcontainers.ContainerRequest{
+				Image:      "alpine",
+				Entrypoint: []string{"tail", "-f", "/dev/null"},
+			},
+			Started: true,
+		}
+
+		// withExtraHost {
+		opts := []testcontainers.ContainerCustomizer{
+			testcontainers.WithExtraHost("auth.testcontainers.local", testcontainers.HostGateway),
+			testcontainers.WithExtraHost("testcontainers.local", "10.0.0.1"),
+		}
+		// }
+		for _, opt := range opts {
+			require.NoError(t, opt.Customize(&req))
+		}
+
+		c, err := testcontainers.GenericContainer(ctx, req)
+		require.NoError(t, err)
+		defer func() {
+			err = c.Terminate(ctx)
+			require.NoError(t, err)
+		}()
+
+		_, reader, err := c.Exec(ctx, []string{"cat", "/etc/hosts"}, exec.Multiplexed())
+		require.NoError(t, err)
+
+		hosts, err := io.ReadAll(reader)
+		require.NoError(t, err)
+
+		assert.Contains(t, string(hosts), "10.0.0.1\ttestcontainers.local")
+		// the daemon replaces host-gateway by the IP of the host
+		assert.Regexp(t, `(?m)^\d+\.\d+\.\d+\.\d+\tauth\.testcontainers\.local$`, string(hosts))
+	})
+}
+
+func TestWithHealthCheck(t *testing.T) {
+	t.Run("invalid healthcheck", func(t *testing.T) {
+		req := &testcontainers.GenericContainerRequest{}
+
+		require.Error(t, testcontainers.WithHealthCheck(nil, time.Second, time.Second, 3).Customize(req))
+		require.Error(t, testcontainers.WithHealthCheck([]string{"true"}, time.Second, time.Second, 3).Customize(req))
+		require.Error(t, testcontainers.WithHealthCheck([]string{"CMD", "true"}, -time.Second, time.Second, 3).Customize(req))
+		require.Error(t, testcontainers.WithHealthCheck([]string{"CMD", "true"}, time.Second, time.Second, -1).Customize(req))
+		require.Nil(t, req.ConfigModifier)
+	})
+
+	t.Run("healthy", func(t *testing.T) {
+		ctx := context.Background()
+
+		req := testcontainers.GenericContainerRequest{
+			ContainerRequest: testcontainers.ContainerRequest{
+				Image:      "alpine",
+				Entrypoint: []string{"tail", "-f", "/dev/null"},
+				ConfigModifier: func(config *container.Config) {
+					config.Hostname = "testcontainers"
+				},
+			},
+			Started: true,
+		}
+
+		// withHealthCheck {
+		opts := []testcontainers.ContainerCustomizer{
+			testcontainers.WithHealthCheck([]string{"CMD-SHELL", "test -d /proc/1"}, 500*time.Millisecond, time.Second, 3),
+			testcontainers.WithWaitStrategy(wait.ForHealthCheck().WithStartupTimeout(30 * time.Second)),
+		}
+		// }
+		for _, opt := range opts {
+			require.NoError(t, opt.Customize(&req))
+		}
+
+		c, err := testcontainers.GenericContainer(ctx, req)
+		require.NoError(t, err)
+		defer func() {
+			err = c.Terminate(ctx)
+			require.NoError(t, err)
+		}()
+
+		state, err := c.State(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, state.Health)
+		assert.Equal(t, "healthy", state.Health.Status)
+
+		inspect, err := c.Inspect(ctx)
+		require.NoError(t, err)
+
+		// the previous config modifier is kept
+		assert.Equal(t, "testcontainers", inspect.Config.Hostname)
+		assert.Equal(t, []string{"CMD-SHELL", "test -d /proc/1"}, inspect.Config.Healthcheck.Test)
+	})
+}
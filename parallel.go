@@ -119,3 +119,49 @@ func ParallelContainers(ctx context.Context, reqs ParallelContainerRequest, opt
 
 	return containers, nil
 }
+
+// RunParallel creates and starts the generic containers of the requests concurrently, one goroutine per
+// request, respecting the wait strategy of each request. The containers are returned in the order of the
+// requests. If any request fails, the containers that were created are terminated, and a
+// ParallelContainersError is returned with the errors of the failed requests.
+func RunParallel(ctx context.Context, reqs ...GenericContainerRequest) ([]Container, error) {
+	containers := make([]Container, len(reqs))
+	errs := make([]error, len(reqs))
+
+	wg := sync.WaitGroup{}
+	wg.Add(len(reqs))
+
+	for i, req := range reqs {
+		go func(i int, req GenericContainerRequest) {
+			defer wg.Done()
+
+			containers[i], errs[i] = GenericContainer(ctx, req)
+		}(i, req)
+	}
+
+	wg.Wait()
+
+	var parallelErr ParallelContainersError
+	for i, err := range errs {
+		if err != nil {
+			parallelErr.Errors = append(parallelErr.Errors, ParallelContainersRequestError{Request: reqs[i], Error: err})
+		}
+	}
+
+	if len(parallelErr.Errors) == 0 {
+		return containers, nil
+	}
+
+	// the containers failing to start could have been created, so they are terminated too
+	for _, c := range containers {
+		if c == nil {
+			continue
+		}
+
+		if err := c.Terminate(ctx); err != nil {
+			Logger.Printf("failed to terminate container %s after a parallel run failure: %s", c.GetContainerID(), err)
+		}
+	}
+
+	return nil, parallelErr
+}
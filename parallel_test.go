@@ -7,6 +7,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 
 	"github.com/testcontainers/testcontainers-go/wait"
@@ -165,3 +168,110 @@ func TestParallelContainersWithReuse(t *testing.T) {
 	// Container is reused, only terminate first container
 	terminateContainerOnEnd(t, ctx, res[0])
 }
+
+func TestRunParallel(t *testing.T) {
+	ctx := context.Background()
+
+	nginxRequest := func(name string) GenericContainerRequest {
+		return GenericContainerRequest{
+			ContainerRequest: ContainerRequest{
+				Image:        nginxAlpineImage,
+				Name:         name,
+				ExposedPorts: []string{nginxDefaultPort},
+				WaitingFor:   wait.ForListeningPort(nginxDefaultPort),
+			},
+			Started: true,
+		}
+	}
+
+	t.Run("all containers start", func(t *testing.T) {
+		names := []string{"run-parallel-" + uuid.NewString(), "run-parallel-" + uuid.NewString()}
+
+		// runParallel {
+		containers, err := RunParallel(ctx, nginxRequest(names[0]), nginxRequest(names[1]))
+		// }
+		require.NoError(t, err)
+		require.Len(t, containers, 2)
+
+		for i, c := range containers {
+			terminateContainerOnEnd(t, ctx, c)
+
+			// the containers are returned in the order of the requests
+			name, err := c.Name(ctx)
+			require.NoError(t, err)
+			require.Equal(t, "/"+names[i], name)
+
+			state, err := c.State(ctx)
+			require.NoError(t, err)
+			require.True(t, state.Running)
+		}
+	})
+
+	t.Run("started containers are terminated on failure", func(t *testing.T) {
+		name := "run-parallel-" + uuid.NewString()
+
+		bad := nginxRequest("")
+		bad.Image = "bad bad bad"
+
+		containers, err := RunParallel(ctx, nginxRequest(name), bad)
+		require.Error(t, err)
+		require.Nil(t, containers)
+
+		var e ParallelContainersError
+		require.ErrorAs(t, err, &e)
+		require.Len(t, e.Errors, 1)
+		require.Equal(t, "bad bad bad", e.Errors[0].Request.Image)
+
+		cli, err := NewDockerClientWithOpts(ctx)
+		require.NoError(t, err)
+		defer cli.Close()
+
+		list, err := cli.ContainerList(ctx, container.ListOptions{
+			All:     true,
+			Filters: filters.NewArgs(filters.Arg("name", name)),
+		})
+		require.NoError(t, err)
+		require.Empty(t, list)
+	})
+}
+
+func BenchmarkRunParallel(b *testing.B) {
+	ctx := context.Background()
+
+	reqs := make([]GenericContainerRequest, 3)
+	for i := range reqs {
+		reqs[i] = GenericContainerRequest{
+			ContainerRequest: ContainerRequest{
+				Image:        nginxDelayedImage,
+				ExposedPorts: []string{nginxDefaultPort},
+				WaitingFor:   wait.ForListeningPort(nginxDefaultPort),
+			},
+			Started: true,
+		}
+	}
+
+	// pull the image before measuring
+	c, err := GenericContainer(ctx, reqs[0])
+	require.NoError(b, err)
+	require.NoError(b, c.Terminate(ctx))
+
+	b.Run("sequential", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, req := range reqs {
+				c, err := GenericContainer(ctx, req)
+				require.NoError(b, err)
+				require.NoError(b, c.Terminate(ctx))
+			}
+		}
+	})
+
+	b.Run("parallel", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			containers, err := RunParallel(ctx, reqs...)
+			require.NoError(b, err)
+			for _, c := range containers {
+				require.NoError(b, c.Terminate(ctx))
+			}
+		}
+	})
+}
@@ -198,12 +198,12 @@ type sshdContainer struct {
 }
 
 // Terminate stops the container and closes the SSH session
-func (sshdC *sshdContainer) Terminate(ctx context.Context) error {
+func (sshdC *sshdContainer) Terminate(ctx context.Context, opts ...TerminateOption) error {
 	for _, pfw := range sshdC.portForwarders {
 		pfw.Close(ctx)
 	}
 
-	return sshdC.DockerContainer.Terminate(ctx)
+	return sshdC.DockerContainer.Terminate(ctx, opts...)
 }
 
 func configureSSHConfig(ctx context.Context, sshdC *sshdContainer) (*ssh.ClientConfig, error) {
@@ -52,12 +52,15 @@ func NewReaper(ctx context.Context, sessionID string, provider ReaperProvider, r
 	return reuseOrCreateReaper(ctx, sessionID, provider)
 }
 
+// reaperContainerNamePrefix is the prefix of the names of the reaper containers, which cannot be used by WithName
+const reaperContainerNamePrefix = "reaper_"
+
 // reaperContainerNameFromSessionID returns the container name that uniquely
 // identifies the container based on the session id.
 func reaperContainerNameFromSessionID(sessionID string) string {
 	// The session id is 64 characters, so we will not hit the limit of 128
 	// characters for container names.
-	return fmt.Sprintf("reaper_%s", sessionID)
+	return reaperContainerNamePrefix + sessionID
 }
 
 // lookUpReaperContainer returns a DockerContainer type with the reaper container in the case
@@ -0,0 +1,76 @@
+package testcontainers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
+)
+
+// RetryPolicy retries the operations on the containers, like Exec or MappedPort, failing with a transient
+// error of the Docker daemon. The classification is conservative: only the errors of a daemon which is
+// unavailable, or of a connection to the daemon which could not be established, are transient, so the real
+// failures are returned at once, without being masked. A connection lost once established, e.g. reset by
+// the daemon, is not retried, as the daemon may have processed the request already, e.g. run the command
+// of an Exec, which must not be run twice.
+type RetryPolicy struct {
+	attempts int
+	backoff  time.Duration
+}
+
+// WithRetry returns a RetryPolicy running an operation up to attempts times, waiting backoff between them.
+// An operation is run at least once, and it's only run again if the previous attempt did not reach the daemon,
+// or the daemon was unavailable, so the operations which are not idempotent, like Exec, can be retried.
+func WithRetry(attempts int, backoff time.Duration) RetryPolicy {
+	return RetryPolicy{attempts: max(attempts, 1), backoff: backoff}
+}
+
+// Do runs the operation until it succeeds, it fails with an error which is not transient, the attempts are
+// exhausted, or the context is done, returning the last error of the operation.
+func (p RetryPolicy) Do(ctx context.Context, operation func(ctx context.Context) error) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = operation(ctx); err == nil || !isTransientError(err) {
+			return err
+		}
+
+		if attempt == p.attempts {
+			return fmt.Errorf("%d attempts: %w", attempt, err)
+		}
+
+		timer := time.NewTimer(p.backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%w: %w", ctx.Err(), err)
+		case <-timer.C:
+		}
+	}
+}
+
+// isTransientError reports whether the error is a transient error of the Docker daemon, so the operation
+// failing with it can be retried, i.e. the daemon is unavailable, or the request never reached it.
+func isTransientError(err error) bool {
+	return errdefs.IsUnavailable(err) || (client.IsErrConnectionFailed(err) && !isConnectionLost(err))
+}
+
+// isConnectionLost reports whether the error is the failure of a connection to the daemon which was
+// established, so the request may have been processed. The Docker client reports both as failed
+// connections, only keeping the cause of the latter.
+func isConnectionLost(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op != "dial" {
+		return true
+	}
+
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
@@ -0,0 +1,162 @@
+package testcontainers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryPolicy_Do(t *testing.T) {
+	ctx := context.Background()
+
+	transientErr := errdefs.Unavailable(errors.New("daemon unavailable"))
+
+	// failing returns an operation failing with the errors, in order, before succeeding
+	failing := func(errs ...error) (func(context.Context) error, *int) {
+		calls := 0
+		return func(_ context.Context) error {
+			calls++
+			if calls <= len(errs) {
+				return errs[calls-1]
+			}
+			return nil
+		}, &calls
+	}
+
+	t.Run("success", func(t *testing.T) {
+		operation, calls := failing()
+
+		require.NoError(t, WithRetry(3, time.Millisecond).Do(ctx, operation))
+		assert.Equal(t, 1, *calls)
+	})
+
+	t.Run("transient errors", func(t *testing.T) {
+		connectionErr := fmt.Errorf("exec: %w", client.ErrorConnectionFailed("unix:///var/run/docker.sock"))
+		operation, calls := failing(transientErr, connectionErr)
+
+		require.NoError(t, WithRetry(3, time.Millisecond).Do(ctx, operation))
+		assert.Equal(t, 3, *calls)
+	})
+
+	t.Run("attempts exhausted", func(t *testing.T) {
+		operation, calls := failing(transientErr, transientErr, transientErr)
+
+		err := WithRetry(2, time.Millisecond).Do(ctx, operation)
+		require.ErrorIs(t, err, transientErr)
+		require.ErrorContains(t, err, "2 attempts")
+		assert.Equal(t, 2, *calls)
+	})
+
+	t.Run("permanent error", func(t *testing.T) {
+		permanentErr := errdefs.NotFound(errors.New("no such container"))
+		operation, calls := failing(permanentErr)
+
+		err := WithRetry(3, time.Millisecond).Do(ctx, operation)
+		require.Equal(t, permanentErr, err)
+		assert.Equal(t, 1, *calls)
+	})
+
+	t.Run("context done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		operation, calls := failing(transientErr, transientErr)
+
+		err := WithRetry(3, time.Hour).Do(ctx, operation)
+		require.ErrorIs(t, err, context.Canceled)
+		require.ErrorIs(t, err, transientErr)
+		assert.Equal(t, 1, *calls)
+	})
+
+	t.Run("at least one attempt", func(t *testing.T) {
+		operation, calls := failing(transientErr)
+
+		require.ErrorIs(t, WithRetry(0, time.Millisecond).Do(ctx, operation), transientErr)
+		assert.Equal(t, 1, *calls)
+	})
+}
+
+func TestRetryPolicy_connectionLost(t *testing.T) {
+	ctx := context.Background()
+
+	// the daemon receives the request, then drops the connection before responding
+	requests := 0
+	daemon := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+
+		conn, _, err := w.(http.Hijacker).Hijack()
+		require.NoError(t, err)
+		require.NoError(t, conn.Close())
+	}))
+	defer daemon.Close()
+
+	cli, err := client.NewClientWithOpts(client.WithHost("tcp://"+daemon.Listener.Addr().String()), client.WithVersion("1.41"))
+	require.NoError(t, err)
+	defer cli.Close()
+
+	err = WithRetry(3, time.Millisecond).Do(ctx, func(ctx context.Context) error {
+		_, err := cli.ContainerInspect(ctx, "nginx")
+		return err
+	})
+	require.True(t, client.IsErrConnectionFailed(err), err)
+	require.NotContains(t, err.Error(), "attempts")
+	assert.Equal(t, 1, requests)
+}
+
+func TestRetryPolicy_connectionRefused(t *testing.T) {
+	ctx := context.Background()
+
+	// the port of a closed listener refuses the connections
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	require.NoError(t, listener.Close())
+
+	cli, err := client.NewClientWithOpts(client.WithHost("tcp://"+listener.Addr().String()), client.WithVersion("1.41"))
+	require.NoError(t, err)
+	defer cli.Close()
+
+	calls := 0
+	err = WithRetry(3, time.Millisecond).Do(ctx, func(ctx context.Context) error {
+		calls++
+		_, err := cli.ContainerInspect(ctx, "nginx")
+		return err
+	})
+	require.True(t, client.IsErrConnectionFailed(err), err)
+	require.ErrorContains(t, err, "3 attempts")
+	assert.Equal(t, 3, calls)
+}
+
+func TestRetryPolicy_exec(t *testing.T) {
+	ctx := context.Background()
+
+	nginx, err := GenericContainer(ctx, GenericContainerRequest{
+		ProviderType: providerType,
+		ContainerRequest: ContainerRequest{
+			Image: nginxAlpineImage,
+		},
+		Started: true,
+	})
+	require.NoError(t, err)
+	terminateContainerOnEnd(t, ctx, nginx)
+
+	// retryExec {
+	var code int
+	err = WithRetry(3, time.Second).Do(ctx, func(ctx context.Context) error {
+		var err error
+		code, _, err = nginx.Exec(ctx, []string{"nginx", "-t"})
+		return err
+	})
+	// }
+	require.NoError(t, err)
+	assert.Zero(t, code)
+}
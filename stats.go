@@ -0,0 +1,102 @@
+package testcontainers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/docker/docker/api/types"
+)
+
+// ContainerStats is a sample of the resource usage of a container, as reported by docker stats.
+type ContainerStats struct {
+	// Read is the time the sample was read by the Docker daemon
+	Read time.Time
+	// CPUPercent is the usage of the host CPUs since the previous sample, where 100 is one full CPU
+	CPUPercent float64
+	// MemoryUsage is the memory used by the container, in bytes, excluding the page cache
+	MemoryUsage uint64
+	// MemoryLimit is the memory limit of the container, in bytes
+	MemoryLimit uint64
+	// NetworkRxBytes is the number of bytes received on all the networks of the container
+	NetworkRxBytes uint64
+	// NetworkTxBytes is the number of bytes sent on all the networks of the container
+	NetworkTxBytes uint64
+}
+
+// Stats streams the resource usage of the container, one sample per second as sent by the Docker daemon.
+// The channel is closed when the context is done, when the container stops, or if a sample can't be decoded.
+func (c *DockerContainer) Stats(ctx context.Context) (<-chan ContainerStats, error) {
+	resp, err := c.provider.client.ContainerStats(ctx, c.ID, true)
+	if err != nil {
+		return nil, fmt.Errorf("container stats: %w", err)
+	}
+
+	stats := make(chan ContainerStats)
+
+	go func() {
+		defer close(stats)
+		defer resp.Body.Close()
+
+		decoder := json.NewDecoder(resp.Body)
+		for {
+			var sample types.StatsJSON
+			if err := decoder.Decode(&sample); err != nil {
+				return
+			}
+
+			// the daemon keeps sending empty samples after the container stops
+			if sample.Read.IsZero() {
+				return
+			}
+
+			select {
+			case stats <- newContainerStats(sample):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return stats, nil
+}
+
+// newContainerStats converts the stats of the Docker API, computing the CPU percent and the memory usage
+// like the Docker CLI.
+func newContainerStats(sample types.StatsJSON) ContainerStats {
+	stats := ContainerStats{
+		Read:        sample.Read,
+		MemoryUsage: sample.MemoryStats.Usage,
+		MemoryLimit: sample.MemoryStats.Limit,
+	}
+
+	cpuDelta := float64(sample.CPUStats.CPUUsage.TotalUsage) - float64(sample.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(sample.CPUStats.SystemUsage) - float64(sample.PreCPUStats.SystemUsage)
+
+	onlineCPUs := float64(sample.CPUStats.OnlineCPUs)
+	if onlineCPUs == 0 {
+		onlineCPUs = float64(len(sample.CPUStats.CPUUsage.PercpuUsage))
+	}
+
+	if cpuDelta > 0 && systemDelta > 0 {
+		stats.CPUPercent = cpuDelta / systemDelta * onlineCPUs * 100
+	}
+
+	// the page cache is reported as total_inactive_file with cgroup v1, and as inactive_file with cgroup v2
+	cache, ok := sample.MemoryStats.Stats["total_inactive_file"]
+	if !ok {
+		cache = sample.MemoryStats.Stats["inactive_file"]
+	}
+
+	if cache < stats.MemoryUsage {
+		stats.MemoryUsage -= cache
+	}
+
+	for _, network := range sample.Networks {
+		stats.NetworkRxBytes += network.RxBytes
+		stats.NetworkTxBytes += network.TxBytes
+	}
+
+	return stats
+}
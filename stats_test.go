@@ -0,0 +1,103 @@
+package testcontainers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewContainerStats(t *testing.T) {
+	read := time.Now()
+
+	var sample types.StatsJSON
+	sample.Read = read
+	sample.PreCPUStats.CPUUsage.TotalUsage = 1000
+	sample.PreCPUStats.SystemUsage = 10000
+	sample.CPUStats.CPUUsage.TotalUsage = 1500
+	sample.CPUStats.SystemUsage = 20000
+	sample.CPUStats.OnlineCPUs = 2
+	sample.MemoryStats.Usage = 64 * 1024 * 1024
+	sample.MemoryStats.Limit = 128 * 1024 * 1024
+	sample.Networks = map[string]types.NetworkStats{
+		"eth0": {RxBytes: 100, TxBytes: 200},
+		"eth1": {RxBytes: 10, TxBytes: 20},
+	}
+
+	t.Run("cgroup v1", func(t *testing.T) {
+		sample.MemoryStats.Stats = map[string]uint64{"total_inactive_file": 16 * 1024 * 1024}
+
+		stats := newContainerStats(sample)
+
+		assert.Equal(t, read, stats.Read)
+		// 500 of 10000 on 2 CPUs
+		assert.InDelta(t, 10.0, stats.CPUPercent, 0.001)
+		assert.Equal(t, uint64(48*1024*1024), stats.MemoryUsage)
+		assert.Equal(t, uint64(128*1024*1024), stats.MemoryLimit)
+		assert.Equal(t, uint64(110), stats.NetworkRxBytes)
+		assert.Equal(t, uint64(220), stats.NetworkTxBytes)
+	})
+
+	t.Run("cgroup v2", func(t *testing.T) {
+		sample.MemoryStats.Stats = map[string]uint64{"inactive_file": 32 * 1024 * 1024}
+
+		stats := newContainerStats(sample)
+
+		assert.Equal(t, uint64(32*1024*1024), stats.MemoryUsage)
+	})
+
+	t.Run("first sample", func(t *testing.T) {
+		first := sample
+		first.PreCPUStats = types.CPUStats{}
+		first.CPUStats.SystemUsage = 0
+
+		stats := newContainerStats(first)
+
+		assert.Zero(t, stats.CPUPercent)
+	})
+}
+
+func TestDockerContainer_Stats(t *testing.T) {
+	ctx := context.Background()
+
+	nginx, err := GenericContainer(ctx, GenericContainerRequest{
+		ProviderType: providerType,
+		ContainerRequest: ContainerRequest{
+			Image: nginxAlpineImage,
+		},
+		Started: true,
+	})
+	require.NoError(t, err)
+	terminateContainerOnEnd(t, ctx, nginx)
+
+	// containerStats {
+	statsCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stats, err := nginx.(*DockerContainer).Stats(statsCtx)
+	require.NoError(t, err)
+
+	sample, ok := <-stats
+	require.True(t, ok)
+	// }
+
+	assert.False(t, sample.Read.IsZero())
+	assert.NotZero(t, sample.MemoryUsage)
+	assert.NotZero(t, sample.MemoryLimit)
+
+	// the channel is closed when the container stops
+	timeout := 10 * time.Second
+	require.NoError(t, nginx.Stop(ctx, &timeout))
+
+	require.Eventually(t, func() bool {
+		select {
+		case _, ok := <-stats:
+			return !ok
+		default:
+			return false
+		}
+	}, 10*time.Second, 100*time.Millisecond)
+}
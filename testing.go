@@ -40,6 +40,23 @@ func SkipIfDockerDesktop(t *testing.T, ctx context.Context) {
 	}
 }
 
+// CleanupContainer is a utility function registering a cleanup function in the test that terminates
+// the container, failing the test if it cannot be terminated. It does nothing if the container is nil,
+// so it can be called right after creating the container, before checking the error.
+func CleanupContainer(tb testing.TB, ctr Container) {
+	tb.Helper()
+
+	if ctr == nil {
+		return
+	}
+
+	tb.Cleanup(func() {
+		if err := ctr.Terminate(context.Background()); err != nil {
+			tb.Errorf("failed to terminate container: %s", err)
+		}
+	})
+}
+
 // exampleLogConsumer {
 
 // StdoutLogConsumer is a LogConsumer that prints the log to stdout
@@ -1,7 +1,36 @@
 package testcontainers
 
-import "testing"
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
 
 func ExampleSkipIfProviderIsNotHealthy() {
 	SkipIfProviderIsNotHealthy(&testing.T{})
 }
+
+// terminationCounter is a container counting the calls to Terminate
+type terminationCounter struct {
+	Container
+	terminations int
+}
+
+func (c *terminationCounter) Terminate(context.Context, ...TerminateOption) error {
+	c.terminations++
+	return nil
+}
+
+func TestCleanupContainer(t *testing.T) {
+	ctr := &terminationCounter{}
+
+	t.Run("registers the termination", func(t *testing.T) {
+		CleanupContainer(t, ctr)
+		CleanupContainer(t, nil)
+
+		require.Zero(t, ctr.terminations)
+	})
+
+	require.Equal(t, 1, ctr.terminations)
+}
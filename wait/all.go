@@ -34,7 +34,8 @@ func (ms *MultiStrategy) WithStartupTimeout(timeout time.Duration) Strategy {
 	return ms.WithDeadline(timeout)
 }
 
-// WithDeadline sets a time.Duration which limits all wait strategies
+// WithDeadline sets a time.Duration which limits all wait strategies, as a single wall-clock budget shared
+// by all of them. If a wait strategy is not ready once the deadline is exceeded, the error names it.
 func (ms *MultiStrategy) WithDeadline(deadline time.Duration) *MultiStrategy {
 	ms.deadline = &deadline
 	return ms
@@ -61,7 +62,7 @@ func (ms *MultiStrategy) WaitUntilReady(ctx context.Context, target StrategyTarg
 		return fmt.Errorf("no wait strategy supplied")
 	}
 
-	for _, strategy := range ms.Strategies {
+	for i, strategy := range ms.Strategies {
 		strategyCtx := ctx
 
 		// Set default Timeout when strategy implements StrategyTimeout
@@ -74,6 +75,9 @@ func (ms *MultiStrategy) WaitUntilReady(ctx context.Context, target StrategyTarg
 
 		err := strategy.WaitUntilReady(strategyCtx, target)
 		if err != nil {
+			if ms.deadline != nil && ctx.Err() != nil {
+				return fmt.Errorf("wait strategy %d (%T) not ready within the deadline of %s: %w", i, strategy, *ms.deadline, err)
+			}
 			return err
 		}
 	}
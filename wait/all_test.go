@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"io"
+	"strings"
 	"testing"
 	"time"
 )
@@ -119,3 +120,30 @@ func TestMultiStrategy_WaitUntilReady(t *testing.T) {
 		})
 	}
 }
+
+func TestMultiStrategy_WaitUntilReadyDeadlineNamesStrategy(t *testing.T) {
+	strategy := ForAll(
+		ForNop(
+			func(ctx context.Context, target StrategyTarget) error {
+				return nil
+			},
+		),
+		// the second strategy never becomes ready
+		ForNop(
+			func(ctx context.Context, target StrategyTarget) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		),
+	).WithDeadline(500 * time.Millisecond)
+
+	err := strategy.WaitUntilReady(context.Background(), NopStrategyTarget{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a deadline exceeded error, got %v", err)
+	}
+
+	expected := "wait strategy 1 (*wait.NopStrategy) not ready within the deadline of 500ms"
+	if !strings.Contains(err.Error(), expected) {
+		t.Fatalf("expected the error to contain %q, got %q", expected, err)
+	}
+}
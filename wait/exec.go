@@ -1,7 +1,9 @@
 package wait
 
 import (
+	"bytes"
 	"context"
+	"fmt"
 	"io"
 	"time"
 
@@ -82,22 +84,43 @@ func (ws *ExecStrategy) WaitUntilReady(ctx context.Context, target StrategyTarge
 		timeout = *ws.timeout
 	}
 
+	start := time.Now()
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
+	// the result of the last execution is reported if the command never succeeds
+	var (
+		executed bool
+		lastCode int
+		lastOut  []byte
+	)
+
 	for {
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
+			if !executed {
+				return newTimeoutError(ctx, ws, start, "", nil)
+			}
+
+			return newTimeoutError(ctx, ws, start, fmt.Sprintf("exit code %d, output: %q", lastCode, lastOutput(lastOut)), nil)
 		case <-time.After(ws.PollInterval):
 			exitCode, resp, err := target.Exec(ctx, ws.cmd, tcexec.Multiplexed())
 			if err != nil {
 				return err
 			}
+
+			var out []byte
+			if resp != nil {
+				if out, err = io.ReadAll(resp); err != nil {
+					return fmt.Errorf("read exec output: %w", err)
+				}
+			}
+			executed, lastCode, lastOut = true, exitCode, out
+
 			if !ws.ExitCodeMatcher(exitCode) {
 				continue
 			}
-			if ws.ResponseMatcher != nil && !ws.ResponseMatcher(resp) {
+			if ws.ResponseMatcher != nil && !ws.ResponseMatcher(bytes.NewReader(out)) {
 				continue
 			}
 
@@ -105,3 +128,16 @@ func (ws *ExecStrategy) WaitUntilReady(ctx context.Context, target StrategyTarge
 		}
 	}
 }
+
+// maxReportedOutput is the maximum number of bytes of the output of the last execution reported on timeout
+const maxReportedOutput = 1024
+
+// lastOutput returns the end of the output of the last execution, trimmed, to be reported on timeout.
+func lastOutput(out []byte) string {
+	out = bytes.TrimSpace(out)
+	if len(out) > maxReportedOutput {
+		out = out[len(out)-maxReportedOutput:]
+	}
+
+	return string(out)
+}
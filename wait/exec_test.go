@@ -148,6 +148,33 @@ func TestExecStrategyWaitUntilReady_DeadlineExceeded(t *testing.T) {
 	}
 }
 
+func TestExecStrategyWaitUntilReady_TimeoutReportsLastExecution(t *testing.T) {
+	target := mockExecTarget{
+		exitCode: 1,
+		response: "broker not ready\n",
+	}
+	wg := wait.NewExecStrategy([]string{"true"}).
+		WithStartupTimeout(500 * time.Millisecond)
+	err := wg.WaitUntilReady(context.Background(), target)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a deadline exceeded error, got %v", err)
+	}
+
+	var timeoutErr *wait.TimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("expected a timeout error, got %v", err)
+	}
+
+	expected := `exit code 1, output: "broker not ready"`
+	if timeoutErr.LastResult != expected {
+		t.Fatalf("expected the last result to be %s, got %s", expected, timeoutErr.LastResult)
+	}
+
+	if timeoutErr.Strategy != "*wait.ExecStrategy" {
+		t.Fatalf("expected the strategy to be *wait.ExecStrategy, got %s", timeoutErr.Strategy)
+	}
+}
+
 func TestExecStrategyWaitUntilReady_CustomExitCode(t *testing.T) {
 	target := mockExecTarget{
 		exitCode: 10,
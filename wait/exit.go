@@ -61,16 +61,19 @@ func (ws *ExitStrategy) Timeout() *time.Duration {
 
 // WaitUntilReady implements Strategy.WaitUntilReady
 func (ws *ExitStrategy) WaitUntilReady(ctx context.Context, target StrategyTarget) error {
+	start := time.Now()
 	if ws.timeout != nil {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, *ws.timeout)
 		defer cancel()
 	}
 
+	var lastResult string
+
 	for {
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
+			return newTimeoutError(ctx, ws, start, lastResult, nil)
 		default:
 			state, err := target.State(ctx)
 			if err != nil {
@@ -81,6 +84,7 @@ func (ws *ExitStrategy) WaitUntilReady(ctx context.Context, target StrategyTarge
 				}
 			}
 			if state.Running {
+				lastResult = "container running"
 				time.Sleep(ws.PollInterval)
 				continue
 			}
@@ -67,13 +67,16 @@ func (ws *HealthStrategy) WaitUntilReady(ctx context.Context, target StrategyTar
 		timeout = *ws.timeout
 	}
 
+	start := time.Now()
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
+	var lastResult string
+
 	for {
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
+			return newTimeoutError(ctx, ws, start, lastResult, nil)
 		default:
 			state, err := target.State(ctx)
 			if err != nil {
@@ -83,6 +86,10 @@ func (ws *HealthStrategy) WaitUntilReady(ctx context.Context, target StrategyTar
 				return err
 			}
 			if state.Health == nil || state.Health.Status != types.Healthy {
+				lastResult = "no health status"
+				if state.Health != nil {
+					lastResult = "health status " + state.Health.Status
+				}
 				time.Sleep(ws.PollInterval)
 				continue
 			}
@@ -115,6 +115,10 @@ func TestWaitFailsForNilHealth(t *testing.T) {
 	err := wg.WaitUntilReady(context.Background(), target)
 	require.Error(t, err)
 	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	var timeoutErr *TimeoutError
+	require.ErrorAs(t, err, &timeoutErr)
+	require.Equal(t, "no health status", timeoutErr.LastResult)
 }
 
 func TestWaitForHealthFailsDueToOOMKilledContainer(t *testing.T) {
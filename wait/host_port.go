@@ -28,6 +28,8 @@ type HostPortStrategy struct {
 	// all WaitStrategies should have a startupTimeout to avoid waiting infinitely
 	timeout      *time.Duration
 	PollInterval time.Duration
+	// skipInternalCheck skips the check, executed in the container, that the process is listening on the port
+	skipInternalCheck bool
 }
 
 // NewHostPortStrategy constructs a default host port strategy
@@ -66,6 +68,15 @@ func (hp *HostPortStrategy) WithPollInterval(pollInterval time.Duration) *HostPo
 	return hp
 }
 
+// SkipInternalCheck can be used to skip the internal check, which executes a shell command in the container
+// to confirm the process is actually listening on the port, as the Docker daemon accepts the connections to
+// the mapped port before the process binds it. The internal check is performed by default, in addition to
+// dialing the mapped port, and it's already skipped for the images without a shell.
+func (hp *HostPortStrategy) SkipInternalCheck(skip bool) *HostPortStrategy {
+	hp.skipInternalCheck = skip
+	return hp
+}
+
 func (hp *HostPortStrategy) Timeout() *time.Duration {
 	return hp.timeout
 }
@@ -77,6 +88,7 @@ func (hp *HostPortStrategy) WaitUntilReady(ctx context.Context, target StrategyT
 		timeout = *hp.timeout
 	}
 
+	start := time.Now()
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
@@ -118,7 +130,7 @@ func (hp *HostPortStrategy) WaitUntilReady(ctx context.Context, target StrategyT
 
 		select {
 		case <-ctx.Done():
-			return fmt.Errorf("%w: %w", ctx.Err(), err)
+			return newTimeoutError(ctx, hp, start, fmt.Sprintf("port %s not mapped", internalPort), err)
 		case <-time.After(waitInterval):
 			if err := checkTarget(ctx, target); err != nil {
 				return err
@@ -131,10 +143,20 @@ func (hp *HostPortStrategy) WaitUntilReady(ctx context.Context, target StrategyT
 	}
 
 	if err := externalCheck(ctx, ipAddress, port, target, waitInterval); err != nil {
+		if ctx.Err() != nil {
+			return newTimeoutError(ctx, hp, start, fmt.Sprintf("mapped port %s not listening", port), nil)
+		}
 		return err
 	}
 
-	err = internalCheck(ctx, internalPort, target)
+	if hp.skipInternalCheck {
+		return nil
+	}
+
+	err = internalCheck(ctx, internalPort, target, waitInterval)
+	if err != nil && ctx.Err() != nil {
+		return newTimeoutError(ctx, hp, start, fmt.Sprintf("port %s not listening in the container", internalPort), nil)
+	}
 	if err != nil && errors.Is(errShellNotExecutable, err) {
 		log.Println("Shell not executable in container, only external port check will be performed")
 	} else {
@@ -176,7 +198,7 @@ func externalCheck(ctx context.Context, ipAddress string, port nat.Port, target
 	return nil
 }
 
-func internalCheck(ctx context.Context, internalPort nat.Port, target StrategyTarget) error {
+func internalCheck(ctx context.Context, internalPort nat.Port, target StrategyTarget, waitInterval time.Duration) error {
 	command := buildInternalCheckCommand(internalPort.Int())
 	for {
 		if ctx.Err() != nil {
@@ -195,6 +217,13 @@ func internalCheck(ctx context.Context, internalPort nat.Port, target StrategyTa
 		} else if exitCode == 126 {
 			return errShellNotExecutable
 		}
+
+		// the process is not listening yet, so wait before executing the command again
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(waitInterval):
+		}
 	}
 	return nil
 }
@@ -62,6 +62,104 @@ func TestWaitForListeningPortSucceeds(t *testing.T) {
 	}
 }
 
+func TestWaitForListeningPortSkipsInternalCheck(t *testing.T) {
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	rawPort := listener.Addr().(*net.TCPAddr).Port
+	port, err := nat.NewPort("tcp", strconv.Itoa(rawPort))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var execCount int
+	target := &MockStrategyTarget{
+		HostImpl: func(_ context.Context) (string, error) {
+			return "localhost", nil
+		},
+		MappedPortImpl: func(_ context.Context, _ nat.Port) (nat.Port, error) {
+			return port, nil
+		},
+		StateImpl: func(_ context.Context) (*types.ContainerState, error) {
+			return &types.ContainerState{
+				Running: true,
+			}, nil
+		},
+		ExecImpl: func(_ context.Context, _ []string, _ ...exec.ProcessOption) (int, io.Reader, error) {
+			execCount++
+			return 1, nil, nil
+		},
+	}
+
+	wg := ForListeningPort("80").
+		WithStartupTimeout(5 * time.Second).
+		WithPollInterval(100 * time.Millisecond).
+		SkipInternalCheck(true)
+
+	if err := wg.WaitUntilReady(context.Background(), target); err != nil {
+		t.Fatal(err)
+	}
+
+	if execCount != 0 {
+		t.Fatalf("expected the internal check to be skipped, got %d executions", execCount)
+	}
+}
+
+func TestWaitForListeningPortRunsInternalCheckByDefault(t *testing.T) {
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	rawPort := listener.Addr().(*net.TCPAddr).Port
+	port, err := nat.NewPort("tcp", strconv.Itoa(rawPort))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	strategies := map[string]*HostPortStrategy{
+		"default":                  ForListeningPort("80"),
+		"SkipInternalCheck(false)": ForListeningPort("80").SkipInternalCheck(false),
+	}
+
+	for name, wg := range strategies {
+		t.Run(name, func(t *testing.T) {
+			var execCount int
+			target := &MockStrategyTarget{
+				HostImpl: func(_ context.Context) (string, error) {
+					return "localhost", nil
+				},
+				MappedPortImpl: func(_ context.Context, _ nat.Port) (nat.Port, error) {
+					return port, nil
+				},
+				StateImpl: func(_ context.Context) (*types.ContainerState, error) {
+					return &types.ContainerState{
+						Running: true,
+					}, nil
+				},
+				ExecImpl: func(_ context.Context, _ []string, _ ...exec.ProcessOption) (int, io.Reader, error) {
+					execCount++
+					return 0, nil, nil
+				},
+			}
+
+			wg = wg.WithStartupTimeout(5 * time.Second).WithPollInterval(100 * time.Millisecond)
+
+			if err := wg.WaitUntilReady(context.Background(), target); err != nil {
+				t.Fatal(err)
+			}
+
+			if execCount == 0 {
+				t.Fatal("expected the internal check to be executed")
+			}
+		})
+	}
+}
+
 func TestWaitForExposedPortSucceeds(t *testing.T) {
 	listener, err := net.Listen("tcp", "localhost:0")
 	if err != nil {
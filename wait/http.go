@@ -42,6 +42,8 @@ type HTTPStrategy struct {
 	PollInterval           time.Duration
 	UserInfo               *url.Userinfo
 	ForceIPv4LocalHost     bool
+	// ClientCertificates are the client certificates presented to the servers requiring mutual TLS
+	ClientCertificates []tls.Certificate
 }
 
 // NewHTTPStrategy constructs a HTTP strategy waiting on port 80 and status code 200
@@ -99,6 +101,15 @@ func (ws *HTTPStrategy) WithTLS(useTLS bool, tlsconf ...*tls.Config) *HTTPStrate
 	return ws
 }
 
+// WithClientCert adds a client certificate presented to the servers requiring mutual TLS, and enables TLS.
+// It's added to the certificates of the TLS config passed to WithTLS, if any, without modifying it,
+// and it honors WithAllowInsecure.
+func (ws *HTTPStrategy) WithClientCert(cert tls.Certificate) *HTTPStrategy {
+	ws.UseTLS = true
+	ws.ClientCertificates = append(ws.ClientCertificates, cert)
+	return ws
+}
+
 func (ws *HTTPStrategy) WithAllowInsecure(allowInsecure bool) *HTTPStrategy {
 	ws.AllowInsecure = allowInsecure
 	return ws
@@ -159,6 +170,7 @@ func (ws *HTTPStrategy) WaitUntilReady(ctx context.Context, target StrategyTarge
 		timeout = *ws.timeout
 	}
 
+	start := time.Now()
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
@@ -179,7 +191,7 @@ func (ws *HTTPStrategy) WaitUntilReady(ctx context.Context, target StrategyTarge
 		for err != nil || ports == nil {
 			select {
 			case <-ctx.Done():
-				return fmt.Errorf("%w: %w", ctx.Err(), err)
+				return newTimeoutError(ctx, ws, start, "no port exposed", err)
 			case <-time.After(ws.PollInterval):
 				if err := checkTarget(ctx, target); err != nil {
 					return err
@@ -211,7 +223,7 @@ func (ws *HTTPStrategy) WaitUntilReady(ctx context.Context, target StrategyTarge
 		for mappedPort == "" {
 			select {
 			case <-ctx.Done():
-				return fmt.Errorf("%w: %w", ctx.Err(), err)
+				return newTimeoutError(ctx, ws, start, fmt.Sprintf("port %s not mapped", ws.Port), err)
 			case <-time.After(ws.PollInterval):
 				if err := checkTarget(ctx, target); err != nil {
 					return err
@@ -252,14 +264,23 @@ func (ws *HTTPStrategy) WaitUntilReady(ctx context.Context, target StrategyTarge
 		TLSClientConfig:       ws.TLSConfig,
 	}
 
+	if len(ws.ClientCertificates) > 0 {
+		if ws.TLSConfig == nil {
+			tripper.TLSClientConfig = &tls.Config{}
+		} else {
+			tripper.TLSClientConfig = ws.TLSConfig.Clone()
+		}
+		tripper.TLSClientConfig.Certificates = append(tripper.TLSClientConfig.Certificates, ws.ClientCertificates...)
+	}
+
 	var proto string
 	if ws.UseTLS {
 		proto = "https"
 		if ws.AllowInsecure {
-			if ws.TLSConfig == nil {
+			if tripper.TLSClientConfig == nil {
 				tripper.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
 			} else {
-				ws.TLSConfig.InsecureSkipVerify = true
+				tripper.TLSClientConfig.InsecureSkipVerify = true
 			}
 		}
 	} else {
@@ -289,10 +310,16 @@ func (ws *HTTPStrategy) WaitUntilReady(ctx context.Context, target StrategyTarge
 		}
 	}
 
+	// the result of the last request is reported if the endpoint never matches
+	var (
+		lastResult string
+		lastErr    error
+	)
+
 	for {
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
+			return newTimeoutError(ctx, ws, start, lastResult, lastErr)
 		case <-time.After(ws.PollInterval):
 			if err := checkTarget(ctx, target); err != nil {
 				return err
@@ -308,17 +335,23 @@ func (ws *HTTPStrategy) WaitUntilReady(ctx context.Context, target StrategyTarge
 
 			resp, err := client.Do(req)
 			if err != nil {
+				if !interrupted(ctx) {
+					lastResult, lastErr = "", err
+				}
 				continue
 			}
+			lastResult, lastErr = fmt.Sprintf("status code %d", resp.StatusCode), nil
 			if ws.StatusCodeMatcher != nil && !ws.StatusCodeMatcher(resp.StatusCode) {
 				_ = resp.Body.Close()
 				continue
 			}
 			if ws.ResponseMatcher != nil && !ws.ResponseMatcher(resp.Body) {
+				lastResult += ", response not matching"
 				_ = resp.Body.Close()
 				continue
 			}
 			if ws.ResponseHeadersMatcher != nil && !ws.ResponseHeadersMatcher(resp.Header) {
+				lastResult += ", response headers not matching"
 				_ = resp.Body.Close()
 				continue
 			}
@@ -3,13 +3,20 @@ package wait_test
 import (
 	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"crypto/tls"
 	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"math/big"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"testing"
@@ -473,6 +480,158 @@ func TestHTTPStrategyWaitUntilReadyNoBasicAuth(t *testing.T) {
 	}
 }
 
+func TestHTTPStrategyWaitUntilReadyWithClientCert(t *testing.T) {
+	// the client certificate is self-signed, and trusted by the server
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "testcontainers-client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(leaf)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+
+	host, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	target := &wait.MockStrategyTarget{
+		HostImpl: func(_ context.Context) (string, error) {
+			return host, nil
+		},
+		MappedPortImpl: func(_ context.Context, _ nat.Port) (nat.Port, error) {
+			return nat.NewPort("tcp", port)
+		},
+		StateImpl: func(_ context.Context) (*types.ContainerState, error) {
+			return &types.ContainerState{Running: true}, nil
+		},
+	}
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(server.Certificate())
+	tlsConfig := &tls.Config{RootCAs: rootCAs}
+
+	t.Run("without client certificate", func(t *testing.T) {
+		wg := wait.ForHTTP("/").
+			WithPort("443/tcp").
+			WithTLS(true, tlsConfig).
+			WithStartupTimeout(500 * time.Millisecond).
+			WithPollInterval(100 * time.Millisecond)
+
+		if err := wg.WaitUntilReady(context.Background(), target); err == nil {
+			t.Fatal("expected the server to reject the client without certificate")
+		}
+	})
+
+	t.Run("with client certificate", func(t *testing.T) {
+		// waitForHTTPClientCert {
+		wg := wait.ForHTTP("/").
+			WithPort("443/tcp").
+			WithTLS(true, tlsConfig).
+			WithClientCert(tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}).
+			WithStartupTimeout(5 * time.Second).
+			WithPollInterval(100 * time.Millisecond)
+		// }
+
+		if err := wg.WaitUntilReady(context.Background(), target); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(tlsConfig.Certificates) != 0 {
+			t.Fatal("expected the TLS config passed to WithTLS not to be modified")
+		}
+	})
+
+	t.Run("with client certificate and insecure", func(t *testing.T) {
+		wg := wait.ForHTTP("/").
+			WithPort("443/tcp").
+			WithClientCert(tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}).
+			WithAllowInsecure(true).
+			WithStartupTimeout(5 * time.Second).
+			WithPollInterval(100 * time.Millisecond)
+
+		if err := wg.WaitUntilReady(context.Background(), target); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestHTTPStrategyWaitUntilReady_timeoutReportsLastStatusCode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	host, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	target := &wait.MockStrategyTarget{
+		HostImpl: func(_ context.Context) (string, error) {
+			return host, nil
+		},
+		MappedPortImpl: func(_ context.Context, _ nat.Port) (nat.Port, error) {
+			return nat.NewPort("tcp", port)
+		},
+		StateImpl: func(_ context.Context) (*types.ContainerState, error) {
+			return &types.ContainerState{Running: true}, nil
+		},
+	}
+
+	// waitTimeoutError {
+	wg := wait.ForHTTP("/").
+		WithPort("80/tcp").
+		WithStartupTimeout(500 * time.Millisecond).
+		WithPollInterval(100 * time.Millisecond)
+
+	err = wg.WaitUntilReady(context.Background(), target)
+
+	var timeoutErr *wait.TimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("expected a timeout error, got %v", err)
+	}
+	// }
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a deadline exceeded error, got %v", err)
+	}
+
+	if timeoutErr.LastResult != "status code 503" {
+		t.Fatalf("expected status code 503 as last result, got %s", timeoutErr.LastResult)
+	}
+
+	if timeoutErr.Elapsed < 500*time.Millisecond {
+		t.Fatalf("expected at least 500ms elapsed, got %s", timeoutErr.Elapsed)
+	}
+}
+
 func TestHttpStrategyFailsWhileGettingPortDueToOOMKilledContainer(t *testing.T) {
 	var mappedPortCount int
 	target := &wait.MockStrategyTarget{
@@ -0,0 +1,420 @@
+package wait
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/docker/go-connections/nat"
+)
+
+// Implement interface
+var (
+	_ Strategy        = (*KafkaStrategy)(nil)
+	_ StrategyTimeout = (*KafkaStrategy)(nil)
+)
+
+const (
+	defaultKafkaPort    = "9092/tcp"
+	defaultKafkaGroupID = "testcontainers-quorum-check"
+
+	// kafkaClientID is the client id sent in the requests of the probe
+	kafkaClientID = "testcontainers"
+
+	// the Kafka protocol API keys used by the probe
+	kafkaKeyMetadata        int16 = 3
+	kafkaKeyFindCoordinator int16 = 10
+	kafkaKeyApiVersions     int16 = 18
+
+	// the versions of the APIs used by the probe, which are not flexible, i.e. they do not use
+	// tagged fields, and are supported by all the brokers since Kafka 1.0
+	kafkaMetadataVersion        int16 = 4
+	kafkaFindCoordinatorVersion int16 = 1
+	kafkaApiVersionsVersion     int16 = 0
+
+	// maxKafkaResponseSize is the largest response accepted by the probe, far above the size of the
+	// responses to its requests, so a service not speaking the protocol, e.g. an HTTP server answering
+	// "HTTP/1.1 400", does not make the probe allocate the size read from the first bytes of its answer.
+	maxKafkaResponseSize = 1 << 20
+)
+
+// KafkaStrategy waits until a Kafka broker answers the ApiVersions request of the Kafka protocol
+// on the given port. Optionally, it also waits until the controller quorum has elected a leader
+// and the broker can coordinate consumer groups, as the broker listens before that happens, so
+// producing or consuming right after the port is open could fail.
+// The requests are sent to the broker behind the mapped port, without following the addresses
+// advertised by the broker, so it works with any advertised listener.
+type KafkaStrategy struct {
+	// all Strategies should have a startupTimeout to avoid waiting infinitely
+	timeout *time.Duration
+
+	// additional properties
+	Port         nat.Port
+	PollInterval time.Duration
+	// Quorum enables the check of the controller and the group coordinator
+	Quorum bool
+	// GroupID is the consumer group used to check that the group coordinator is available
+	GroupID string
+}
+
+// NewKafkaStrategy constructs a Kafka strategy waiting on port 9092, without checking the quorum
+func NewKafkaStrategy() *KafkaStrategy {
+	return &KafkaStrategy{
+		Port:         defaultKafkaPort,
+		PollInterval: defaultPollInterval(),
+		GroupID:      defaultKafkaGroupID,
+	}
+}
+
+// ForKafka is a convenience method to create a Kafka strategy
+func ForKafka() *KafkaStrategy {
+	return NewKafkaStrategy()
+}
+
+// WithStartupTimeout can be used to change the default startup timeout
+func (ks *KafkaStrategy) WithStartupTimeout(timeout time.Duration) *KafkaStrategy {
+	ks.timeout = &timeout
+	return ks
+}
+
+// WithPollInterval can be used to override the default polling interval of 100 milliseconds
+func (ks *KafkaStrategy) WithPollInterval(pollInterval time.Duration) *KafkaStrategy {
+	ks.PollInterval = pollInterval
+	return ks
+}
+
+// WithPort can be used to override the default port of the broker, 9092/tcp
+func (ks *KafkaStrategy) WithPort(port nat.Port) *KafkaStrategy {
+	ks.Port = port
+	return ks
+}
+
+// WithQuorum enables the check of the controller quorum and the group coordinator
+func (ks *KafkaStrategy) WithQuorum() *KafkaStrategy {
+	ks.Quorum = true
+	return ks
+}
+
+func (ks *KafkaStrategy) Timeout() *time.Duration {
+	return ks.timeout
+}
+
+// WaitUntilReady implements Strategy.WaitUntilReady
+func (ks *KafkaStrategy) WaitUntilReady(ctx context.Context, target StrategyTarget) error {
+	timeout := defaultStartupTimeout()
+	if ks.timeout != nil {
+		timeout = *ks.timeout
+	}
+
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	host, err := target.Host(ctx)
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(ks.PollInterval)
+	defer ticker.Stop()
+
+	var port nat.Port
+	port, err = target.MappedPort(ctx, ks.Port)
+
+	for port == "" {
+		select {
+		case <-ctx.Done():
+			return newTimeoutError(ctx, ks, start, fmt.Sprintf("port %s not mapped", ks.Port), err)
+		case <-ticker.C:
+			if err := checkTarget(ctx, target); err != nil {
+				return err
+			}
+			port, err = target.MappedPort(ctx, ks.Port)
+		}
+	}
+
+	address := net.JoinHostPort(host, strconv.Itoa(port.Int()))
+
+	// the status of the last probe is reported if the broker never gets ready
+	var status string
+
+	for {
+		select {
+		case <-ctx.Done():
+			return newTimeoutError(ctx, ks, start, status, err)
+		case <-ticker.C:
+			if err := checkTarget(ctx, target); err != nil {
+				return err
+			}
+			checkStatus, checkErr := ks.check(ctx, address)
+			if checkErr == nil {
+				return nil
+			}
+			if !interrupted(ctx) {
+				status, err = checkStatus, checkErr
+			}
+		}
+	}
+}
+
+// check sends the requests of the probe to the broker listening on the address. If the broker is
+// not ready, the returned status tells how far the probe went, e.g. "no controller".
+func (ks *KafkaStrategy) check(ctx context.Context, address string) (string, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return "broker unreachable", err
+	}
+	defer conn.Close()
+
+	// a broker not speaking the protocol yet must not block the polling loop
+	deadline := time.Now().Add(5 * time.Second)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+
+	if err := conn.SetDeadline(deadline); err != nil {
+		return "broker unreachable", err
+	}
+
+	kc := &kafkaConn{rw: conn}
+
+	versions, err := kc.apiVersions()
+	if err != nil {
+		return "no api versions", fmt.Errorf("api versions: %w", err)
+	}
+
+	if !ks.Quorum {
+		return "", nil
+	}
+
+	if err := versions.supports(kafkaKeyMetadata, kafkaMetadataVersion); err != nil {
+		return "unsupported metadata api", err
+	}
+
+	if err := kc.controller(); err != nil {
+		return "no controller", fmt.Errorf("controller: %w", err)
+	}
+
+	if err := versions.supports(kafkaKeyFindCoordinator, kafkaFindCoordinatorVersion); err != nil {
+		return "unsupported find coordinator api", err
+	}
+
+	if err := kc.coordinator(ks.GroupID); err != nil {
+		return "no group coordinator", fmt.Errorf("coordinator: %w", err)
+	}
+
+	return "", nil
+}
+
+// kafkaAPIVersionRange is the range of versions of an API supported by the broker.
+type kafkaAPIVersionRange struct {
+	min, max int16
+}
+
+// kafkaAPIVersions are the ranges of versions supported by the broker, by API key.
+type kafkaAPIVersions map[int16]kafkaAPIVersionRange
+
+// supports returns an error if the broker does not support the version of the API, reporting the
+// range of versions the broker supports, if any.
+func (v kafkaAPIVersions) supports(apiKey int16, version int16) error {
+	r, ok := v[apiKey]
+	if !ok {
+		return fmt.Errorf("api key %d not supported by the broker", apiKey)
+	}
+
+	if version < r.min || version > r.max {
+		return fmt.Errorf("api key %d version %d not supported by the broker, which supports versions %d to %d", apiKey, version, r.min, r.max)
+	}
+
+	return nil
+}
+
+// kafkaConn sends requests of the Kafka protocol over a connection, one at a time.
+type kafkaConn struct {
+	rw            io.ReadWriter
+	correlationID int32
+}
+
+// apiVersions sends the ApiVersions request, returning the versions supported by the broker.
+func (kc *kafkaConn) apiVersions() (kafkaAPIVersions, error) {
+	d, err := kc.request(kafkaKeyApiVersions, kafkaApiVersionsVersion, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if code := d.int16(); code != 0 {
+		return nil, fmt.Errorf("error code %d", code)
+	}
+
+	n := d.int32()
+	versions := make(kafkaAPIVersions)
+	for i := int32(0); i < n && d.err == nil; i++ {
+		apiKey := d.int16()
+		versions[apiKey] = kafkaAPIVersionRange{min: d.int16(), max: d.int16()}
+	}
+
+	return versions, d.err
+}
+
+// controller sends a Metadata request without topics, returning an error if the broker does not know
+// the controller yet.
+func (kc *kafkaConn) controller() error {
+	var body []byte
+	body = binary.BigEndian.AppendUint32(body, 0) // no topics
+	body = append(body, 0)                        // do not create topics
+
+	d, err := kc.request(kafkaKeyMetadata, kafkaMetadataVersion, body)
+	if err != nil {
+		return err
+	}
+
+	_ = d.int32() // throttle time
+	brokers := d.int32()
+	for i := int32(0); i < brokers && d.err == nil; i++ {
+		_ = d.int32() // node id
+		d.string()    // host
+		_ = d.int32() // port
+		d.string()    // rack
+	}
+	d.string() // cluster id
+
+	controllerID := d.int32()
+	if d.err != nil {
+		return d.err
+	}
+
+	if controllerID < 0 {
+		return errors.New("no controller available")
+	}
+
+	return nil
+}
+
+// coordinator sends a FindCoordinator request for the consumer group, returning an error if the
+// broker cannot coordinate it yet.
+func (kc *kafkaConn) coordinator(groupID string) error {
+	body := appendKafkaString(nil, groupID)
+	body = append(body, 0) // group key type
+
+	d, err := kc.request(kafkaKeyFindCoordinator, kafkaFindCoordinatorVersion, body)
+	if err != nil {
+		return err
+	}
+
+	_ = d.int32() // throttle time
+	code := d.int16()
+	if d.err != nil {
+		return d.err
+	}
+
+	if code != 0 {
+		return fmt.Errorf("error code %d", code)
+	}
+
+	return nil
+}
+
+// request sends the request with the given API key, version and body, returning a decoder for the
+// body of the response, after its correlation id.
+func (kc *kafkaConn) request(apiKey int16, version int16, body []byte) (*kafkaDecoder, error) {
+	kc.correlationID++
+
+	var header []byte
+	header = binary.BigEndian.AppendUint16(header, uint16(apiKey))
+	header = binary.BigEndian.AppendUint16(header, uint16(version))
+	header = binary.BigEndian.AppendUint32(header, uint32(kc.correlationID))
+	header = appendKafkaString(header, kafkaClientID)
+
+	msg := binary.BigEndian.AppendUint32(nil, uint32(len(header)+len(body)))
+	msg = append(msg, header...)
+	msg = append(msg, body...)
+
+	if _, err := kc.rw.Write(msg); err != nil {
+		return nil, err
+	}
+
+	size := make([]byte, 4)
+	if _, err := io.ReadFull(kc.rw, size); err != nil {
+		return nil, err
+	}
+
+	// the response holds at least the correlation id
+	n := binary.BigEndian.Uint32(size)
+	if n <= 4 || n > maxKafkaResponseSize {
+		return nil, fmt.Errorf("invalid response size %d, expected more than 4 and at most %d bytes", n, maxKafkaResponseSize)
+	}
+
+	resp := make([]byte, n)
+	if _, err := io.ReadFull(kc.rw, resp); err != nil {
+		return nil, err
+	}
+
+	d := &kafkaDecoder{r: bytes.NewReader(resp)}
+	if correlationID := d.int32(); d.err == nil && correlationID != kc.correlationID {
+		return nil, fmt.Errorf("unexpected correlation id %d, expected %d", correlationID, kc.correlationID)
+	}
+
+	return d, d.err
+}
+
+// appendKafkaString appends the string to the buffer, prefixed by its length.
+func appendKafkaString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
+
+// kafkaDecoder decodes the fields of a response of the Kafka protocol, keeping the first error,
+// so the fields can be decoded without checking the error after each one.
+type kafkaDecoder struct {
+	r   *bytes.Reader
+	err error
+}
+
+func (d *kafkaDecoder) read(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+
+	b := make([]byte, n)
+	if _, err := io.ReadFull(d.r, b); err != nil {
+		d.err = fmt.Errorf("malformed response: %w", err)
+		return nil
+	}
+
+	return b
+}
+
+func (d *kafkaDecoder) int16() int16 {
+	b := d.read(2)
+	if b == nil {
+		return 0
+	}
+
+	return int16(binary.BigEndian.Uint16(b))
+}
+
+func (d *kafkaDecoder) int32() int32 {
+	b := d.read(4)
+	if b == nil {
+		return 0
+	}
+
+	return int32(binary.BigEndian.Uint32(b))
+}
+
+// string decodes a nullable string, which is prefixed by its length, or -1 if null.
+func (d *kafkaDecoder) string() string {
+	n := d.int16()
+	if n <= 0 {
+		return ""
+	}
+
+	return string(d.read(int(n)))
+}
@@ -0,0 +1,337 @@
+package wait
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/go-connections/nat"
+)
+
+// fakeKafkaBroker answers the requests of the Kafka protocol with the bodies returned by
+// the handler for their API key, after the correlation id of the request.
+type fakeKafkaBroker struct {
+	listener net.Listener
+	handler  func(apiKey int16) []byte
+}
+
+func newFakeKafkaBroker(t *testing.T, handler func(apiKey int16) []byte) *fakeKafkaBroker {
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	b := &fakeKafkaBroker{listener: listener, handler: handler}
+	go b.serve()
+
+	return b
+}
+
+func (b *fakeKafkaBroker) serve() {
+	for {
+		conn, err := b.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		go b.handle(conn)
+	}
+}
+
+func (b *fakeKafkaBroker) handle(conn net.Conn) {
+	defer conn.Close()
+
+	for {
+		size := make([]byte, 4)
+		if _, err := io.ReadFull(conn, size); err != nil {
+			return
+		}
+
+		req := make([]byte, binary.BigEndian.Uint32(size))
+		if _, err := io.ReadFull(conn, req); err != nil {
+			return
+		}
+
+		apiKey := int16(binary.BigEndian.Uint16(req[0:2]))
+		correlationID := req[4:8]
+
+		body := append(append([]byte{}, correlationID...), b.handler(apiKey)...)
+		resp := binary.BigEndian.AppendUint32(nil, uint32(len(body)))
+		if _, err := conn.Write(append(resp, body...)); err != nil {
+			return
+		}
+	}
+}
+
+func (b *fakeKafkaBroker) target() *MockStrategyTarget {
+	port := b.listener.Addr().(*net.TCPAddr).Port
+
+	return &MockStrategyTarget{
+		HostImpl: func(_ context.Context) (string, error) {
+			return "localhost", nil
+		},
+		MappedPortImpl: func(_ context.Context, _ nat.Port) (nat.Port, error) {
+			return nat.NewPort("tcp", strconv.Itoa(port))
+		},
+		StateImpl: func(_ context.Context) (*types.ContainerState, error) {
+			return &types.ContainerState{
+				Running: true,
+			}, nil
+		},
+	}
+}
+
+// kafkaResponses returns the handler of a broker supporting the APIs used by the strategy, with the
+// given controller id and error code of the group coordinator.
+func kafkaResponses(controllerID int32, coordinatorErrorCode int16) func(apiKey int16) []byte {
+	return func(apiKey int16) []byte {
+		var b []byte
+
+		switch apiKey {
+		case kafkaKeyApiVersions:
+			b = binary.BigEndian.AppendUint16(b, 0) // error code
+			b = binary.BigEndian.AppendUint32(b, 3)
+			for _, key := range []int16{kafkaKeyMetadata, kafkaKeyFindCoordinator, kafkaKeyApiVersions} {
+				b = binary.BigEndian.AppendUint16(b, uint16(key))
+				b = binary.BigEndian.AppendUint16(b, 0)  // min version
+				b = binary.BigEndian.AppendUint16(b, 12) // max version
+			}
+		case kafkaKeyMetadata:
+			b = binary.BigEndian.AppendUint32(b, 0) // throttle time
+			b = binary.BigEndian.AppendUint32(b, 1) // brokers
+			b = binary.BigEndian.AppendUint32(b, 1) // node id
+			b = appendKafkaString(b, "localhost")
+			b = binary.BigEndian.AppendUint32(b, 9092)
+			b = binary.BigEndian.AppendUint16(b, 0xffff) // null rack
+			b = appendKafkaString(b, "cluster")
+			b = binary.BigEndian.AppendUint32(b, uint32(controllerID))
+			b = binary.BigEndian.AppendUint32(b, 0) // topics
+		case kafkaKeyFindCoordinator:
+			b = binary.BigEndian.AppendUint32(b, 0) // throttle time
+			b = binary.BigEndian.AppendUint16(b, uint16(coordinatorErrorCode))
+			b = binary.BigEndian.AppendUint16(b, 0xffff) // null error message
+			b = binary.BigEndian.AppendUint32(b, 1)      // node id
+			b = appendKafkaString(b, "localhost")
+			b = binary.BigEndian.AppendUint32(b, 9092)
+		}
+
+		return b
+	}
+}
+
+func TestWaitForKafkaSucceeds(t *testing.T) {
+	broker := newFakeKafkaBroker(t, kafkaResponses(1, 0))
+
+	wg := ForKafka().
+		WithQuorum().
+		WithStartupTimeout(5 * time.Second).
+		WithPollInterval(100 * time.Millisecond)
+
+	if err := wg.WaitUntilReady(context.Background(), broker.target()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWaitForKafkaWithoutQuorumIgnoresController(t *testing.T) {
+	broker := newFakeKafkaBroker(t, kafkaResponses(-1, 15))
+
+	wg := ForKafka().
+		WithStartupTimeout(5 * time.Second).
+		WithPollInterval(100 * time.Millisecond)
+
+	if err := wg.WaitUntilReady(context.Background(), broker.target()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWaitForKafkaFailsWithoutController(t *testing.T) {
+	broker := newFakeKafkaBroker(t, kafkaResponses(-1, 0))
+
+	wg := ForKafka().
+		WithQuorum().
+		WithStartupTimeout(500 * time.Millisecond).
+		WithPollInterval(100 * time.Millisecond)
+
+	err := wg.WaitUntilReady(context.Background(), broker.target())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+
+	var timeoutErr *TimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("expected a timeout error, got %v", err)
+	}
+
+	if timeoutErr.LastResult != "no controller" {
+		t.Fatalf("expected no controller as last result, got %s", timeoutErr.LastResult)
+	}
+}
+
+func TestWaitForKafkaFailsWithoutCoordinator(t *testing.T) {
+	// 15 is the COORDINATOR_NOT_AVAILABLE error code
+	broker := newFakeKafkaBroker(t, kafkaResponses(1, 15))
+
+	wg := ForKafka().
+		WithQuorum().
+		WithStartupTimeout(500 * time.Millisecond).
+		WithPollInterval(100 * time.Millisecond)
+
+	err := wg.WaitUntilReady(context.Background(), broker.target())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestKafkaAPIVersionsSupports(t *testing.T) {
+	versions := kafkaAPIVersions{kafkaKeyMetadata: {min: 1, max: 12}}
+
+	tests := []struct {
+		name    string
+		apiKey  int16
+		version int16
+		err     string
+	}{
+		{name: "min version", apiKey: kafkaKeyMetadata, version: 1},
+		{name: "max version", apiKey: kafkaKeyMetadata, version: 12},
+		{name: "below min version", apiKey: kafkaKeyMetadata, version: 0, err: "api key 3 version 0 not supported by the broker, which supports versions 1 to 12"},
+		{name: "above max version", apiKey: kafkaKeyMetadata, version: 13, err: "api key 3 version 13 not supported by the broker, which supports versions 1 to 12"},
+		{name: "unknown api key", apiKey: kafkaKeyFindCoordinator, version: 1, err: "api key 10 not supported by the broker"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := versions.supports(test.apiKey, test.version)
+			if test.err == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+
+			if err == nil || err.Error() != test.err {
+				t.Fatalf("expected error %q, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestWaitForKafkaFailsWhenNotSpeakingKafka(t *testing.T) {
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	// the connections are accepted but never answered
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_, _ = io.Copy(io.Discard, conn)
+			}()
+		}
+	}()
+
+	target := (&fakeKafkaBroker{listener: listener}).target()
+
+	wg := ForKafka().
+		WithStartupTimeout(500 * time.Millisecond).
+		WithPollInterval(100 * time.Millisecond)
+
+	if err := wg.WaitUntilReady(context.Background(), target); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+// kafkaReadWriter is a connection writing the requests to nowhere and answering with the given bytes.
+type kafkaReadWriter struct {
+	io.Reader
+}
+
+func (kafkaReadWriter) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+func TestKafkaConnRejectsInvalidResponseSizes(t *testing.T) {
+	tests := []struct {
+		name string
+		resp []byte
+	}{
+		{
+			// an HTTP server answering the request, whose first bytes are read as a size of 1.2 GB
+			name: "HTTP",
+			resp: []byte("HTTP/1.1 400 Bad Request\r\n\r\n"),
+		},
+		{
+			name: "Oversized",
+			resp: binary.BigEndian.AppendUint32(nil, maxKafkaResponseSize+1),
+		},
+		{
+			name: "Without correlation id",
+			resp: binary.BigEndian.AppendUint32(nil, 4),
+		},
+		{
+			name: "Empty",
+			resp: binary.BigEndian.AppendUint32(nil, 0),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			kc := &kafkaConn{rw: kafkaReadWriter{Reader: bytes.NewReader(test.resp)}}
+
+			_, err := kc.apiVersions()
+			if err == nil || !strings.Contains(err.Error(), "invalid response size") {
+				t.Fatalf("expected an invalid response size error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestWaitForKafkaFailsWhileGettingPortDueToOOMKilledContainer(t *testing.T) {
+	var mappedPortCount int
+	target := &MockStrategyTarget{
+		HostImpl: func(_ context.Context) (string, error) {
+			return "localhost", nil
+		},
+		MappedPortImpl: func(_ context.Context, _ nat.Port) (nat.Port, error) {
+			defer func() { mappedPortCount++ }()
+			if mappedPortCount == 0 {
+				return "", ErrPortNotFound
+			}
+			return "49152", nil
+		},
+		StateImpl: func(_ context.Context) (*types.ContainerState, error) {
+			return &types.ContainerState{
+				OOMKilled: true,
+			}, nil
+		},
+	}
+
+	wg := ForKafka().
+		WithStartupTimeout(500 * time.Millisecond).
+		WithPollInterval(100 * time.Millisecond)
+
+	err := wg.WaitUntilReady(context.Background(), target)
+	if err == nil {
+		t.Fatal("no error")
+	}
+
+	expected := "container crashed with out-of-memory (OOMKilled)"
+	if err.Error() != expected {
+		t.Fatalf("expected %q, got %q", expected, err.Error())
+	}
+}
@@ -2,6 +2,7 @@ package wait
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"regexp"
 	"strings"
@@ -67,6 +68,13 @@ func (ws *LogStrategy) WithOccurrence(o int) *LogStrategy {
 	return ws
 }
 
+// Times makes the strategy ready only once the log occurs n times, e.g. for a line logged once per listener
+// of the service, and it's the same as WithOccurrence. The log is matched as plain text, or as a regular
+// expression if AsRegexp is used, each match counting as an occurrence.
+func (ws *LogStrategy) Times(n int) *LogStrategy {
+	return ws.WithOccurrence(n)
+}
+
 // ForLog is the default construction for the fluid interface.
 //
 // For Example:
@@ -89,16 +97,18 @@ func (ws *LogStrategy) WaitUntilReady(ctx context.Context, target StrategyTarget
 		timeout = *ws.timeout
 	}
 
+	start := time.Now()
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
 	length := 0
+	var lastResult string
 
 LOOP:
 	for {
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
+			return newTimeoutError(ctx, ws, start, lastResult, nil)
 		default:
 			checkErr := checkTarget(ctx, target)
 
@@ -123,6 +133,7 @@ LOOP:
 				break LOOP
 			default:
 				length = len(logs)
+				lastResult = fmt.Sprintf("%d of %d occurrences", countLogs(ws, b), ws.Occurrence)
 				time.Sleep(ws.PollInterval)
 				continue
 			}
@@ -133,13 +144,15 @@ LOOP:
 }
 
 func checkLogsFn(ws *LogStrategy, b []byte) bool {
+	return countLogs(ws, b) >= ws.Occurrence
+}
+
+// countLogs returns the number of occurrences of the log in the logs.
+func countLogs(ws *LogStrategy, b []byte) int {
 	if ws.IsRegexp {
 		re := regexp.MustCompile(ws.Log)
-		occurrences := re.FindAll(b, -1)
-
-		return len(occurrences) >= ws.Occurrence
+		return len(re.FindAll(b, -1))
 	}
 
-	logs := string(b)
-	return strings.Count(logs, ws.Log) >= ws.Occurrence
+	return strings.Count(string(b), ws.Log)
 }
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"io"
+	"strings"
 	"testing"
 	"time"
 
@@ -135,6 +136,65 @@ func TestWaitShouldFailWithExactNumberOfOccurrences(t *testing.T) {
 	})
 }
 
+func TestWaitForLogTimes(t *testing.T) {
+	// growingLogs returns a target logging one more line each time its logs are read, and the number of reads
+	growingLogs := func(line string) (StrategyTarget, *int) {
+		reads := 0
+		return &MockStrategyTarget{
+			LogsImpl: func(_ context.Context) (io.ReadCloser, error) {
+				reads++
+				return io.NopCloser(strings.NewReader(strings.Repeat(line+"\n", reads))), nil
+			},
+			StateImpl: func(_ context.Context) (*types.ContainerState, error) {
+				return &types.ContainerState{Running: true}, nil
+			},
+		}, &reads
+	}
+
+	t.Run("no regexp", func(t *testing.T) {
+		target, reads := growingLogs("Awaiting socket connections on 0.0.0.0:9092.")
+
+		wg := ForLog("Awaiting socket connections").Times(3).WithPollInterval(time.Millisecond)
+		if err := wg.WaitUntilReady(context.Background(), target); err != nil {
+			t.Fatal(err)
+		}
+
+		if *reads != 3 {
+			t.Fatalf("expected to be ready after 3 reads of the logs, got %d", *reads)
+		}
+	})
+
+	t.Run("as regexp", func(t *testing.T) {
+		target, reads := growingLogs("Awaiting socket connections on 0.0.0.0:9093.")
+
+		wg := ForLog(`Awaiting socket connections on [\d.]+:\d+`).AsRegexp().Times(2).WithPollInterval(time.Millisecond)
+		if err := wg.WaitUntilReady(context.Background(), target); err != nil {
+			t.Fatal(err)
+		}
+
+		if *reads != 2 {
+			t.Fatalf("expected to be ready after 2 reads of the logs, got %d", *reads)
+		}
+	})
+
+	t.Run("not enough occurrences", func(t *testing.T) {
+		target := NopStrategyTarget{
+			ReaderCloser: io.NopCloser(bytes.NewReader([]byte("Awaiting socket connections on 0.0.0.0:9092."))),
+		}
+
+		wg := ForLog(`Awaiting socket connections`).AsRegexp().Times(2).WithStartupTimeout(100 * time.Millisecond)
+		if err := wg.WaitUntilReady(context.Background(), target); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+
+	t.Run("at least one occurrence", func(t *testing.T) {
+		if wg := ForLog("docker").Times(0); wg.Occurrence != 1 {
+			t.Fatalf("expected 1 occurrence, got %d", wg.Occurrence)
+		}
+	})
+}
+
 func TestWaitForLogFailsDueToOOMKilledContainer(t *testing.T) {
 	target := &MockStrategyTarget{
 		LogsImpl: func(_ context.Context) (io.ReadCloser, error) {
@@ -70,6 +70,7 @@ func (w *waitForSql) WaitUntilReady(ctx context.Context, target StrategyTarget)
 		timeout = *w.timeout
 	}
 
+	start := time.Now()
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
@@ -87,7 +88,7 @@ func (w *waitForSql) WaitUntilReady(ctx context.Context, target StrategyTarget)
 	for port == "" {
 		select {
 		case <-ctx.Done():
-			return fmt.Errorf("%w: %w", ctx.Err(), err)
+			return newTimeoutError(ctx, w, start, fmt.Sprintf("port %s not mapped", w.Port), err)
 		case <-ticker.C:
 			if err := checkTarget(ctx, target); err != nil {
 				return err
@@ -104,12 +105,12 @@ func (w *waitForSql) WaitUntilReady(ctx context.Context, target StrategyTarget)
 	for {
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
+			return newTimeoutError(ctx, w, start, "", err)
 		case <-ticker.C:
 			if err := checkTarget(ctx, target); err != nil {
 				return err
 			}
-			if _, err := db.ExecContext(ctx, w.query); err != nil {
+			if _, err = db.ExecContext(ctx, w.query); err != nil {
 				continue
 			}
 			return nil
@@ -0,0 +1,64 @@
+package wait
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// TimeoutError is returned by the wait strategies when the container is not ready before their
+// timeout, or the deadline of the context, is exceeded. Use errors.As to get the details, and
+// errors.Is with context.DeadlineExceeded to check for a timeout.
+type TimeoutError struct {
+	// Strategy is the type of the wait strategy, e.g. "*wait.HTTPStrategy"
+	Strategy string
+	// Elapsed is the time spent waiting
+	Elapsed time.Duration
+	// LastResult describes the result of the last probe, e.g. "status code 503",
+	// empty if no probe completed
+	LastResult string
+	// Err is the error of the context, wrapping the error of the last probe if any
+	Err error
+}
+
+// Error implements error.
+func (e *TimeoutError) Error() string {
+	msg := fmt.Sprintf("%s not ready after %s", e.Strategy, e.Elapsed.Round(time.Millisecond))
+	if e.LastResult != "" {
+		msg += fmt.Sprintf(" (last result: %s)", e.LastResult)
+	}
+
+	return msg + ": " + e.Err.Error()
+}
+
+// Unwrap returns the error of the context and the last probe.
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// newTimeoutError returns the TimeoutError of the strategy which started waiting at start,
+// when the context is done.
+func newTimeoutError(ctx context.Context, strategy Strategy, start time.Time, lastResult string, lastErr error) *TimeoutError {
+	err := ctx.Err()
+	if lastErr != nil {
+		err = fmt.Errorf("%w: %w", err, lastErr)
+	}
+
+	return &TimeoutError{
+		Strategy:   fmt.Sprintf("%T", strategy),
+		Elapsed:    time.Since(start),
+		LastResult: lastResult,
+		Err:        err,
+	}
+}
+
+// interrupted reports whether the context is done or its deadline is exceeded, in which case the
+// probe which just failed was interrupted by it, and its result is not reported.
+func interrupted(ctx context.Context) bool {
+	if ctx.Err() != nil {
+		return true
+	}
+
+	deadline, ok := ctx.Deadline()
+	return ok && !time.Now().Before(deadline)
+}
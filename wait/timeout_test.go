@@ -0,0 +1,41 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeoutError(t *testing.T) {
+	probeErr := errors.New("connection refused")
+
+	t.Run("with-last-result", func(t *testing.T) {
+		err := &TimeoutError{
+			Strategy:   "*wait.HTTPStrategy",
+			Elapsed:    1500 * time.Millisecond,
+			LastResult: "status code 503",
+			Err:        context.DeadlineExceeded,
+		}
+
+		require.EqualError(t, err, "*wait.HTTPStrategy not ready after 1.5s (last result: status code 503): context deadline exceeded")
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("without-last-result", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+		<-ctx.Done()
+
+		err := newTimeoutError(ctx, ForListeningPort("80/tcp"), time.Now(), "", probeErr)
+
+		require.Equal(t, "*wait.HostPortStrategy", err.Strategy)
+		require.Empty(t, err.LastResult)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.ErrorIs(t, err, probeErr)
+		require.Contains(t, err.Error(), "not ready after ")
+		require.Contains(t, err.Error(), ": context deadline exceeded: connection refused")
+	})
+}